@@ -0,0 +1,154 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Accessibility describes the subtitles and audio description a film's
+// offers carry on the configured streaming services
+type Accessibility struct {
+	SubtitleLanguages []string `json:"subtitle_languages"`
+	AudioDescription  bool     `json:"audio_description"`
+	// AudioLanguages are the base language codes of the audio tracks
+	AudioLanguages []string `json:"audio_languages"`
+	// OriginalAudio is set if the film can be watched in its original
+	// language; with audio data but without it, only dubs are offered
+	OriginalAudio bool `json:"original_audio"`
+	// Providers are the services the annotations were taken from
+	Providers []string `json:"providers"`
+}
+
+// SetStreamingServices stores the streaming services the group uses, by
+// name (e.g. "Netflix"); empty considers every service
+func (a *App) SetStreamingServices(names []string) error {
+	var services []string
+	for _, name := range names {
+		if name = strings.TrimSpace(name); name != "" {
+			services = append(services, name)
+		}
+	}
+	settings := a.loadSettings()
+	settings.StreamingServices = services
+	return a.saveSettings(settings)
+}
+
+// GetAccessibility returns the subtitle languages and audio-description
+// availability of a film on the configured streaming services, as far as
+// JustWatch reports them
+func (a *App) GetAccessibility(movie Movie) (Accessibility, error) {
+	var access Accessibility
+	if movie.TMDBID == 0 {
+		return access, fmt.Errorf("no TMDB match for '%s' to find accessibility data with", movie.Title)
+	}
+	offers, err := a.justWatchOffers(movie)
+	if err != nil {
+		return access, err
+	}
+
+	configured := make(map[string]bool)
+	for _, name := range a.loadSettings().StreamingServices {
+		configured[strings.ToLower(name)] = true
+	}
+
+	subtitles := make(map[string]bool)
+	audio := make(map[string]bool)
+	providers := make(map[string]bool)
+	for _, offer := range offers {
+		provider := offer.Package.ClearName
+		if len(configured) > 0 && !configured[strings.ToLower(provider)] {
+			continue
+		}
+		if len(offer.SubtitleLanguages) == 0 && len(offer.AudioLanguages) == 0 {
+			continue
+		}
+		providers[provider] = true
+		for _, language := range offer.SubtitleLanguages {
+			subtitles[strings.ToLower(language)] = true
+		}
+		for _, language := range offer.AudioLanguages {
+			if describedAudio(language) {
+				access.AudioDescription = true
+				continue
+			}
+			base, _, _ := strings.Cut(strings.ToLower(language), "-")
+			audio[base] = true
+		}
+	}
+
+	access.SubtitleLanguages = sortedKeys(subtitles)
+	access.AudioLanguages = sortedKeys(audio)
+	access.OriginalAudio = movie.OriginalLanguage != "" && audio[movie.OriginalLanguage]
+	access.Providers = sortedKeys(providers)
+	return access, nil
+}
+
+// setAccessibility copies accessibility data into a movie
+func setAccessibility(movie *Movie, access Accessibility) {
+	movie.SubtitleLanguages = access.SubtitleLanguages
+	movie.AudioDescription = access.AudioDescription
+	movie.AudioLanguages = access.AudioLanguages
+	movie.OriginalAudio = access.OriginalAudio
+}
+
+// describedAudio reports whether an audio track is an audio-description
+// track, which services list as a variant of the language such as "en-AD"
+// or "English - Audio Description"
+func describedAudio(language string) bool {
+	language = strings.ToLower(language)
+	return strings.HasSuffix(language, "-ad") || strings.HasSuffix(language, "_ad") ||
+		strings.Contains(language, "description") || strings.Contains(language, "described")
+}
+
+// hasSubtitles reports whether any of the wanted languages is among the
+// subtitles, comparing base language codes so "en" matches "en-GB"
+func hasSubtitles(subtitles []string, wanted []string) bool {
+	for _, subtitle := range subtitles {
+		base, _, _ := strings.Cut(subtitle, "-")
+		for _, language := range wanted {
+			want, _, _ := strings.Cut(strings.ToLower(strings.TrimSpace(language)), "-")
+			if base == want {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// sortedKeys returns the keys of a set in order
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// filterAccessibility annotates the movies with accessibility data and keeps
+// those with subtitles in any of the given languages and, if required, audio
+// description; movies without data are dropped
+func (a *App) filterAccessibility(movies []Movie, subtitles []string, audioDescription bool) []Movie {
+	included := make([]bool, len(movies))
+	forEachIndex(len(movies), enrichWorkers, func(index int) {
+		if movies[index].TMDBID == 0 {
+			return
+		}
+		access, err := a.GetAccessibility(movies[index])
+		if err != nil {
+			return
+		}
+		setAccessibility(&movies[index], access)
+		included[index] = (len(subtitles) == 0 || hasSubtitles(access.SubtitleLanguages, subtitles)) &&
+			(!audioDescription || access.AudioDescription)
+	})
+
+	var filtered []Movie
+	for i, movie := range movies {
+		if included[i] {
+			filtered = append(filtered, movie)
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,187 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"klisse-desktop/letterboxd"
+)
+
+// letterboxdSession returns a session signed in with the stored Letterboxd
+// session cookie
+func (a *App) letterboxdSession() (*letterboxd.Session, error) {
+	token := a.loadSettings().LetterboxdSession
+	if token == "" {
+		return nil, fmt.Errorf("Letterboxd session not configured")
+	}
+	// Signed-in pages carry per-session form tokens, so they must bypass the
+	// shared page cache
+	return letterboxd.NewSession(token, a.scrapeCache.next, scrapeUserAgent)
+}
+
+// finishSession stores the session cookie if Letterboxd renewed it; every
+// signed-in request should call it once done with the session
+func (a *App) finishSession(session *letterboxd.Session) {
+	settings := a.loadSettings()
+	if token := session.Token(); token != "" && token != settings.LetterboxdSession {
+		settings.LetterboxdSession = token
+		if err := a.saveSettings(settings); err != nil {
+			log.Printf("Could not store the renewed Letterboxd session: %v", err)
+		}
+	}
+}
+
+// submitLetterboxd submits a signed-in form, counting failures
+func (a *App) submitLetterboxd(session *letterboxd.Session, actionURL string, form url.Values) error {
+	if err := session.Post(actionURL, form); err != nil {
+		a.metrics.countError("letterboxd_post")
+		return err
+	}
+	return nil
+}
+
+// loadFilm visits a film page while signed in and reads the film's
+// canonical URL and internal ID
+func (a *App) loadFilm(session *letterboxd.Session, filmURL string) (letterboxd.Film, error) {
+	film, err := session.Film(filmURL)
+	if err != nil {
+		a.metrics.countError("scrape")
+	}
+	return film, err
+}
+
+// LogFilm adds a diary entry for a film to the signed-in user's Letterboxd
+// account, rated in stars from 0.5 to 5 (0 leaves it unrated) on a date
+// formatted as YYYY-MM-DD (empty means today). Letterboxd then drops the
+// film from their watchlist, and it is forgotten from username's remembered
+// watchlist so follow-up features stop suggesting it
+func (a *App) LogFilm(username string, movie Movie, rating float64, date string) error {
+	if movie.URL == "" {
+		return fmt.Errorf("no film URL provided")
+	}
+	if rating < 0 || rating > 5 || math.Mod(rating*2, 1) != 0 {
+		return fmt.Errorf("rating must be between 0.5 and 5 stars in half-star steps")
+	}
+	if date == "" {
+		date = time.Now().Format("2006-01-02")
+	} else if _, err := time.Parse("2006-01-02", date); err != nil {
+		return fmt.Errorf("invalid date '%s', expected YYYY-MM-DD", date)
+	}
+
+	defer a.metrics.since("letterboxd_log", time.Now())
+	session, err := a.letterboxdSession()
+	if err != nil {
+		return err
+	}
+	defer a.finishSession(session)
+	film, err := a.loadFilm(session, movie.URL)
+	if err != nil {
+		return err
+	}
+	if film.ID == "" {
+		return fmt.Errorf("could not find the Letterboxd ID of '%s'", movie.Title)
+	}
+
+	err = a.submitLetterboxd(session, "/s/save-diary-entry", url.Values{
+		"json":           {"true"},
+		"filmId":         {film.ID},
+		"specifiedDate":  {"true"},
+		"viewingDateStr": {date},
+		"rating":         {strconv.Itoa(int(rating * 2))},
+		"review":         {""},
+		"tags":           {""},
+	})
+	if err != nil {
+		return err
+	}
+
+	a.forgetWatchlistFilm(username, movieKey(movie.URL))
+	return nil
+}
+
+// forgetWatchlistFilm drops a film from a user's watchlist as remembered by
+// the last comparison. The watchlists are shared with the intersection cache
+// and checkpoints, so they are replaced with copies rather than modified
+func (a *App) forgetWatchlistFilm(username string, key string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	watchlists := make(map[string]map[string]WatchlistEntry, len(a.watchlists))
+	for user, watchlist := range a.watchlists {
+		if _, listed := watchlist[key]; listed && strings.EqualFold(user, username) {
+			copied := make(map[string]WatchlistEntry, len(watchlist))
+			for k, film := range watchlist {
+				if k != key {
+					copied[k] = film
+				}
+			}
+			watchlist = copied
+		}
+		watchlists[user] = watchlist
+	}
+	a.watchlists = watchlists
+}
+
+// RemoveFromWatchlist removes a film from the signed-in user's Letterboxd
+// watchlist, e.g. once the group has watched or vetoed it for good. The next
+// comparison notices the change through the watchlist's film count
+func (a *App) RemoveFromWatchlist(movie Movie) error {
+	if movie.URL == "" {
+		return fmt.Errorf("no film URL provided")
+	}
+
+	defer a.metrics.since("letterboxd_watchlist_edit", time.Now())
+	session, err := a.letterboxdSession()
+	if err != nil {
+		return err
+	}
+	defer a.finishSession(session)
+	film, err := a.loadFilm(session, movie.URL)
+	if err != nil {
+		return err
+	}
+
+	removeURL := strings.TrimSuffix(film.URL, "/") + "/remove-from-watchlist/"
+	return a.submitLetterboxd(session, removeURL, url.Values{})
+}
+
+// WatchlistLink returns the Letterboxd page of a film, where it can be added
+// to a watchlist by hand; films only known from TMDB, such as
+// recommendations, link through Letterboxd's TMDB redirect
+func (a *App) WatchlistLink(movie Movie) (string, error) {
+	if movie.URL != "" {
+		return strings.TrimSuffix(movie.URL, "/") + "/", nil
+	}
+	if movie.TMDBID > 0 {
+		return fmt.Sprintf("https://letterboxd.com/tmdb/%d/", movie.TMDBID), nil
+	}
+	return "", fmt.Errorf("no Letterboxd or TMDB reference for '%s'", movie.Title)
+}
+
+// AddToWatchlist adds a film, such as a recommendation nobody has listed yet,
+// to the signed-in user's Letterboxd watchlist. Without a session, use
+// WatchlistLink to open the film page instead
+func (a *App) AddToWatchlist(movie Movie) error {
+	filmURL, err := a.WatchlistLink(movie)
+	if err != nil {
+		return err
+	}
+
+	defer a.metrics.since("letterboxd_watchlist_edit", time.Now())
+	session, err := a.letterboxdSession()
+	if err != nil {
+		return err
+	}
+	defer a.finishSession(session)
+	film, err := a.loadFilm(session, filmURL)
+	if err != nil {
+		return err
+	}
+
+	addURL := strings.TrimSuffix(film.URL, "/") + "/add-to-watchlist/"
+	return a.submitLetterboxd(session, addURL, url.Values{})
+}
@@ -0,0 +1,248 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// affinityPages is how many pages of watched films are sampled per user
+	affinityPages = 3
+	// affinitySampleSize caps the TMDB lookups spent building one profile
+	affinitySampleSize = 40
+	// affinityShrinkage pulls weights of rarely seen features towards zero
+	affinityShrinkage = 2.0
+	// affinityMaxAge is how long a built profile is reused
+	affinityMaxAge = 24 * time.Hour
+)
+
+// AffinityProfile holds a user's learned taste as per-feature weights, where
+// positive values mean the user rates such films above their own average
+type AffinityProfile struct {
+	Username   string             `json:"username"`
+	Genres     map[string]float64 `json:"genres"`
+	Decades    map[string]float64 `json:"decades"`
+	Directors  map[string]float64 `json:"directors"`
+	SampleSize int                `json:"sample_size"`
+	BuiltAt    time.Time          `json:"built_at"`
+}
+
+// GetAffinity returns the taste profile for a user, building it from their
+// rated films if it isn't cached yet
+func (a *App) GetAffinity(username string) (AffinityProfile, error) {
+	a.mu.Lock()
+	profile, ok := a.affinities[username]
+	a.mu.Unlock()
+	if ok && time.Since(profile.BuiltAt) < affinityMaxAge {
+		return profile, nil
+	}
+
+	profile, err := a.buildAffinity(username)
+	if err != nil {
+		return profile, err
+	}
+
+	a.mu.Lock()
+	if a.affinities == nil {
+		a.affinities = make(map[string]AffinityProfile)
+	}
+	a.affinities[username] = profile
+	a.mu.Unlock()
+
+	return profile, nil
+}
+
+// annotateAffinity scores hydrated movies by the group's predicted
+// enjoyment, weighting participants per opts, for SortAffinity
+func (a *App) annotateAffinity(movies []Movie, usernames []string, opts CompareOptions) error {
+	profiles := a.groupAffinities(usernames)
+	if len(profiles) == 0 {
+		return fmt.Errorf("could not build a taste profile for any participant")
+	}
+
+	var totalWeight float64
+	for _, profile := range profiles {
+		totalWeight += opts.weight(profile.Username)
+	}
+
+	for i := range movies {
+		var total float64
+		for _, profile := range profiles {
+			total += opts.weight(profile.Username) * profile.score(&movies[i])
+		}
+		movies[i].AffinityScore = total / totalWeight
+	}
+	return nil
+}
+
+// sortAffinity orders movies by their affinity score, highest first
+func sortAffinity(movies []Movie) {
+	sort.SliceStable(movies, func(i, j int) bool {
+		return movies[i].AffinityScore > movies[j].AffinityScore
+	})
+}
+
+// groupAffinities builds the profiles of all participants concurrently,
+// skipping users whose profile could not be built
+func (a *App) groupAffinities(usernames []string) []AffinityProfile {
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	var profiles []AffinityProfile
+
+	for _, username := range usernames {
+		wg.Add(1)
+		go func(user string) {
+			defer wg.Done()
+			profile, err := a.GetAffinity(user)
+			if err != nil {
+				log.Printf("Could not build affinity profile for '%s': %v", user, err)
+				return
+			}
+			mu.Lock()
+			profiles = append(profiles, profile)
+			mu.Unlock()
+		}(username)
+	}
+	wg.Wait()
+
+	return profiles
+}
+
+// buildAffinity samples a user's rated films and learns feature weights from
+// how far each film's rating deviates from the user's mean
+func (a *App) buildAffinity(username string) (AffinityProfile, error) {
+	profile := AffinityProfile{
+		Username:  username,
+		Genres:    make(map[string]float64),
+		Decades:   make(map[string]float64),
+		Directors: make(map[string]float64),
+	}
+
+	films, err := a.scrapeWatched(username, affinityPages)
+	if err != nil {
+		return profile, err
+	}
+
+	var rated []WatchedFilm
+	var sum float64
+	for _, film := range films {
+		if film.Rating > 0 {
+			rated = append(rated, film)
+			sum += film.Rating
+		}
+	}
+	if len(rated) == 0 {
+		return profile, fmt.Errorf("no rated films found for '%s'", username)
+	}
+	mean := sum / float64(len(rated))
+
+	// Sample evenly across the rating range so both loved and disliked films count
+	sort.Slice(rated, func(i, j int) bool { return rated[i].Rating > rated[j].Rating })
+	sample := rated
+	if len(rated) > affinitySampleSize {
+		sample = make([]WatchedFilm, 0, affinitySampleSize)
+		step := float64(len(rated)) / affinitySampleSize
+		for i := 0; i < affinitySampleSize; i++ {
+			sample = append(sample, rated[int(float64(i)*step)])
+		}
+	}
+
+	type accumulator struct {
+		sum   float64
+		count int
+	}
+	totals := map[string]map[string]*accumulator{
+		"genre":    {},
+		"decade":   {},
+		"director": {},
+	}
+
+	// Look the sampled films up concurrently, then fold them in sample order
+	movies := make([]*Movie, len(sample))
+	forEachIndex(len(sample), enrichWorkers, func(index int) {
+		movie := Movie{Key: movieKey(sample[index].URL), Title: sample[index].Title, URL: sample[index].URL}
+		if err := a.enrichMovie(&movie); err == nil {
+			movies[index] = &movie
+		}
+	})
+
+	for i, film := range sample {
+		movie := movies[i]
+		if movie == nil {
+			continue
+		}
+		profile.SampleSize++
+
+		deviation := film.Rating - mean
+		for kind, names := range movieFeatures(movie) {
+			for _, name := range names {
+				acc, ok := totals[kind][name]
+				if !ok {
+					acc = &accumulator{}
+					totals[kind][name] = acc
+				}
+				acc.sum += deviation
+				acc.count++
+			}
+		}
+	}
+
+	if profile.SampleSize == 0 {
+		return profile, fmt.Errorf("could not look up any rated films for '%s'", username)
+	}
+
+	weights := map[string]map[string]float64{
+		"genre":    profile.Genres,
+		"decade":   profile.Decades,
+		"director": profile.Directors,
+	}
+	for kind, features := range totals {
+		for name, acc := range features {
+			weights[kind][name] = acc.sum / (float64(acc.count) + affinityShrinkage)
+		}
+	}
+
+	profile.BuiltAt = time.Now()
+	return profile, nil
+}
+
+// score predicts how much the user would enjoy a movie relative to their average
+func (p AffinityProfile) score(movie *Movie) float64 {
+	weights := map[string]map[string]float64{
+		"genre":    p.Genres,
+		"decade":   p.Decades,
+		"director": p.Directors,
+	}
+
+	var total float64
+	var count int
+	for kind, names := range movieFeatures(movie) {
+		for _, name := range names {
+			total += weights[kind][name]
+			count++
+		}
+	}
+	if count == 0 {
+		return 0
+	}
+	return total / float64(count)
+}
+
+// movieFeatures extracts the genre, decade and director features of a movie
+func movieFeatures(movie *Movie) map[string][]string {
+	features := map[string][]string{
+		"genre": movie.Genres,
+	}
+	if year, err := strconv.Atoi(movie.ReleaseYear); err == nil {
+		features["decade"] = []string{fmt.Sprintf("%ds", year/10*10)}
+	}
+	if name := strings.TrimSpace(movie.Director.Name); name != "" && name != "N/A" {
+		features["director"] = []string{name}
+	}
+	return features
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+// Alias sources, in increasing order of trust
+const (
+	AliasLetterboxd = "letterboxd" // Read from the film's Letterboxd page
+	AliasImported   = "imported"   // Taken from a shared correction set
+	AliasManual     = "manual"     // Chosen by the user with OverrideMatch
+)
+
+// aliasPriority ranks alias sources so weaker ones never replace stronger ones
+var aliasPriority = map[string]int{
+	AliasLetterboxd: 1,
+	AliasImported:   2,
+	AliasManual:     3,
+}
+
+// aliasSetVersion is the format version of exported alias sets
+const aliasSetVersion = 1
+
+// Alias maps a Letterboxd film slug to the TMDB movie it should resolve to
+type Alias struct {
+	TMDBID    int       `json:"tmdb_id"`
+	Source    string    `json:"source"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// AliasSet is the shareable file format of the alias table
+type AliasSet struct {
+	Version    int              `json:"version"`
+	ExportedAt time.Time        `json:"exported_at"`
+	Aliases    map[string]Alias `json:"aliases"`
+}
+
+// GetAliases returns the local alias table keyed by film slug
+func (a *App) GetAliases() (map[string]Alias, error) {
+	aliases := make(map[string]Alias)
+	if err := a.store.load("aliases", &aliases); err != nil {
+		return nil, fmt.Errorf("could not load aliases: %v", err)
+	}
+	return aliases, nil
+}
+
+// aliasFor returns the TMDB ID a film slug is aliased to, or 0 if none; it is
+// consulted before any TMDB search
+func (a *App) aliasFor(key string) int {
+	if key == "" {
+		return 0
+	}
+	aliases, err := a.GetAliases()
+	if err != nil {
+		return 0
+	}
+	return aliases[key].TMDBID
+}
+
+// setAlias records an alias unless a more trusted one already exists; an ID
+// of 0 removes the alias
+func (a *App) setAlias(key string, tmdbID int, source string) error {
+	a.aliasesMu.Lock()
+	defer a.aliasesMu.Unlock()
+
+	aliases, err := a.GetAliases()
+	if err != nil {
+		return err
+	}
+	if tmdbID == 0 {
+		delete(aliases, key)
+	} else if !mergeAlias(aliases, key, Alias{TMDBID: tmdbID, Source: source, UpdatedAt: time.Now()}) {
+		return nil
+	}
+	return a.store.save("aliases", aliases)
+}
+
+// mergeAlias adds an alias to the table unless a more trusted source already
+// maps the slug, reporting whether the table changed
+func mergeAlias(aliases map[string]Alias, key string, alias Alias) bool {
+	existing, ok := aliases[key]
+	if ok && aliasPriority[existing.Source] > aliasPriority[alias.Source] {
+		return false
+	}
+	if ok && existing.TMDBID == alias.TMDBID && existing.Source == alias.Source {
+		return false
+	}
+	aliases[key] = alias
+	return true
+}
+
+// ExportAliases writes the alias table to a file others can import
+func (a *App) ExportAliases(path string) error {
+	aliases, err := a.GetAliases()
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(AliasSet{
+		Version:    aliasSetVersion,
+		ExportedAt: time.Now(),
+		Aliases:    aliases,
+	}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("could not encode aliases: %v", err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("could not write alias file: %v", err)
+	}
+	return nil
+}
+
+// ImportAliases merges a shared alias file into the local table, keeping
+// the user's own manual corrections, and returns how many aliases changed
+func (a *App) ImportAliases(path string) (int, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, fmt.Errorf("could not read alias file: %v", err)
+	}
+	var set AliasSet
+	if err := json.Unmarshal(data, &set); err != nil {
+		return 0, fmt.Errorf("could not parse alias file: %v", err)
+	}
+	if set.Version > aliasSetVersion {
+		return 0, fmt.Errorf("alias file version %d is newer than supported (%d)", set.Version, aliasSetVersion)
+	}
+
+	a.aliasesMu.Lock()
+	defer a.aliasesMu.Unlock()
+
+	aliases, err := a.GetAliases()
+	if err != nil {
+		return 0, err
+	}
+	changed := 0
+	for key, alias := range set.Aliases {
+		if key == "" || alias.TMDBID <= 0 {
+			continue
+		}
+		// Someone else's corrections never outrank the user's own
+		alias.Source = AliasImported
+		alias.UpdatedAt = time.Now()
+		if mergeAlias(aliases, key, alias) {
+			changed++
+		}
+	}
+	if changed == 0 {
+		return 0, nil
+	}
+	return changed, a.store.save("aliases", aliases)
+}
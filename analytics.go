@@ -0,0 +1,186 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+)
+
+// topSharedGenres is how many shared genres are reported per friend
+const topSharedGenres = 3
+
+// UserAnalytics aggregates a user's stored comparison history and movie
+// nights across every group they've compared with
+type UserAnalytics struct {
+	Username    string `json:"username"`
+	Comparisons int    `json:"comparisons"`
+	// MovieNights counts the recorded nights picked from a comparison the
+	// user took part in, and PicksWon those whose film was on their list
+	MovieNights int               `json:"movie_nights"`
+	PicksWon    int               `json:"picks_won"`
+	WinRate     float64           `json:"win_rate"`
+	Friends     []FriendAnalytics `json:"friends"`
+	// MostCompatible is the friend with the highest compatibility, if any
+	MostCompatible string `json:"most_compatible"`
+}
+
+// FriendAnalytics is how a user's watchlist lines up with a friend's
+type FriendAnalytics struct {
+	Username     string `json:"username"`
+	Comparisons  int    `json:"comparisons"`
+	SharedMovies int    `json:"shared_movies"`
+	// TopGenres are the most common genres of the shared movies
+	TopGenres []string `json:"top_genres"`
+	// Compatibility is the share, in [0, 1], of the films on either of
+	// their watchlists that were on both, over the joint comparisons that
+	// recorded watchlist sizes
+	Compatibility float64 `json:"compatibility"`
+}
+
+// GetUserAnalytics aggregates the comparison history and movie nights into
+// a user's pick win rate, their shared genres with each friend and their
+// most compatible friend
+func (a *App) GetUserAnalytics(username string) (UserAnalytics, error) {
+	analytics := UserAnalytics{Username: username}
+	history, err := a.loadComparisons()
+	if err != nil {
+		return analytics, err
+	}
+	nights, err := a.loadMovieNights()
+	if err != nil {
+		return analytics, err
+	}
+
+	type friendTotals struct {
+		comparisons int
+		shared      map[string]bool
+		genres      map[string]int
+		// overlap and union sum, over comparisons with watchlist sizes, the
+		// films on both watchlists and on either
+		overlap int
+		union   int
+	}
+	friends := make(map[string]*friendTotals)
+
+	for _, record := range history.Records {
+		if !slices.Contains(record.Usernames, username) {
+			continue
+		}
+		analytics.Comparisons++
+		for _, friend := range record.Usernames {
+			if friend == username {
+				continue
+			}
+			totals, ok := friends[friend]
+			if !ok {
+				totals = &friendTotals{shared: map[string]bool{}, genres: map[string]int{}}
+				friends[friend] = totals
+			}
+			totals.comparisons++
+			both := 0
+			for _, movie := range record.Movies {
+				if !slices.Contains(movie.Users, username) || !slices.Contains(movie.Users, friend) {
+					continue
+				}
+				both++
+				if !totals.shared[movie.Key] {
+					totals.shared[movie.Key] = true
+					for _, genre := range movie.Genres {
+						totals.genres[genre]++
+					}
+				}
+			}
+			// Films on both watchlists are among the common movies, bar
+			// exclusions, so the sizes give the films on either
+			mine, theirs := record.WatchlistSizes[username], record.WatchlistSizes[friend]
+			if mine > 0 && theirs > 0 && mine+theirs > both {
+				totals.overlap += both
+				totals.union += mine + theirs - both
+			}
+		}
+	}
+
+	if analytics.Comparisons == 0 {
+		return analytics, fmt.Errorf("no comparisons with '%s' in the history", username)
+	}
+
+	// A night counts for the comparison it was most likely picked from: the
+	// latest one before it listing the film
+	for _, night := range nights {
+		record, ok := pickedFrom(history.Records, night)
+		if !ok || !slices.Contains(record.Usernames, username) {
+			continue
+		}
+		analytics.MovieNights++
+		for _, movie := range record.Movies {
+			if movie.Key == night.Key && slices.Contains(movie.Users, username) {
+				analytics.PicksWon++
+				break
+			}
+		}
+	}
+	if analytics.MovieNights > 0 {
+		analytics.WinRate = float64(analytics.PicksWon) / float64(analytics.MovieNights)
+	}
+
+	for friend, totals := range friends {
+		stats := FriendAnalytics{
+			Username:     friend,
+			Comparisons:  totals.comparisons,
+			SharedMovies: len(totals.shared),
+			TopGenres:    topGenres(totals.genres, topSharedGenres),
+		}
+		if totals.union > 0 {
+			stats.Compatibility = float64(totals.overlap) / float64(totals.union)
+		}
+		analytics.Friends = append(analytics.Friends, stats)
+	}
+	sort.Slice(analytics.Friends, func(i, j int) bool {
+		fi, fj := analytics.Friends[i], analytics.Friends[j]
+		if fi.Compatibility != fj.Compatibility {
+			return fi.Compatibility > fj.Compatibility
+		}
+		if fi.SharedMovies != fj.SharedMovies {
+			return fi.SharedMovies > fj.SharedMovies
+		}
+		return fi.Username < fj.Username
+	})
+	if len(analytics.Friends) > 0 && analytics.Friends[0].SharedMovies > 0 {
+		analytics.MostCompatible = analytics.Friends[0].Username
+	}
+	return analytics, nil
+}
+
+// pickedFrom returns the latest comparison before a movie night that listed
+// its film
+func pickedFrom(records []ComparisonRecord, night MovieNight) (ComparisonRecord, bool) {
+	for i := len(records) - 1; i >= 0; i-- {
+		if records[i].RunAt.After(night.WatchedAt) {
+			continue
+		}
+		for _, movie := range records[i].Movies {
+			if movie.Key == night.Key {
+				return records[i], true
+			}
+		}
+	}
+	return ComparisonRecord{}, false
+}
+
+// topGenres returns the n most counted genres, most common first
+func topGenres(counts map[string]int, n int) []string {
+	genres := make([]string, 0, len(counts))
+	for genre := range counts {
+		genres = append(genres, genre)
+	}
+	sort.Slice(genres, func(i, j int) bool {
+		if counts[genres[i]] != counts[genres[j]] {
+			return counts[genres[i]] > counts[genres[j]]
+		}
+		return genres[i] < genres[j]
+	})
+	if len(genres) > n {
+		genres = genres[:n]
+	}
+	return genres
+}
@@ -0,0 +1,270 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+)
+
+// anilistQuery finds an anime film on AniList with its main studios,
+// source material and the rest of its franchise
+const anilistQuery = `query ($search: String) {
+  Media(search: $search, type: ANIME, format: MOVIE) {
+    id
+    siteUrl
+    source
+    episodes
+    startDate { year }
+    studios(isMain: true) { nodes { name } }
+    relations {
+      edges {
+        relationType
+        node { title { romaji english } format episodes type }
+      }
+    }
+  }
+}`
+
+// animeLimiter paces AniList and Jikan requests; both allow far fewer
+// requests than TMDB
+var animeLimiter = newRateLimiter(time.Second)
+
+// AnimeInfo is what AniList or Jikan know about an animated film beyond
+// TMDB's details
+type AnimeInfo struct {
+	// Source is the service the info came from, "anilist" or "jikan"
+	Source  string   `json:"source"`
+	URL     string   `json:"url"`
+	Studios []string `json:"studios"`
+	// SourceMaterial is what the film adapts, e.g. "manga" or "original"
+	SourceMaterial string `json:"source_material"`
+	Episodes       int    `json:"episodes"`
+	// Franchise lists related anime, such as the series a film continues
+	Franchise []AnimeRelation `json:"franchise"`
+}
+
+// AnimeRelation is another entry in an anime film's franchise
+type AnimeRelation struct {
+	Title    string `json:"title"`
+	Relation string `json:"relation"`
+	Format   string `json:"format"`
+	Episodes int    `json:"episodes"`
+}
+
+// SetAnimeMetadata turns enriching Japanese animated films via AniList,
+// falling back to Jikan, on or off
+func (a *App) SetAnimeMetadata(enabled bool) error {
+	settings := a.loadSettings()
+	settings.AnimeMetadata = enabled
+	return a.saveSettings(settings)
+}
+
+// GetAnimeInfo looks an animated film up on AniList, falling back to
+// Jikan (MyAnimeList), since TMDB data for anime films is often thin. The
+// movie should be hydrated, so its TMDB release year rules out namesakes
+func (a *App) GetAnimeInfo(movie Movie) (AnimeInfo, error) {
+	title, year := filmYear(movie.Title, movie.URL)
+	if movie.ReleaseYear != "" {
+		year = movie.ReleaseYear
+	}
+	info, err := a.anilistInfo(title, year)
+	if err == nil {
+		return info, nil
+	}
+	info, jikanErr := a.jikanInfo(title, year)
+	if jikanErr != nil {
+		return AnimeInfo{}, fmt.Errorf("no anime info for '%s': anilist: %v; jikan: %v", title, err, jikanErr)
+	}
+	return info, nil
+}
+
+// anilistInfo looks a film up on AniList
+func (a *App) anilistInfo(title string, year string) (AnimeInfo, error) {
+	body, _ := json.Marshal(map[string]interface{}{
+		"query":     anilistQuery,
+		"variables": map[string]string{"search": title},
+	})
+	req, err := http.NewRequest(http.MethodPost, "https://graphql.anilist.co", bytes.NewReader(body))
+	if err != nil {
+		return AnimeInfo{}, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+
+	var result struct {
+		Data struct {
+			Media *struct {
+				ID        int    `json:"id"`
+				SiteURL   string `json:"siteUrl"`
+				Source    string `json:"source"`
+				Episodes  int    `json:"episodes"`
+				StartDate struct {
+					Year int `json:"year"`
+				} `json:"startDate"`
+				Studios struct {
+					Nodes []struct {
+						Name string `json:"name"`
+					} `json:"nodes"`
+				} `json:"studios"`
+				Relations struct {
+					Edges []struct {
+						RelationType string `json:"relationType"`
+						Node         struct {
+							Title struct {
+								Romaji  string `json:"romaji"`
+								English string `json:"english"`
+							} `json:"title"`
+							Format   string `json:"format"`
+							Episodes int    `json:"episodes"`
+							Type     string `json:"type"`
+						} `json:"node"`
+					} `json:"edges"`
+				} `json:"relations"`
+			} `json:"Media"`
+		} `json:"data"`
+	}
+	if err := a.animeJSON(req, "anilist", &result); err != nil {
+		return AnimeInfo{}, err
+	}
+	media := result.Data.Media
+	if media == nil {
+		return AnimeInfo{}, fmt.Errorf("not found")
+	}
+	if year != "" && media.StartDate.Year != 0 && fmt.Sprint(media.StartDate.Year) != year {
+		return AnimeInfo{}, fmt.Errorf("closest match is from %d", media.StartDate.Year)
+	}
+
+	info := AnimeInfo{
+		Source:         "anilist",
+		URL:            media.SiteURL,
+		SourceMaterial: animeEnum(media.Source),
+		Episodes:       media.Episodes,
+	}
+	for _, studio := range media.Studios.Nodes {
+		info.Studios = append(info.Studios, studio.Name)
+	}
+	for _, edge := range media.Relations.Edges {
+		// Manga and novels in the franchise aren't watchable
+		if edge.Node.Type != "ANIME" {
+			continue
+		}
+		title := edge.Node.Title.English
+		if title == "" {
+			title = edge.Node.Title.Romaji
+		}
+		info.Franchise = append(info.Franchise, AnimeRelation{
+			Title:    title,
+			Relation: animeEnum(edge.RelationType),
+			Format:   animeEnum(edge.Node.Format),
+			Episodes: edge.Node.Episodes,
+		})
+	}
+	return info, nil
+}
+
+// jikanInfo looks a film up on MyAnimeList through Jikan, which knows
+// studios and source material but not the franchise
+func (a *App) jikanInfo(title string, year string) (AnimeInfo, error) {
+	params := url.Values{"q": {title}, "type": {"movie"}, "limit": {"5"}}
+	req, err := http.NewRequest(http.MethodGet, "https://api.jikan.moe/v4/anime?"+params.Encode(), nil)
+	if err != nil {
+		return AnimeInfo{}, err
+	}
+
+	var result struct {
+		Data []struct {
+			URL      string `json:"url"`
+			Source   string `json:"source"`
+			Episodes int    `json:"episodes"`
+			Year     int    `json:"year"`
+			Aired    struct {
+				Prop struct {
+					From struct {
+						Year int `json:"year"`
+					} `json:"from"`
+				} `json:"prop"`
+			} `json:"aired"`
+			Studios []struct {
+				Name string `json:"name"`
+			} `json:"studios"`
+		} `json:"data"`
+	}
+	if err := a.animeJSON(req, "jikan", &result); err != nil {
+		return AnimeInfo{}, err
+	}
+	for _, anime := range result.Data {
+		if aired := anime.Aired.Prop.From.Year; year != "" && aired != 0 && fmt.Sprint(aired) != year {
+			continue
+		}
+		info := AnimeInfo{
+			Source:         "jikan",
+			URL:            anime.URL,
+			SourceMaterial: strings.ToLower(anime.Source),
+			Episodes:       anime.Episodes,
+		}
+		for _, studio := range anime.Studios {
+			info.Studios = append(info.Studios, studio.Name)
+		}
+		return info, nil
+	}
+	return AnimeInfo{}, fmt.Errorf("not found")
+}
+
+// animeJSON sends a request to AniList or Jikan and decodes its reply
+func (a *App) animeJSON(req *http.Request, service string, v interface{}) error {
+	animeLimiter.wait()
+	client := &http.Client{Transport: sharedTransport, Timeout: 10 * time.Second}
+	start := time.Now()
+	resp, err := client.Do(req)
+	a.metrics.since(service+"_request", start)
+	if err != nil {
+		a.metrics.countError(service)
+		return fmt.Errorf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("not found")
+	}
+	if resp.StatusCode != http.StatusOK {
+		a.metrics.countError(service)
+		return fmt.Errorf("status code %d", resp.StatusCode)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("failed to parse response: %v", err)
+	}
+	return nil
+}
+
+// animeEnum turns an AniList enum value such as "LIGHT_NOVEL" into
+// "light novel"
+func animeEnum(value string) string {
+	return strings.ToLower(strings.ReplaceAll(value, "_", " "))
+}
+
+// isAnime reports whether a hydrated movie is a Japanese animated film,
+// the only kind AniList and Jikan know; other animation would only match
+// namesakes
+func isAnime(movie Movie) bool {
+	return movie.OriginalLanguage == "ja" && slices.Contains(movie.Genres, "Animation")
+}
+
+// annotateAnime adds AniList or Jikan info to every hydrated anime movie
+func (a *App) annotateAnime(movies []Movie) {
+	forEachIndex(len(movies), enrichWorkers, func(index int) {
+		if !isAnime(movies[index]) {
+			return
+		}
+		info, err := a.GetAnimeInfo(movies[index])
+		if err != nil {
+			log.Printf("Could not fetch anime info: %v", err)
+			return
+		}
+		movies[index].Anime = &info
+	})
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// API key validation outcomes
+const (
+	KeyValid         = "valid"
+	KeyCleared       = "cleared"
+	KeyInvalid       = "invalid"
+	KeyWrongType     = "wrong_type"
+	KeyQuotaExceeded = "quota_exceeded"
+	KeyNetworkError  = "network_error"
+	KeyServiceError  = "service_error"
+)
+
+// APIKeyValidation describes the outcome of checking a TMDB API key
+type APIKeyValidation struct {
+	Valid   bool   `json:"valid"`
+	Status  string `json:"status"`
+	Message string `json:"message"`
+	// KeyType is "v3" for API keys or "v4" for read access tokens
+	KeyType   string `json:"key_type"`
+	LatencyMS int64  `json:"latency_ms"`
+	// RateLimitLimit and RateLimitRemaining are -1 when TMDB doesn't report them
+	RateLimitLimit     int `json:"rate_limit_limit"`
+	RateLimitRemaining int `json:"rate_limit_remaining"`
+}
+
+// SetTMDBAPIKey validates a TMDB API key against the API and, only if it
+// works, uses and persists it; an empty key clears the saved one
+func (a *App) SetTMDBAPIKey(apiKey string) (APIKeyValidation, error) {
+	apiKey = strings.TrimSpace(apiKey)
+
+	if apiKey == "" {
+		a.runtimeAPIKey = ""
+		settings := a.loadSettings()
+		settings.TMDBAPIKey = ""
+		if err := a.saveSettings(settings); err != nil {
+			return APIKeyValidation{}, err
+		}
+		return APIKeyValidation{Status: KeyCleared, Message: "TMDB API key removed", RateLimitLimit: -1, RateLimitRemaining: -1}, nil
+	}
+
+	result := validateTMDBKey(apiKey)
+	if !result.Valid {
+		return result, nil
+	}
+
+	a.runtimeAPIKey = apiKey
+	settings := a.loadSettings()
+	settings.TMDBAPIKey = apiKey
+	if err := a.saveSettings(settings); err != nil {
+		return result, err
+	}
+	return result, nil
+}
+
+// validateTMDBKey checks a key with a lightweight TMDB request and tells
+// invalid keys, exhausted quotas and network failures apart
+func validateTMDBKey(apiKey string) APIKeyValidation {
+	result := APIKeyValidation{KeyType: "v3", RateLimitLimit: -1, RateLimitRemaining: -1}
+
+	// v4 read access tokens are JWTs; the app authenticates with v3 keys
+	if strings.HasPrefix(apiKey, "eyJ") && strings.Count(apiKey, ".") == 2 {
+		result.KeyType = "v4"
+		result.Status = KeyWrongType
+		result.Message = "This looks like a v4 read access token; enter the v3 API key from TMDB's API settings instead"
+		return result
+	}
+
+	client := &http.Client{Transport: sharedTransport, Timeout: 10 * time.Second}
+	start := time.Now()
+	resp, err := client.Get("https://api.themoviedb.org/3/configuration?api_key=" + apiKey)
+	result.LatencyMS = time.Since(start).Milliseconds()
+	if err != nil {
+		result.Status = KeyNetworkError
+		result.Message = fmt.Sprintf("Could not reach TMDB to check the key: %v", err)
+		return result
+	}
+	defer resp.Body.Close()
+
+	if limit, err := strconv.Atoi(resp.Header.Get("X-RateLimit-Limit")); err == nil {
+		result.RateLimitLimit = limit
+	}
+	if remaining, err := strconv.Atoi(resp.Header.Get("X-RateLimit-Remaining")); err == nil {
+		result.RateLimitRemaining = remaining
+	}
+
+	if resp.StatusCode == http.StatusOK {
+		result.Valid = true
+		result.Status = KeyValid
+		result.Message = "TMDB API key is working"
+		return result
+	}
+	tmdbErr := parseTMDBError(resp)
+	switch {
+	case tmdbErr.invalidKey():
+		result.Status = KeyInvalid
+		result.Message = fmt.Sprintf("TMDB rejected the API key: %v", tmdbErr)
+	case tmdbErr.rateLimited():
+		result.Status = KeyQuotaExceeded
+		result.Message = "TMDB rate limit exceeded; try again in a few seconds"
+	default:
+		result.Status = KeyServiceError
+		result.Message = fmt.Sprintf("TMDB API error: %v", tmdbErr)
+	}
+	return result
+}
@@ -7,8 +7,8 @@ import (
 	"log"
 	"net/http"
 	"net/url"
+	"path/filepath"
 	"regexp"
-	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -28,8 +28,10 @@ func (a *App) getTMDBAPIKey() string {
 
 // Movie represents a movie with all its details
 type Movie struct {
+	Key             string     `json:"key"`
 	Title           string     `json:"title"`
 	URL             string     `json:"url"`
+	TMDBID          int        `json:"tmdb_id"`
 	Rating          float64    `json:"rating"`
 	FormattedRating string     `json:"formatted_rating"`
 	PosterURL       string     `json:"poster_url"`
@@ -41,11 +43,56 @@ type Movie struct {
 	FormattedRuntime string    `json:"formatted_runtime"`
 	Genres          []string   `json:"genres"`
 	IMDBID          string     `json:"imdb_id"`
+	Certification   string     `json:"certification"`
+	EntryType       string     `json:"entry_type"`
+	Classification  string     `json:"classification"`
+	Artist          string     `json:"artist"`
+	Status          string     `json:"status"`
+	DigitalReleaseDate string `json:"digital_release_date"`
+	PhysicalReleaseDate string `json:"physical_release_date"`
+	LetterboxdRating float64   `json:"letterboxd_rating"`
+	LetterboxdWatches int      `json:"letterboxd_watches"`
+	VoteCount       int        `json:"vote_count"`
+	IMDbRating      float64    `json:"imdb_rating"`
+	CompositeScore  float64    `json:"composite_score"`
+	Note            string     `json:"note"`
+	Tags            []string   `json:"tags"`
+	Pinned          bool       `json:"pinned"`
 	Overview        string     `json:"overview"`
 	Director        Person     `json:"director"`
 	Cast            []Person   `json:"cast"`
 	Users           []User     `json:"users"`
+	SeenBy          []string   `json:"seen_by"`
+	LovedBy         []string   `json:"loved_by"`
+	Reviews         []Review   `json:"reviews"`
+	FriendRatings   []FriendRating `json:"friend_ratings"`
+	RentalPrices    []Price    `json:"rental_prices"`
+	SubtitleLanguages []string `json:"subtitle_languages"`
+	AudioDescription bool      `json:"audio_description"`
+	OriginalLanguage string    `json:"original_language"`
+	AudioLanguages  []string   `json:"audio_languages"`
+	OriginalAudio   bool       `json:"original_audio"`
+	Themes          []string   `json:"themes"`
+	Keywords        []string   `json:"keywords"`
+	Awards          []Award    `json:"awards"`
+	AwardWins       int        `json:"award_wins"`
+	AwardNominations int       `json:"award_nominations"`
+	RottenTomatoes  int        `json:"rotten_tomatoes"`
+	Metascore       int        `json:"metascore"`
+	MoodScore       float64    `json:"mood_score"`
 	Count           int        `json:"count"`
+	Score           float64    `json:"score"`
+	ListRank        int        `json:"list_rank"`
+	AffinityScore   float64    `json:"affinity_score"`
+	Editions        []Edition  `json:"editions"`
+	Anime           *AnimeInfo `json:"anime"`
+}
+
+// movieKey returns the Letterboxd film slug identifying a movie URL,
+// e.g. "https://letterboxd.com/film/alien/" -> "alien"
+func movieKey(filmURL string) string {
+	trimmed := strings.Trim(filmURL, "/")
+	return trimmed[strings.LastIndex(trimmed, "/")+1:]
 }
 
 // Person represents a director or cast member
@@ -56,81 +103,183 @@ type Person struct {
 
 // User represents a Letterboxd user
 type User struct {
-	Name   string `json:"name"`
-	Avatar string `json:"avatar"`
+	Name          string `json:"name"`
+	Avatar        string `json:"avatar"`
+	DisplayName   string `json:"display_name"`
+	Location      string `json:"location"`
+	WatchlistSize int    `json:"watchlist_size"`
 }
 
 // TMDBMovie represents TMDB movie data
 type TMDBMovie struct {
 	ID           int    `json:"id"`
 	VoteAverage  float64 `json:"vote_average"`
+	VoteCount    int    `json:"vote_count"`
 	PosterPath   string `json:"poster_path"`
 	BackdropPath string `json:"backdrop_path"`
 	ReleaseDate  string `json:"release_date"`
+	Status       string `json:"status"`
 	Runtime      int    `json:"runtime"`
 	Genres       []struct {
 		Name string `json:"name"`
 	} `json:"genres"`
-	IMDBID   string `json:"imdb_id"`
-	Overview string `json:"overview"`
-	Credits  struct {
+	IMDBID           string `json:"imdb_id"`
+	Overview         string `json:"overview"`
+	OriginalLanguage string `json:"original_language"`
+	Credits          struct {
 		Crew []struct {
 			Name string `json:"name"`
 			Job  string `json:"job"`
 			ID   int    `json:"id"`
 		} `json:"crew"`
 		Cast []struct {
-			Name string `json:"name"`
-			ID   int    `json:"id"`
+			Name      string `json:"name"`
+			ID        int    `json:"id"`
+			Character string `json:"character"`
 		} `json:"cast"`
 	} `json:"credits"`
 	Images struct {
-		Logos []struct {
-			FilePath    string  `json:"file_path"`
-			ISO6391     *string `json:"iso_639_1"`
-		} `json:"logos"`
+		Logos []TMDBImage `json:"logos"`
 	} `json:"images"`
+	ReleaseDates struct {
+		Results []struct {
+			ISO31661     string `json:"iso_3166_1"`
+			ReleaseDates []struct {
+				Certification string `json:"certification"`
+				ReleaseDate   string `json:"release_date"`
+				Type          int    `json:"type"`
+			} `json:"release_dates"`
+		} `json:"results"`
+	} `json:"release_dates"`
+	Keywords struct {
+		Keywords []struct {
+			ID   int    `json:"id"`
+			Name string `json:"name"`
+		} `json:"keywords"`
+	} `json:"keywords"`
+	BelongsToCollection *struct {
+		ID   int    `json:"id"`
+		Name string `json:"name"`
+	} `json:"belongs_to_collection"`
+}
+
+// TMDBImage is a poster, backdrop or logo in a TMDB images response
+type TMDBImage struct {
+	FilePath    string  `json:"file_path"`
+	ISO6391     *string `json:"iso_639_1"`
+	Width       int     `json:"width"`
+	Height      int     `json:"height"`
+	VoteAverage float64 `json:"vote_average"`
 }
 
 // TMDBSearchResult represents TMDB search response
 type TMDBSearchResult struct {
 	Results []struct {
-		ID int `json:"id"`
+		ID            int    `json:"id"`
+		Title         string `json:"title"`
+		OriginalTitle string `json:"original_title"`
+		ReleaseDate   string `json:"release_date"`
+		PosterPath    string `json:"poster_path"`
+		Overview      string `json:"overview"`
+		Adult         bool   `json:"adult"`
 	} `json:"results"`
 }
 
 // App struct
 type App struct {
-	ctx           context.Context
-	runtimeAPIKey string // API key set at runtime from frontend
+	ctx               context.Context
+	runtimeAPIKey     string            // API key set at runtime from frontend
+	scrapeCache       *cachingTransport // Conditional-request cache for Letterboxd pages
+	tmdbCache         *cachingTransport // Conditional-request cache for TMDB responses
+	tmdbFlights       flightGroup       // Coalesces concurrent identical TMDB requests
+	refreshes         refreshLog        // Last-refresh timestamps for the status panel
+	metrics           *metrics          // Pipeline timings and error counts
+	tmdbLimiter       *rateLimiter      // Paces TMDB requests across workers
+	letterboxdLimiter *rateLimiter      // Paces one-off Letterboxd requests
+	tmdbBudget        *requestBudget    // TMDB rate-limit budget
+	letterboxdBudget  *requestBudget    // Self-imposed Letterboxd request budget
+	store             *dataStore        // Persistent local data (exclusions, notes, ...)
+	exclusionsMu      sync.Mutex        // Serialises exclusion list updates
+	historyMu         sync.Mutex        // Serialises watchlist history updates
+	notesMu           sync.Mutex        // Serialises movie note updates
+	postersMu         sync.Mutex        // Serialises preferred poster updates
+	tagsMu            sync.Mutex        // Serialises tag updates
+	pinsMu            sync.Mutex        // Serialises pin updates
+	aliasesMu         sync.Mutex        // Serialises alias table updates
+	nightsMu          sync.Mutex        // Serialises movie night log updates
+	comparisonsMu     sync.Mutex        // Serialises comparison history updates
+	sharesMu          sync.Mutex        // Guards shared vote sessions
+	lanMu             sync.Mutex        // Guards the LAN voting server
+	lanServer         *http.Server      // LAN voting server, nil when stopped
+	lanPort           int               // Port the LAN voting server listens on
+	pprofMu           sync.Mutex        // Guards the pprof server
+	pprofServer       *http.Server      // pprof server, nil unless profiling
+	pprofAddr         string            // Address the pprof server listens on
+	profilingForced   bool              // Profiling enabled by --profile
+	layoutMu          sync.Mutex        // Guards the ruleset and layout check
+	rules             Ruleset           // Scraping ruleset in use, zero until loaded
+	layout            LayoutHealth      // Latest Letterboxd layout check
+
+	mu            sync.Mutex
+	watchlists    map[string]map[string]WatchlistEntry // Watchlists scraped by the last comparison
+	affinities    map[string]AffinityProfile           // Taste profiles built per user
+	surprises     map[string]Movie                     // Blind picks awaiting Reveal, by token
+	intersections map[string]groupIntersection         // Last intersection per group, for incremental refreshes
+	sessions      map[string]*ComparisonSession        // Comparison sessions by ID
+
+	shares map[string]*VoteSession // Shared vote sessions by code, guarded by sharesMu
 }
 
 // NewApp creates a new App application struct
 func NewApp() *App {
-	return &App{}
+	letterboxdBudget := newRequestBudget("letterboxd", 60, time.Minute)
+	app := &App{
+		scrapeCache: newCachingTransport(filepath.Join(profileCacheDir(activeProfile()), "pages"),
+			&budgetTransport{budget: letterboxdBudget, next: sharedTransport}),
+		metrics:           newMetrics(),
+		tmdbLimiter:       newRateLimiter(250 * time.Millisecond),
+		letterboxdLimiter: newRateLimiter(500 * time.Millisecond),
+		tmdbBudget:        newRequestBudget("tmdb", 40, 10*time.Second),
+		letterboxdBudget:  letterboxdBudget,
+		tmdbCache:         newTMDBCache(filepath.Join(profileCacheDir(activeProfile()), "tmdb")),
+		store:             newAppStore(),
+	}
+	if err := migrateStore(app.store); err != nil {
+		log.Printf("Could not migrate local data: %v", err)
+	}
+	app.applySettings()
+	return app
+}
+
+// scrapeUserAgent is the browser user agent Letterboxd requests are sent with
+const scrapeUserAgent = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/91.0.4472.124 Safari/537.36"
+
+// newCollector creates a colly collector that scrapes through the page cache
+func (a *App) newCollector() *colly.Collector {
+	c := colly.NewCollector()
+	c.UserAgent = scrapeUserAgent
+	c.WithTransport(a.scrapeCache)
+	return c
 }
 
 // startup is called when the app starts. The context is saved
 // so we can call the runtime methods
 func (a *App) startup(ctx context.Context) {
 	a.ctx = ctx
+	go a.checkLayoutAtStartup()
 }
 
-// SetTMDBAPIKey sets the TMDB API key at runtime
-func (a *App) SetTMDBAPIKey(apiKey string) error {
-	a.runtimeAPIKey = strings.TrimSpace(apiKey)
-	return nil
-}
 
 // GetUserAvatar fetches the avatar URL for a Letterboxd user
 func (a *App) GetUserAvatar(username string) (string, error) {
-	c := colly.NewCollector()
-	c.UserAgent = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/91.0.4472.124 Safari/537.36"
+	defer a.metrics.since("profile_scrape", time.Now())
+	c := a.newCollector()
 
 	var avatarURL string
 	var err error
 
-	c.OnHTML("meta[property='og:image']", func(e *colly.HTMLElement) {
+	rules := a.ruleset()
+	c.OnHTML(rules.Selectors.OGImage, func(e *colly.HTMLElement) {
 		content := e.Attr("content")
 		if content != "" {
 			avatarURL = content
@@ -138,10 +287,11 @@ func (a *App) GetUserAvatar(username string) (string, error) {
 	})
 
 	c.OnError(func(r *colly.Response, e error) {
+		a.metrics.countError("scrape")
 		err = fmt.Errorf("could not fetch profile for '%s': %v", username, e)
 	})
 
-	visitErr := c.Visit(fmt.Sprintf("https://letterboxd.com/%s/", username))
+	visitErr := c.Visit(forUser(rules.URLs.Profile, username))
 	if visitErr != nil {
 		return "", fmt.Errorf("could not visit profile for '%s': %v", username, visitErr)
 	}
@@ -157,73 +307,121 @@ func (a *App) GetUserAvatar(username string) (string, error) {
 	return avatarURL, nil
 }
 
-// GetWatchlist scrapes a user's Letterboxd watchlist
-func (a *App) GetWatchlist(username string) (map[string]string, error) {
-	c := colly.NewCollector()
-	c.UserAgent = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/91.0.4472.124 Safari/537.36"
+// GetWatchlist scrapes a user's Letterboxd watchlist, keyed by film
+func (a *App) GetWatchlist(username string) (map[string]WatchlistEntry, error) {
+	_, movies, err := a.scrapeWatchlist(username)
+	return movies, err
+}
 
-	movies := make(map[string]string)
-	var scrapeErr error
+// scrapeWatchlist scrapes a user's watchlist together with the profile
+// details shown in its page header, in a single pass
+func (a *App) scrapeWatchlist(username string) (userProfile, map[string]WatchlistEntry, error) {
+	defer a.metrics.since("watchlist_scrape", time.Now())
+	profile, movies, err := a.scrapeWatchlistFragments(username)
+	if err != nil {
+		log.Printf("Scraping full watchlist pages for '%s': %v", username, err)
+		profile, movies, err = a.scrapeWatchlistWith(a.newCollector(), username)
+	}
+	if err != nil {
+		return userProfile{}, nil, err
+	}
 
-	c.OnHTML("li.poster-container", func(e *colly.HTMLElement) {
-		posterDiv := e.ChildAttr("div.film-poster", "data-target-link")
-		img := e.ChildAttr("div.film-poster img", "alt")
-		
-		if img != "" && posterDiv != "" {
-			title := img
-			fullURL := fmt.Sprintf("https://letterboxd.com%s", posterDiv)
-			movies[title] = fullURL
+	// Posters or pagination loaded by JavaScript are missed by the static
+	// scrape, so a suspiciously short watchlist is retried in a browser
+	if suspiciouslyEmpty(len(movies), profile.WatchlistSize) {
+		if c := a.newBrowserCollector(); c != nil {
+			log.Printf("Watchlist for '%s' looks incomplete (%d of %d films), rendering it in a browser", username, len(movies), profile.WatchlistSize)
+			rendered, renderedMovies, err := a.scrapeWatchlistWith(c, username)
+			if err != nil {
+				log.Printf("Could not render watchlist for '%s': %v", username, err)
+			} else if len(renderedMovies) > len(movies) {
+				profile, movies = rendered, renderedMovies
+			}
 		}
-	})
+	}
 
-	c.OnHTML("a.next", func(e *colly.HTMLElement) {
+	if len(movies) == 0 {
+		if err := a.layoutChanged(); err != nil {
+			return userProfile{}, nil, err
+		}
+		return userProfile{}, nil, fmt.Errorf("no movies found in watchlist for '%s'", username)
+	}
+	if profile.WatchlistSize == 0 {
+		profile.WatchlistSize = len(movies)
+	}
+
+	a.refreshes.watchlistRefreshed(username)
+	if _, err := a.recordWatchlistSnapshot(username, movies); err != nil {
+		log.Printf("Could not record watchlist history for '%s': %v", username, err)
+	}
+	return profile, movies, nil
+}
+
+// scrapeWatchlistWith scrapes every full page of a user's watchlist with a
+// collector
+func (a *App) scrapeWatchlistWith(c *colly.Collector, username string) (userProfile, map[string]WatchlistEntry, error) {
+	var profile userProfile
+	movies := make(map[string]WatchlistEntry)
+	var scrapeErr error
+
+	rules := a.ruleset()
+	onProfileHeader(c, rules.Selectors, &profile)
+	onPosters(c, rules, movies)
+
+	c.OnHTML(rules.Selectors.NextPage, func(e *colly.HTMLElement) {
 		nextHref := e.Attr("href")
 		if nextHref != "" {
-			time.Sleep(500 * time.Millisecond) // Rate limiting
-			nextURL := fmt.Sprintf("https://letterboxd.com%s", nextHref)
+			a.letterboxdLimiter.wait() // Rate limiting
+			nextURL := rules.URLs.link(nextHref)
 			e.Request.Visit(nextURL)
 		}
 	})
 
 	c.OnError(func(r *colly.Response, e error) {
+		a.metrics.countError("scrape")
 		scrapeErr = e
 	})
 
-	startURL := fmt.Sprintf("https://letterboxd.com/%s/watchlist/", username)
+	startURL := forUser(rules.URLs.Watchlist, username)
 	err := c.Visit(startURL)
 	if err != nil {
-		return nil, fmt.Errorf("could not visit watchlist for '%s': %v", username, err)
+		return userProfile{}, nil, fmt.Errorf("could not visit watchlist for '%s': %v", username, err)
 	}
 
 	if scrapeErr != nil {
-		return nil, scrapeErr
+		return userProfile{}, nil, scrapeErr
 	}
-
-	if len(movies) == 0 {
-		return nil, fmt.Errorf("no movies found in watchlist for '%s'", username)
-	}
-
-	return movies, nil
+	return profile, movies, nil
 }
 
 // GetTMDBDetails fetches movie details from TMDB API with improved search logic
 func (a *App) GetTMDBDetails(movieTitle string) (TMDBMovie, error) {
-	var tmdbData TMDBMovie
+	title, year := splitTitleYear(movieTitle)
+	return a.lookupTMDB(title, year, detailAppends)
+}
+
+// lookupTMDB searches TMDB for a title released in year (if known) and
+// fetches the details of the best matching candidate, with the given
+// append_to_response sections
+func (a *App) lookupTMDB(movieTitle string, year string, appends string) (TMDBMovie, error) {
+	movieID, err := a.searchTMDB(movieTitle, year)
+	if err != nil {
+		return TMDBMovie{}, err
+	}
+	return a.fetchTMDBDetails(movieID, appends)
+}
 
+// searchTMDB finds the TMDB ID for a title, trying several spellings and
+// preferring candidates released in the given year
+func (a *App) searchTMDB(movieTitle string, year string) (int, error) {
 	apiKey := a.getTMDBAPIKey()
 	if apiKey == "" || len(apiKey) < 10 {
-		return tmdbData, fmt.Errorf("TMDB API key not configured")
+		return 0, fmt.Errorf("TMDB API key not configured")
 	}
 
 	originalTitle := movieTitle
-
-	// Extract year from title if present
-	var year string
-	yearRegex := regexp.MustCompile(`\((\d{4})\)$`)
-	matches := yearRegex.FindStringSubmatch(movieTitle)
-	if len(matches) > 1 {
-		year = matches[1]
-		movieTitle = strings.TrimSpace(yearRegex.ReplaceAllString(movieTitle, ""))
+	if year != "" {
+		originalTitle = fmt.Sprintf("%s (%s)", movieTitle, year)
 	}
 
 	// Try multiple search variations
@@ -251,15 +449,30 @@ func (a *App) GetTMDBDetails(movieTitle string) (TMDBMovie, error) {
 		searchVariations = append(searchVariations, altTitle)
 	}
 
+	// Search with the exact release year first; Letterboxd and TMDB years can
+	// disagree by one, so finish with an unfiltered search scored by year
+	type searchAttempt struct {
+		Title string
+		Year  string
+	}
+	var attempts []searchAttempt
+	for _, variation := range searchVariations {
+		attempts = append(attempts, searchAttempt{Title: variation, Year: year})
+	}
+	if year != "" {
+		attempts = append(attempts, searchAttempt{Title: movieTitle})
+	}
+
 	var movieID int
 	var searchErr error
+	includeAdult := a.loadSettings().IncludeAdult
 
 	// Try each search variation
-	for i, searchTitle := range searchVariations {
-		encodedTitle := url.QueryEscape(searchTitle)
-		searchURL := fmt.Sprintf("https://api.themoviedb.org/3/search/movie?api_key=%s&query=%s", apiKey, encodedTitle)
-		if year != "" {
-			searchURL += "&year=" + year
+	for i, attempt := range attempts {
+		encodedTitle := url.QueryEscape(attempt.Title)
+		searchURL := fmt.Sprintf("https://api.themoviedb.org/3/search/movie?api_key=%s&query=%s&include_adult=%t", apiKey, encodedTitle, includeAdult)
+		if attempt.Year != "" {
+			searchURL += "&primary_release_year=" + attempt.Year
 		}
 
 		log.Printf("TMDB search attempt %d for '%s': %s", i+1, originalTitle, strings.Replace(searchURL, apiKey, "***", 1))
@@ -269,7 +482,7 @@ func (a *App) GetTMDBDetails(movieTitle string) (TMDBMovie, error) {
 			time.Sleep(250 * time.Millisecond)
 		}
 
-		resp, err := http.Get(searchURL)
+		resp, err := a.tmdbGet(searchURL)
 		if err != nil {
 			searchErr = fmt.Errorf("network error: %v", err)
 			continue
@@ -280,7 +493,7 @@ func (a *App) GetTMDBDetails(movieTitle string) (TMDBMovie, error) {
 			resp.Body.Close()
 			log.Printf("Rate limited, waiting 2 seconds...")
 			time.Sleep(2 * time.Second)
-			resp, err = http.Get(searchURL)
+			resp, err = a.tmdbGet(searchURL)
 			if err != nil {
 				searchErr = fmt.Errorf("retry failed: %v", err)
 				continue
@@ -288,8 +501,13 @@ func (a *App) GetTMDBDetails(movieTitle string) (TMDBMovie, error) {
 		}
 
 		if resp.StatusCode != 200 {
+			tmdbErr := parseTMDBError(resp)
 			resp.Body.Close()
-			searchErr = fmt.Errorf("API error: status code %d", resp.StatusCode)
+			searchErr = fmt.Errorf("API error: %v", tmdbErr)
+			// Other variations won't fare better with a rejected key
+			if tmdbErr.invalidKey() {
+				break
+			}
 			continue
 		}
 
@@ -301,20 +519,33 @@ func (a *App) GetTMDBDetails(movieTitle string) (TMDBMovie, error) {
 		}
 		resp.Body.Close()
 
-		if len(searchResult.Results) > 0 {
-			movieID = searchResult.Results[0].ID
+		if movieID = bestCandidate(searchResult, movieTitle, year, includeAdult); movieID != 0 {
 			log.Printf("Found movie '%s' with ID %d on attempt %d", originalTitle, movieID, i+1)
 			break
 		}
 	}
 
 	if movieID == 0 {
-		log.Printf("No TMDB results found for '%s' after %d attempts. Last error: %v", originalTitle, len(searchVariations), searchErr)
-		return tmdbData, fmt.Errorf("no movie found for: %s", originalTitle)
+		log.Printf("No TMDB results found for '%s' after %d attempts. Last error: %v", originalTitle, len(attempts), searchErr)
+		if searchErr != nil {
+			return 0, fmt.Errorf("no movie found for: %s: %v", originalTitle, searchErr)
+		}
+		return 0, fmt.Errorf("no movie found for: %s", originalTitle)
+	}
+
+	return movieID, nil
+}
+
+// fetchTMDBDetails gets detailed movie information by TMDB ID with retry
+func (a *App) fetchTMDBDetails(movieID int, appends string) (TMDBMovie, error) {
+	var tmdbData TMDBMovie
+
+	apiKey := a.getTMDBAPIKey()
+	if apiKey == "" || len(apiKey) < 10 {
+		return tmdbData, fmt.Errorf("TMDB API key not configured")
 	}
 
-	// Get detailed movie information with retry
-	detailsURL := fmt.Sprintf("https://api.themoviedb.org/3/movie/%d?api_key=%s&append_to_response=credits,images", movieID, apiKey)
+	detailsURL := fmt.Sprintf("https://api.themoviedb.org/3/movie/%d?api_key=%s&append_to_response=%s", movieID, apiKey, appends)
 	
 	var resp *http.Response
 	var err error
@@ -323,11 +554,12 @@ func (a *App) GetTMDBDetails(movieTitle string) (TMDBMovie, error) {
 			time.Sleep(500 * time.Millisecond)
 		}
 		
-		resp, err = http.Get(detailsURL)
-		if err == nil && resp.StatusCode == 200 {
+		resp, err = a.tmdbGet(detailsURL)
+		// A missing film or rejected key won't succeed on a retry
+		if err == nil && (resp.StatusCode == 200 || resp.StatusCode == 401 || resp.StatusCode == 404) {
 			break
 		}
-		if resp != nil {
+		if resp != nil && attempt == 0 {
 			resp.Body.Close()
 		}
 	}
@@ -338,7 +570,7 @@ func (a *App) GetTMDBDetails(movieTitle string) (TMDBMovie, error) {
 	defer resp.Body.Close()
 
 	if resp.StatusCode != 200 {
-		return tmdbData, fmt.Errorf("details API error: status code %d", resp.StatusCode)
+		return tmdbData, fmt.Errorf("details API error: %v", parseTMDBError(resp))
 	}
 
 	if err := json.NewDecoder(resp.Body).Decode(&tmdbData); err != nil {
@@ -356,18 +588,24 @@ func (a *App) TestTMDBAPI() (string, error) {
 	}
 
 	// Test with a simple search
-	testURL := fmt.Sprintf("https://api.themoviedb.org/3/search/movie?api_key=%s&query=interstellar", apiKey)
-	resp, err := http.Get(testURL)
+	testURL := fmt.Sprintf("https://api.themoviedb.org/3/search/movie?api_key=%s&query=interstellar&include_adult=false", apiKey)
+	resp, err := a.tmdbGet(testURL)
 	if err != nil {
 		return "", fmt.Errorf("Failed to connect to TMDB: %v", err)
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode == 401 {
-		return "", fmt.Errorf("Invalid TMDB API key")
-	}
 	if resp.StatusCode != 200 {
-		return "", fmt.Errorf("TMDB API error: status code %d", resp.StatusCode)
+		tmdbErr := parseTMDBError(resp)
+		switch {
+		case tmdbErr.invalidKey():
+			return "", fmt.Errorf("TMDB rejected the API key: %v", tmdbErr)
+		case tmdbErr.rateLimited():
+			return "", fmt.Errorf("TMDB rate limit exceeded: %v", tmdbErr)
+		case tmdbErr.notFound():
+			return "", fmt.Errorf("TMDB resource not found: %v", tmdbErr)
+		}
+		return "", fmt.Errorf("TMDB API error: %v", tmdbErr)
 	}
 
 	var searchResult TMDBSearchResult
@@ -378,216 +616,557 @@ func (a *App) TestTMDBAPI() (string, error) {
 	return fmt.Sprintf("TMDB API key is working! Found %d results for 'Interstellar'", len(searchResult.Results)), nil
 }
 
-// FindCommonMovies processes usernames and returns common movies with full details
-func (a *App) FindCommonMovies(usernames []string) ([]Movie, error) {
+// FindCommonMovies processes usernames and returns the bare intersection of
+// their watchlists; TMDB details are fetched lazily with GetMovieDetails
+func (a *App) FindCommonMovies(usernames []string, preset string) ([]Movie, error) {
+	return a.FindCommonMoviesWithOptions(usernames, CompareOptions{Preset: preset})
+}
+
+// FindCommonMoviesWithOptions is FindCommonMovies with tunable comparison options
+func (a *App) FindCommonMoviesWithOptions(usernames []string, opts CompareOptions) ([]Movie, error) {
+	result, err := a.CompareV2(usernames, opts)
+	return result.Movies, err
+}
+
+// CompareV2 runs a comparison and returns its results in the versioned
+// ComparisonResultV2 envelope, together with statistics about the run.
+// An active content policy or audio preference makes it hydrate every
+// title up front and adds a settings_hydrate warning
+func (a *App) CompareV2(usernames []string, opts CompareOptions) (ComparisonResultV2, error) {
+	start := time.Now()
+	result := newComparisonResult(usernames)
 	if len(usernames) == 0 {
-		return nil, fmt.Errorf("no usernames provided")
+		return result, fmt.Errorf("no usernames provided")
 	}
-
-	// Validate users and get avatars
-	userAvatars := make(map[string]string)
-	for _, username := range usernames {
-		avatar, err := a.GetUserAvatar(username)
-		if err != nil {
-			return nil, fmt.Errorf("could not find profile for user: '%s'. The profile may be private or the username is incorrect", username)
+	preset, err := presetFor(opts.Preset)
+	if err != nil {
+		return result, err
+	}
+	if opts.Theme != "" {
+		if _, err := themeFor(opts.Theme); err != nil {
+			return result, err
 		}
-		userAvatars[username] = avatar
 	}
-
-	// Scrape watchlists concurrently
-	type WatchlistResult struct {
-		Username string
-		Movies   map[string]string
-		Error    error
+	if opts.RatingFilter != nil {
+		if err := opts.RatingFilter.validate(); err != nil {
+			return result, err
+		}
 	}
 
-	watchlistChan := make(chan WatchlistResult, len(usernames))
-	var wg sync.WaitGroup
-
-	for _, username := range usernames {
-		wg.Add(1)
-		go func(user string) {
-			defer wg.Done()
-			movies, err := a.GetWatchlist(user)
-			watchlistChan <- WatchlistResult{
-				Username: user,
-				Movies:   movies,
-				Error:    err,
-			}
-		}(username)
-	}
+	warn := &warnings{}
+	staleBefore := a.scrapeCache.staleServed()
+	timer := a.newStageTimer("compare")
 
-	wg.Wait()
-	close(watchlistChan)
+	// Progress is checkpointed so an interrupted comparison can be resumed
+	cp := a.resumeCheckpoint(usernames)
+
+	scrapedData, profiles, err := a.scrapeWatchlists(usernames, cp, preset, warn)
+	if err != nil {
+		return result, err
+	}
+	if stale := a.scrapeCache.staleServed() - staleBefore; stale > 0 {
+		warn.addf(WarningStaleCache, "", stale, "Letterboxd was unreachable; %d cached pages were used instead", stale)
+	}
+	for username, watchlist := range scrapedData {
+		result.Stats.WatchlistSizes[username] = len(watchlist)
+	}
+	timer.mark("scrape")
 
-	// Process results
-	scrapedData := make(map[string]map[string]string)
-	validUsers := []string{}
+	// Find common movies, keyed by Letterboxd film slug, only reapplying
+	// the watchlists that changed since this group was last compared
+	movieCounts := a.intersectIncrementally(usernames, scrapedData)
+	timer.mark("intersect")
 
-	for result := range watchlistChan {
-		if result.Error != nil {
-			return nil, fmt.Errorf("could not find a public watchlist for user: '%s'. The profile may be private, empty, or the username is incorrect", result.Username)
-		}
-		scrapedData[result.Username] = result.Movies
-		validUsers = append(validUsers, result.Username)
+	// Vetoed movies are dropped from every comparison
+	exclusions, err := a.loadExclusions()
+	if err != nil {
+		log.Printf("Could not load exclusions: %v", err)
 	}
 
-	// Find common movies
-	movieCounts := make(map[string]struct {
-		Users []string
-		URL   string
-	})
+	// Director's cuts, extended editions and re-releases count as the film
+	var editions map[string][]Edition
+	if !opts.SeparateEditions {
+		movieCounts, editions = mergeEditions(movieCounts, func(key string) bool {
+			_, excluded := exclusions[key]
+			return excluded
+		})
+	}
+	notes, err := a.GetMovieNotes()
+	if err != nil {
+		log.Printf("Could not load notes: %v", err)
+	}
+	tags, err := a.GetTags()
+	if err != nil {
+		log.Printf("Could not load tags: %v", err)
+	}
+	pins, err := a.loadPins()
+	if err != nil {
+		log.Printf("Could not load pins: %v", err)
+	}
 
-	for user, watchlist := range scrapedData {
-		for movieTitle, movieURL := range watchlist {
-			if _, exists := movieCounts[movieTitle]; !exists {
-				movieCounts[movieTitle] = struct {
-					Users []string
-					URL   string
-				}{
-					Users: []string{user},
-					URL:   movieURL,
-				}
-			} else {
-				data := movieCounts[movieTitle]
-				data.Users = append(data.Users, user)
-				movieCounts[movieTitle] = data
+	// Collect movies with 2+ users
+	var processedMovies []Movie
+	// In rewatch mode, participants who loved a film count towards its overlap
+	var watched map[string]map[string]WatchedFilm
+	if opts.IncludeRewatches && preset.Offline {
+		warn.addf(WarningWatchedUnavailable, "", 0, "Watched films aren't available offline; rewatches are ignored")
+	} else if opts.IncludeRewatches {
+		watched = a.scrapeWatchedLists(usernames)
+		for _, username := range usernames {
+			if _, imported := a.importedList(username); imported {
+				continue
+			}
+			if _, ok := watched[username]; !ok {
+				warn.addf(WarningWatchedUnavailable, username, 0, "Could not load films watched by %s; rewatches ignore them", username)
 			}
 		}
 	}
 
-	// Process movies with 2+ users and get TMDB details
-	var processedMovies []Movie
-	for title, data := range movieCounts {
-		if len(data.Users) >= 2 {
-			tmdbDetails, err := a.GetTMDBDetails(title)
-			
+	for key, data := range movieCounts {
+		if _, excluded := exclusions[key]; excluded {
+			result.Stats.Excluded++
+			continue
+		}
+
+		var seenBy, lovedBy []string
+		if opts.IncludeRewatches {
+			seenBy, lovedBy = rewatchInfo(key, data.Users, watched)
+		}
+
+		if len(data.Users)+len(lovedBy) >= 2 {
 			var movie Movie
-			movie.Title = title
+			movie.Key = key
+			movie.Title = data.Title
 			movie.URL = data.URL
 			movie.Count = len(data.Users)
-
-			// Create user objects
+			movie.SeenBy = seenBy
+			movie.LovedBy = lovedBy
+			movie.Note = notes[key].Note
+			movie.Tags = tags[key]
+			_, movie.Pinned = pins[key]
+			movie.Editions = editions[key]
+
+			// Create user objects, summing their weights into the overlap score
 			for _, username := range data.Users {
-				movie.Users = append(movie.Users, User{
-					Name:   username,
-					Avatar: userAvatars[username],
-				})
+				movie.Users = append(movie.Users, profiles[username].user(username))
+				movie.Score += opts.weight(username)
 			}
-
-			if err != nil {
-				log.Printf("Could not fetch TMDB details for '%s': %v", title, err)
-				// Set default values
-				movie.Rating = 0.0
-				movie.FormattedRating = "N/A"
-				movie.PosterURL = "https://placehold.co/500x750/1f1f1f/ffffff?text=No+Poster"
-				movie.BackdropURL = movie.PosterURL
-				movie.LogoURL = ""
-				movie.ReleaseDate = "0000-00-00"
-				movie.ReleaseYear = "----"
-				movie.Runtime = 0
-				movie.FormattedRuntime = ""
-				movie.Genres = []string{}
-				movie.IMDBID = ""
-				movie.Overview = "No overview available."
-				movie.Director = Person{Name: "N/A", ID: 0}
-				movie.Cast = []Person{}
-			} else {
-				// Process TMDB data
-				movie.Rating = tmdbDetails.VoteAverage
-				if movie.Rating > 0 {
-					movie.FormattedRating = fmt.Sprintf("%.1f", movie.Rating)
-				} else {
-					movie.FormattedRating = "N/A"
-				}
-
-				if tmdbDetails.PosterPath != "" {
-					movie.PosterURL = fmt.Sprintf("https://image.tmdb.org/t/p/w500%s", tmdbDetails.PosterPath)
-				} else {
-					movie.PosterURL = "https://placehold.co/500x750/1f1f1f/ffffff?text=No+Poster"
-				}
-
-				if tmdbDetails.BackdropPath != "" {
-					movie.BackdropURL = fmt.Sprintf("https://image.tmdb.org/t/p/original%s", tmdbDetails.BackdropPath)
-				} else {
-					movie.BackdropURL = movie.PosterURL
-				}
-
-				// Find logo
-				logoPath := ""
-				noLangLogoPath := ""
-				for _, logo := range tmdbDetails.Images.Logos {
-					if logo.ISO6391 != nil && *logo.ISO6391 == "en" {
-						logoPath = logo.FilePath
-						break
-					}
-					if noLangLogoPath == "" && (logo.ISO6391 == nil || *logo.ISO6391 == "xx") {
-						noLangLogoPath = logo.FilePath
-					}
-				}
-				if logoPath == "" {
-					logoPath = noLangLogoPath
-				}
-				if logoPath != "" {
-					movie.LogoURL = fmt.Sprintf("https://image.tmdb.org/t/p/original%s", logoPath)
-				}
-
-				movie.ReleaseDate = tmdbDetails.ReleaseDate
-				if tmdbDetails.ReleaseDate != "" {
-					parts := strings.Split(tmdbDetails.ReleaseDate, "-")
-					if len(parts) > 0 {
-						movie.ReleaseYear = parts[0]
-					}
-				}
-				if movie.ReleaseYear == "" {
-					movie.ReleaseYear = "----"
-				}
-
-				movie.Runtime = tmdbDetails.Runtime
-				if movie.Runtime > 0 {
-					movie.FormattedRuntime = fmt.Sprintf("%d min", movie.Runtime)
-				}
-
-				// Genres
-				for _, genre := range tmdbDetails.Genres {
-					movie.Genres = append(movie.Genres, genre.Name)
-				}
-
-				movie.IMDBID = tmdbDetails.IMDBID
-				movie.Overview = tmdbDetails.Overview
-				if movie.Overview == "" {
-					movie.Overview = "No overview available."
-				}
-
-				// Director
-				movie.Director = Person{Name: "N/A", ID: 0}
-				for _, crew := range tmdbDetails.Credits.Crew {
-					if crew.Job == "Director" {
-						movie.Director = Person{Name: crew.Name, ID: crew.ID}
-						break
-					}
-				}
-
-				// Cast (first 5)
-				for i, cast := range tmdbDetails.Credits.Cast {
-					if i >= 5 {
-						break
-					}
-					movie.Cast = append(movie.Cast, Person{Name: cast.Name, ID: cast.ID})
-				}
+			for _, username := range lovedBy {
+				movie.Score += opts.weight(username)
 			}
 
+			// Details are hydrated on demand via GetMovieDetails
+			a.applyPlaceholders(&movie)
+
 			processedMovies = append(processedMovies, movie)
 		}
 	}
+	timer.mark("collect")
+
+	// Filtering by entry type, release status, availability, accessibility,
+	// theme, awards, ratings or the content policy and sorting by
+	// availability, variety, awards or affinity need TMDB data, so only
+	// then are details fetched up front. The content policy and audio
+	// preference are saved settings, so while either is set every
+	// comparison hydrates eagerly; a warning says so
+	policy := a.GetContentPolicy()
+	audioPreference := a.loadSettings().AudioPreference
+	optionsNeedDetails := len(opts.EntryTypes) > 0 || len(opts.Classifications) > 0 || opts.ShortsNight || opts.HideUnreleased || opts.SortBy == SortNewlyAvailable || opts.SubscriptionOnly ||
+		len(opts.SubtitleLanguages) > 0 || opts.RequireAudioDescription || opts.Theme != "" ||
+		opts.VarietyBoost || opts.AwardWinners || opts.SortBy == SortAwards || opts.SortBy == SortAffinity ||
+		opts.RatingFilter != nil
+	settingsNeedDetails := policy.active() || audioPreference != ""
+	needsDetails := optionsNeedDetails || settingsNeedDetails
+	if settingsNeedDetails && !optionsNeedDetails && !preset.Hydrate && !preset.Offline {
+		warn.addf(WarningSettingsHydrate, "", 0, "Your content policy or audio preference needs film details, so every title was looked up before showing results")
+	}
+	if needsDetails && preset.Offline {
+		warn.addf(WarningOfflineFilters, "", 0, "Filters and sorting that need film details are skipped offline")
+	} else if needsDetails || preset.Hydrate {
+		if unmatched := a.hydrateMovies(processedMovies, cp, preset); unmatched > 0 {
+			warn.addf(WarningUnmatchedTitles, "", unmatched, "%d titles could not be matched on TMDB", unmatched)
+		}
+		timer.mark("hydrate")
+		if !opts.SeparateEditions {
+			processedMovies = mergeSameFilm(processedMovies, opts)
+		}
+		if policy.active() {
+			processedMovies = filterContent(processedMovies, policy)
+		}
+		if len(opts.EntryTypes) > 0 {
+			processedMovies = filterEntryTypes(processedMovies, opts.EntryTypes)
+		}
+		if len(opts.Classifications) > 0 {
+			processedMovies = filterClassifications(processedMovies, opts.Classifications)
+		}
+		if opts.ShortsNight {
+			processedMovies = filterShortsNight(processedMovies)
+		}
+		if opts.HideUnreleased {
+			processedMovies = filterUnreleased(processedMovies)
+		}
+		if opts.SubscriptionOnly {
+			processedMovies = a.filterSubscription(processedMovies)
+		}
+		if len(opts.SubtitleLanguages) > 0 || opts.RequireAudioDescription {
+			processedMovies = a.filterAccessibility(processedMovies, opts.SubtitleLanguages, opts.RequireAudioDescription)
+		}
+		if audioPreference != "" {
+			processedMovies = a.filterAudio(processedMovies, audioPreference)
+		}
+		if opts.Theme != "" {
+			processedMovies = applyTheme(processedMovies, opts.Theme, opts.ThemeBoost)
+		}
+		if opts.VarietyBoost {
+			a.applyVariety(processedMovies)
+		}
+		if opts.AwardWinners || opts.SortBy == SortAwards {
+			a.annotateAwards(processedMovies)
+		}
+		if opts.AwardWinners {
+			processedMovies = filterAwardWinners(processedMovies)
+		}
+		if a.loadSettings().AnimeMetadata {
+			a.annotateAnime(processedMovies)
+		}
+		if opts.SortBy == SortAffinity {
+			if err := a.annotateAffinity(processedMovies, usernames, opts); err != nil {
+				log.Printf("Could not sort by affinity: %v", err)
+			}
+		}
+		if opts.RatingFilter != nil {
+			if opts.RatingFilter.needsCritics() {
+				a.annotateCritics(processedMovies)
+			}
+			processedMovies = filterRatings(processedMovies, *opts.RatingFilter)
+		}
+	}
+	if len(opts.Tags) > 0 {
+		processedMovies = filterTags(processedMovies, opts.Tags)
+	}
+	if opts.TrendingBoost && !preset.Offline {
+		a.applyTrending(processedMovies)
+	}
+	timer.mark("filter")
+
+	// Sort pinned movies first, then by weighted score, composite rating,
+	// count and title; configured composite weights put the rating first
+	sortResults(processedMovies, opts, a.compositeConfigured())
+	timer.mark("sort")
+
+	a.finishCheckpoint(cp)
+	a.refreshes.comparisonFinished()
+
+	result.Movies = processedMovies
+	result.Warnings = warn.all()
+	result.Stats.CommonMovies = len(processedMovies)
+	result.Stats.DurationMS = time.Since(start).Milliseconds()
+	a.saveComparison(&result)
+	timer.mark("save")
+	timer.done(fmt.Sprintf("users=%d", len(usernames)), fmt.Sprintf("movies=%d", len(processedMovies)))
+	return result, nil
+}
+
+// enrichMovie fills a movie's details from the configured metadata
+// providers, falling back to placeholder values
+func (a *App) enrichMovie(movie *Movie) error {
+	return a.enrichMovieWith(movie, a.providers())
+}
+
+// enrichMovieWith is enrichMovie with an explicit provider chain
+func (a *App) enrichMovieWith(movie *Movie, chain []MetadataProvider) error {
+	if tmdbID := a.aliasFor(movie.Key); tmdbID != 0 {
+		if err := a.enrichByTMDBID(movie, tmdbID); err != nil {
+			log.Printf("Could not fetch details for '%s': %v", movie.Title, err)
+			a.applyPlaceholders(movie)
+			return err
+		}
+		return nil
+	}
+
+	title, year := filmYear(movie.Title, movie.URL)
+	details, err := a.lookupMetadata(chain, title, year)
+	if err != nil {
+		log.Printf("Could not fetch details for '%s': %v", movie.Title, err)
+		a.applyPlaceholders(movie)
+		return err
+	}
+	mergeMetadata(movie, details)
+	movie.CompositeScore = compositeScore(*movie, a.GetCompositeWeights())
+	return nil
+}
+
+// movieFromTMDB converts TMDB details into a movie's metadata fields
+func (a *App) movieFromTMDB(tmdbDetails TMDBMovie, title string) Movie {
+	movie := Movie{Title: title}
+	movie.TMDBID = tmdbDetails.ID
+	movie.Rating = tmdbDetails.VoteAverage
+	movie.VoteCount = tmdbDetails.VoteCount
+	if movie.Rating > 0 {
+		movie.FormattedRating = fmt.Sprintf("%.1f", movie.Rating)
+	} else {
+		movie.FormattedRating = "N/A"
+	}
+
+	posterPath := tmdbDetails.PosterPath
+	if preferred := a.preferredPoster(tmdbDetails.ID); preferred != "" {
+		posterPath = preferred
+	}
+	if posterPath != "" {
+		movie.PosterURL = fmt.Sprintf("https://image.tmdb.org/t/p/%s%s", a.posterSize(), posterPath)
+	} else {
+		movie.PosterURL = a.placeholderPoster(title)
+	}
+
+	if tmdbDetails.BackdropPath != "" {
+		movie.BackdropURL = fmt.Sprintf("https://image.tmdb.org/t/p/original%s", tmdbDetails.BackdropPath)
+	} else {
+		movie.BackdropURL = movie.PosterURL
+	}
+
+	if logoPath := selectLogo(tmdbDetails.Images.Logos, a.logoLanguages()); logoPath != "" {
+		movie.LogoURL = fmt.Sprintf("https://image.tmdb.org/t/p/original%s", logoPath)
+	}
+
+	movie.ReleaseDate = tmdbDetails.ReleaseDate
+	if tmdbDetails.ReleaseDate != "" {
+		parts := strings.Split(tmdbDetails.ReleaseDate, "-")
+		if len(parts) > 0 {
+			movie.ReleaseYear = parts[0]
+		}
+	}
+	if movie.ReleaseYear == "" {
+		movie.ReleaseYear = "----"
+	}
 
-	// Sort by count (descending) then by rating (descending)
-	sort.Slice(processedMovies, func(i, j int) bool {
-		if processedMovies[i].Count != processedMovies[j].Count {
-			return processedMovies[i].Count > processedMovies[j].Count
+	movie.Runtime = tmdbDetails.Runtime
+	if movie.Runtime > 0 {
+		movie.FormattedRuntime = formatRuntime(movie.Runtime, a.language())
+	}
+
+	// Genres
+	for _, genre := range tmdbDetails.Genres {
+		movie.Genres = append(movie.Genres, genre.Name)
+	}
+
+	movie.IMDBID = tmdbDetails.IMDBID
+	movie.OriginalLanguage = tmdbDetails.OriginalLanguage
+	region := a.region()
+	movie.Certification = tmdbCertification(tmdbDetails, region)
+	movie.DigitalReleaseDate = tmdbReleaseDate(tmdbDetails, region, releaseDigital)
+	movie.PhysicalReleaseDate = tmdbReleaseDate(tmdbDetails, region, releasePhysical)
+	movie.EntryType = classifyEntry(tmdbDetails)
+	movie.Classification = classifyMovie(movie.EntryType, movie.Runtime, movie.Genres)
+	if movie.EntryType == EntryConcert {
+		movie.Artist = concertArtist(tmdbDetails, title)
+	}
+	movie.Themes = themesFor(tmdbDetails)
+	for _, keyword := range tmdbDetails.Keywords.Keywords {
+		movie.Keywords = append(movie.Keywords, strings.ToLower(keyword.Name))
+	}
+	movie.Status = releaseStatus(tmdbDetails.Status, tmdbDetails.ReleaseDate, time.Now())
+	movie.Overview = tmdbDetails.Overview
+	if movie.Overview == "" {
+		movie.Overview = "No overview available."
+	}
+
+	// Director
+	movie.Director = Person{Name: "N/A", ID: 0}
+	for _, crew := range tmdbDetails.Credits.Crew {
+		if crew.Job == "Director" {
+			movie.Director = Person{Name: crew.Name, ID: crew.ID}
+			break
+		}
+	}
+
+	// Cast (first 5)
+	for i, cast := range tmdbDetails.Credits.Cast {
+		if i >= 5 {
+			break
+		}
+		movie.Cast = append(movie.Cast, Person{Name: cast.Name, ID: cast.ID})
+	}
+
+	return movie
+}
+
+// applyPlaceholders sets the default values shown for a movie without TMDB details
+func (a *App) applyPlaceholders(movie *Movie) {
+	movie.Rating = 0.0
+	movie.FormattedRating = "N/A"
+	movie.PosterURL = a.placeholderPoster(movie.Title)
+	movie.BackdropURL = movie.PosterURL
+	movie.LogoURL = ""
+	movie.ReleaseDate = "0000-00-00"
+	movie.ReleaseYear = "----"
+	movie.Runtime = 0
+	movie.FormattedRuntime = ""
+	movie.Genres = []string{}
+	movie.IMDBID = ""
+	movie.OriginalLanguage = ""
+	movie.Certification = ""
+	movie.EntryType = EntryUnknown
+	movie.Classification = ClassificationUnknown
+	movie.Artist = ""
+	movie.Themes = nil
+	movie.Keywords = nil
+	movie.Status = ""
+	movie.VoteCount = 0
+	movie.CompositeScore = 0
+	movie.DigitalReleaseDate = ""
+	movie.PhysicalReleaseDate = ""
+	movie.Overview = "No overview available."
+	movie.Director = Person{Name: "N/A", ID: 0}
+	movie.Cast = []Person{}
+	movie.TMDBID = 0
+}
+
+// GetMovieDetails hydrates a single common movie with TMDB details,
+// Letterboxd rating, review snippets and, when signed in, friends' ratings
+// on demand, returning placeholder values if no match could be found
+func (a *App) GetMovieDetails(title string, url string) (Movie, error) {
+	movie := Movie{Key: movieKey(url), Title: title, URL: url}
+	a.enrichMovie(&movie)
+
+	stats, err := a.GetLetterboxdStats(url)
+	if err != nil {
+		log.Printf("Could not fetch Letterboxd stats for '%s': %v", title, err)
+	}
+	movie.LetterboxdRating = stats.Rating
+	movie.LetterboxdWatches = stats.Watches
+	if a.loadSettings().OMDbAPIKey != "" && movie.IMDBID != "" {
+		scores, err := a.GetCriticScores(movie)
+		if err != nil {
+			log.Printf("Could not fetch critic scores for '%s': %v", title, err)
+		}
+		setCriticScores(&movie, scores)
+	}
+	movie.CompositeScore = compositeScore(movie, a.GetCompositeWeights())
+	if a.loadSettings().AnimeMetadata && isAnime(movie) {
+		if info, err := a.GetAnimeInfo(movie); err != nil {
+			log.Printf("Could not fetch anime info for '%s': %v", title, err)
+		} else {
+			movie.Anime = &info
+		}
+	}
+
+	// Rent/buy-only films get prices so the group can pick the cheapest offer
+	if movie.TMDBID != 0 {
+		providers, err := a.GetWatchProviders(movie.TMDBID)
+		if err != nil {
+			log.Printf("Could not fetch watch providers for '%s': %v", title, err)
+		} else if !subscriptionIncluded(providers) && len(providers.Rent)+len(providers.Buy) > 0 {
+			if movie.RentalPrices, err = a.GetRentalPrices(movie); err != nil {
+				log.Printf("Could not fetch rental prices for '%s': %v", title, err)
+			}
+		}
+
+		access, err := a.GetAccessibility(movie)
+		if err != nil {
+			log.Printf("Could not fetch accessibility data for '%s': %v", title, err)
+		}
+		setAccessibility(&movie, access)
+	}
+
+	if awards, err := a.GetAwards(movie); err != nil {
+		log.Printf("Could not fetch awards for '%s': %v", title, err)
+	} else {
+		setAwards(&movie, awards)
+	}
+
+	reviews, err := a.GetReviews(url)
+	if err != nil {
+		log.Printf("Could not fetch reviews for '%s': %v", title, err)
+	}
+	movie.Reviews = reviews
+
+	if a.loadSettings().LetterboxdSession != "" {
+		friendRatings, err := a.GetFriendRatings(url)
+		if err != nil {
+			log.Printf("Could not fetch friend ratings for '%s': %v", title, err)
+		}
+		movie.FriendRatings = friendRatings
+	}
+
+	return movie, nil
+}
+
+// scrapeWatchlists scrapes all watchlists and their users' profiles on the
+// shared worker pool, using imported lists for non-Letterboxd participants
+// and watchlists already saved in the checkpoint (if any), and remembers them
+// for follow-up features such as GetSimilar; a failed scrape is retried once.
+// Offline presets use each user's last saved watchlist instead
+func (a *App) scrapeWatchlists(usernames []string, cp *checkpoint, preset Preset, warn *warnings) (map[string]map[string]WatchlistEntry, map[string]userProfile, error) {
+	type WatchlistResult struct {
+		Username string
+		Profile  userProfile
+		Movies   map[string]WatchlistEntry
+		Error    error
+	}
+
+	watchlistChan := make(chan WatchlistResult, len(usernames))
+
+	a.forEachUser(usernames, preset.Workers, func(user string) {
+		if imported, ok := a.importedList(user); ok {
+			watchlistChan <- WatchlistResult{Username: user, Movies: imported.Entries}
+			return
+		}
+		if profile, movies, ok := cp.watchlist(user); ok {
+			warn.addf(WarningCheckpointResumed, user, 0, "Reused %s's watchlist from an interrupted comparison", user)
+			watchlistChan <- WatchlistResult{Username: user, Profile: profile, Movies: movies}
+			return
+		}
+		if preset.Offline {
+			movies, ok := a.savedWatchlist(user)
+			if !ok {
+				watchlistChan <- WatchlistResult{Username: user, Error: fmt.Errorf("no saved watchlist for user: '%s'. Compare once while online first", user)}
+				return
+			}
+			watchlistChan <- WatchlistResult{Username: user, Movies: movies}
+			return
+		}
+		if preset.ReuseUnchanged {
+			if profile, movies, ok := a.unchangedWatchlist(user); ok {
+				a.recordWatchlist(cp, user, profile, movies)
+				watchlistChan <- WatchlistResult{Username: user, Profile: profile, Movies: movies}
+				return
+			}
+		}
+		profile, movies, err := a.scrapeWatchlist(user)
+		if err != nil {
+			a.letterboxdLimiter.wait()
+			if profile, movies, err = a.scrapeWatchlist(user); err == nil {
+				warn.addf(WarningWatchlistRetried, user, 0, "%s's watchlist only loaded after a retry", user)
+			}
+		}
+		if err == nil {
+			a.recordWatchlist(cp, user, profile, movies)
+		}
+		watchlistChan <- WatchlistResult{
+			Username: user,
+			Profile:  profile,
+			Movies:   movies,
+			Error:    err,
 		}
-		return processedMovies[i].Rating > processedMovies[j].Rating
 	})
+	close(watchlistChan)
+
+	scrapedData := make(map[string]map[string]WatchlistEntry)
+	profiles := make(map[string]userProfile)
+	for result := range watchlistChan {
+		if result.Error != nil && preset.Offline {
+			return nil, nil, result.Error
+		}
+		if result.Error != nil {
+			return nil, nil, fmt.Errorf("could not find a public watchlist for user: '%s'. The profile may be private, empty, or the username is incorrect", result.Username)
+		}
+		scrapedData[result.Username] = result.Movies
+		profiles[result.Username] = result.Profile
+	}
+
+	a.mu.Lock()
+	a.watchlists = scrapedData
+	a.mu.Unlock()
 
-	return processedMovies, nil
+	return scrapedData, profiles, nil
 }
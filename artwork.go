@@ -0,0 +1,185 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// defaultLogoLanguages is the logo language preference used when none is
+// configured; "xx" stands for logos without text in any language
+var defaultLogoLanguages = []string{"en", "xx"}
+
+// logoLanguages returns the configured logo language preference
+func (a *App) logoLanguages() []string {
+	if languages := a.loadSettings().LogoLanguages; len(languages) > 0 {
+		return languages
+	}
+	return defaultLogoLanguages
+}
+
+// SetLogoLanguages sets the order in which logo languages are preferred,
+// e.g. ["de", "en", "xx"]; an empty list restores the default
+func (a *App) SetLogoLanguages(languages []string) error {
+	var cleaned []string
+	for _, language := range languages {
+		if language = strings.ToLower(strings.TrimSpace(language)); language != "" {
+			cleaned = append(cleaned, language)
+		}
+	}
+	settings := a.loadSettings()
+	settings.LogoLanguages = cleaned
+	return a.saveSettings(settings)
+}
+
+// imageLanguage returns an image's ISO 639-1 language, "xx" if it has none
+func imageLanguage(image TMDBImage) string {
+	if image.ISO6391 == nil || *image.ISO6391 == "" {
+		return "xx"
+	}
+	return strings.ToLower(*image.ISO6391)
+}
+
+// selectLogo picks the first logo in the most preferred language, falling
+// back to any available logo so a film with only foreign-language title
+// art still gets one
+func selectLogo(logos []TMDBImage, languages []string) string {
+	for _, language := range languages {
+		for _, logo := range logos {
+			if imageLanguage(logo) == language {
+				return logo.FilePath
+			}
+		}
+	}
+	if len(logos) > 0 {
+		return logos[0].FilePath
+	}
+	return ""
+}
+
+// Artwork is one image in a film's gallery
+type Artwork struct {
+	FilePath     string  `json:"file_path"`
+	URL          string  `json:"url"`
+	ThumbnailURL string  `json:"thumbnail_url"`
+	Language     string  `json:"language"`
+	Width        int     `json:"width"`
+	Height       int     `json:"height"`
+	VoteAverage  float64 `json:"vote_average"`
+	Preferred    bool    `json:"preferred"`
+}
+
+// MovieImages is the full set of artwork TMDB has for a film
+type MovieImages struct {
+	Posters   []Artwork `json:"posters"`
+	Backdrops []Artwork `json:"backdrops"`
+	Logos     []Artwork `json:"logos"`
+}
+
+// GetMovieImages fetches every poster, backdrop and logo for a film so the
+// detail view can show a gallery, marking the user's preferred poster
+func (a *App) GetMovieImages(movieID int) (MovieImages, error) {
+	apiKey := a.getTMDBAPIKey()
+	if apiKey == "" || len(apiKey) < 10 {
+		return MovieImages{}, fmt.Errorf("TMDB API key not configured")
+	}
+
+	resp, err := a.tmdbGet(fmt.Sprintf("https://api.themoviedb.org/3/movie/%d/images?api_key=%s", movieID, apiKey))
+	if err != nil {
+		return MovieImages{}, fmt.Errorf("failed to get movie images: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		return MovieImages{}, fmt.Errorf("images API error: %v", parseTMDBError(resp))
+	}
+
+	var images struct {
+		Posters   []TMDBImage `json:"posters"`
+		Backdrops []TMDBImage `json:"backdrops"`
+		Logos     []TMDBImage `json:"logos"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&images); err != nil {
+		return MovieImages{}, fmt.Errorf("failed to parse movie images: %v", err)
+	}
+
+	preferred := a.preferredPoster(movieID)
+	result := MovieImages{
+		Posters:   toArtwork(images.Posters, "w185"),
+		Backdrops: toArtwork(images.Backdrops, "w300"),
+		Logos:     toArtwork(images.Logos, "w185"),
+	}
+	for i := range result.Posters {
+		result.Posters[i].Preferred = result.Posters[i].FilePath == preferred
+	}
+	return result, nil
+}
+
+// toArtwork converts TMDB images to gallery entries, best rated first
+func toArtwork(images []TMDBImage, thumbnailSize string) []Artwork {
+	artwork := make([]Artwork, 0, len(images))
+	for _, image := range images {
+		artwork = append(artwork, Artwork{
+			FilePath:     image.FilePath,
+			URL:          "https://image.tmdb.org/t/p/original" + image.FilePath,
+			ThumbnailURL: "https://image.tmdb.org/t/p/" + thumbnailSize + image.FilePath,
+			Language:     imageLanguage(image),
+			Width:        image.Width,
+			Height:       image.Height,
+			VoteAverage:  image.VoteAverage,
+		})
+	}
+	sort.SliceStable(artwork, func(i, j int) bool {
+		return artwork[i].VoteAverage > artwork[j].VoteAverage
+	})
+	return artwork
+}
+
+// SetPreferredPoster remembers the poster to show for a film; an empty
+// file path restores TMDB's default
+func (a *App) SetPreferredPoster(movieID int, filePath string) error {
+	a.postersMu.Lock()
+	defer a.postersMu.Unlock()
+
+	posters := make(map[string]string)
+	if err := a.store.load("posters", &posters); err != nil {
+		return err
+	}
+	if filePath == "" {
+		delete(posters, strconv.Itoa(movieID))
+	} else {
+		posters[strconv.Itoa(movieID)] = filePath
+	}
+	return a.store.save("posters", posters)
+}
+
+// preferredPoster returns the poster the user picked for a film, if any
+func (a *App) preferredPoster(movieID int) string {
+	a.postersMu.Lock()
+	defer a.postersMu.Unlock()
+
+	posters := make(map[string]string)
+	if err := a.store.load("posters", &posters); err != nil {
+		log.Printf("Could not load preferred posters: %v", err)
+	}
+	return posters[strconv.Itoa(movieID)]
+}
+
+// posterSize returns the TMDB size posters are requested at
+func (a *App) posterSize() string {
+	if a.loadSettings().HighResArtwork {
+		return "original"
+	}
+	return "w500"
+}
+
+// SetHighResArtwork switches posters between the standard and original
+// resolution
+func (a *App) SetHighResArtwork(enabled bool) error {
+	settings := a.loadSettings()
+	settings.HighResArtwork = enabled
+	return a.saveSettings(settings)
+}
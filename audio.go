@@ -0,0 +1,81 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Audio preferences for foreign-language films
+const (
+	AudioSubtitled = "subtitled" // Original audio with subtitles
+	AudioDubbed    = "dubbed"    // Audio in the interface language
+)
+
+// SetAudioPreference sets whether foreign-language films must be available
+// in their original language with subtitles (AudioSubtitled) or dubbed into
+// the interface language (AudioDubbed) on the configured streaming services;
+// empty accepts either
+func (a *App) SetAudioPreference(preference string) error {
+	switch preference {
+	case "", AudioSubtitled, AudioDubbed:
+	default:
+		return fmt.Errorf("unknown audio preference '%s'", preference)
+	}
+	settings := a.loadSettings()
+	settings.AudioPreference = preference
+	return a.saveSettings(settings)
+}
+
+// foreignLanguage reports whether a hydrated movie's original language
+// differs from the interface language
+func foreignLanguage(movie Movie, language string) bool {
+	base, _, _ := strings.Cut(strings.ToLower(language), "-")
+	return movie.OriginalLanguage != "" && movie.OriginalLanguage != base
+}
+
+// audioAllowed reports whether a foreign-language movie's audio tracks
+// satisfy the preference; subtitles are only required if the services
+// report any. Movies without audio data are allowed.
+func audioAllowed(movie Movie, preference string, language string) bool {
+	if len(movie.AudioLanguages) == 0 {
+		return true
+	}
+	switch preference {
+	case AudioSubtitled:
+		return movie.OriginalAudio &&
+			(len(movie.SubtitleLanguages) == 0 || hasSubtitles(movie.SubtitleLanguages, []string{language}))
+	case AudioDubbed:
+		base, _, _ := strings.Cut(strings.ToLower(language), "-")
+		for _, audio := range movie.AudioLanguages {
+			if audio == base {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// filterAudio annotates the foreign-language movies with accessibility data
+// and drops those not available the way the audio preference asks for;
+// movies the services have no data on are kept
+func (a *App) filterAudio(movies []Movie, preference string) []Movie {
+	language := a.language()
+	forEachIndex(len(movies), enrichWorkers, func(index int) {
+		movie := movies[index]
+		if movie.TMDBID == 0 || len(movie.AudioLanguages) > 0 || !foreignLanguage(movie, language) {
+			return
+		}
+		if access, err := a.GetAccessibility(movie); err == nil {
+			setAccessibility(&movies[index], access)
+		}
+	})
+
+	filtered := movies[:0]
+	for _, movie := range movies {
+		if !foreignLanguage(movie, language) || audioAllowed(movie, preference, language) {
+			filtered = append(filtered, movie)
+		}
+	}
+	return filtered
+}
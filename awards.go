@@ -0,0 +1,143 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// wikidataAwardsQuery lists the awards a film won (P166) or was nominated
+// for (P1411), finding it by TMDB (%[1]d) or IMDb (%[2]q) ID
+const wikidataAwardsQuery = `SELECT DISTINCT ?awardLabel ?kind WHERE {
+  { ?film wdt:P4947 "%[1]d" . } UNION { ?film wdt:P345 %[2]q . }
+  { ?film wdt:P166 ?award . BIND("win" AS ?kind) }
+  UNION
+  { ?film wdt:P1411 ?award . BIND("nomination" AS ?kind) }
+  ?award rdfs:label ?awardLabel . FILTER(LANG(?awardLabel) = "en")
+}`
+
+// majorAwards are the award name prefixes that count as major wins or
+// nominations
+var majorAwards = []string{
+	"Academy Award",
+	"Golden Globe",
+	"BAFTA Award",
+	"Palme d'Or",
+	"Grand Prix (Cannes",
+	"Golden Lion",
+	"Silver Lion",
+	"Golden Bear",
+	"Silver Bear",
+	"César Award",
+	"Independent Spirit Award",
+	"Sundance Grand Jury Prize",
+}
+
+// Award is a major award a film won or was nominated for
+type Award struct {
+	Name string `json:"name"`
+	Won  bool   `json:"won"`
+}
+
+// GetAwards returns the major awards a film won or was nominated for,
+// wins first, as recorded on Wikidata
+func (a *App) GetAwards(movie Movie) ([]Award, error) {
+	if movie.TMDBID == 0 && movie.IMDBID == "" {
+		return nil, fmt.Errorf("no TMDB or IMDb ID for '%s' to find awards with", movie.Title)
+	}
+
+	params := url.Values{
+		"query":  {fmt.Sprintf(wikidataAwardsQuery, movie.TMDBID, movie.IMDBID)},
+		"format": {"json"},
+	}
+	var result struct {
+		Results struct {
+			Bindings []map[string]struct {
+				Value string `json:"value"`
+			} `json:"bindings"`
+		} `json:"results"`
+	}
+	if err := newWikidataProvider(a).getJSON("https://query.wikidata.org/sparql?"+params.Encode(), &result); err != nil {
+		return nil, err
+	}
+
+	won := make(map[string]bool)
+	for _, row := range result.Results.Bindings {
+		name := row["awardLabel"].Value
+		if !majorAward(name) {
+			continue
+		}
+		won[name] = won[name] || row["kind"].Value == "win"
+	}
+
+	awards := make([]Award, 0, len(won))
+	for name, w := range won {
+		awards = append(awards, Award{Name: name, Won: w})
+	}
+	sort.Slice(awards, func(i, j int) bool {
+		if awards[i].Won != awards[j].Won {
+			return awards[i].Won
+		}
+		return awards[i].Name < awards[j].Name
+	})
+	return awards, nil
+}
+
+// majorAward reports whether an award name is one of majorAwards
+func majorAward(name string) bool {
+	for _, prefix := range majorAwards {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// setAwards stores a film's awards along with its win and nomination counts
+func setAwards(movie *Movie, awards []Award) {
+	movie.Awards = awards
+	movie.AwardWins, movie.AwardNominations = 0, 0
+	for _, award := range awards {
+		if award.Won {
+			movie.AwardWins++
+		} else {
+			movie.AwardNominations++
+		}
+	}
+}
+
+// annotateAwards looks up the awards of every hydrated movie
+func (a *App) annotateAwards(movies []Movie) {
+	forEachIndex(len(movies), enrichWorkers, func(index int) {
+		if movies[index].TMDBID == 0 && movies[index].IMDBID == "" {
+			return
+		}
+		awards, err := a.GetAwards(movies[index])
+		if err != nil {
+			return
+		}
+		setAwards(&movies[index], awards)
+	})
+}
+
+// filterAwardWinners keeps only movies that won a major award
+func filterAwardWinners(movies []Movie) []Movie {
+	filtered := movies[:0]
+	for _, movie := range movies {
+		if movie.AwardWins > 0 {
+			filtered = append(filtered, movie)
+		}
+	}
+	return filtered
+}
+
+// sortAwards orders movies by major wins, then nominations
+func sortAwards(movies []Movie) {
+	sort.SliceStable(movies, func(i, j int) bool {
+		if movies[i].AwardWins != movies[j].AwardWins {
+			return movies[i].AwardWins > movies[j].AwardWins
+		}
+		return movies[i].AwardNominations > movies[j].AwardNominations
+	})
+}
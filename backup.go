@@ -0,0 +1,152 @@
+package main
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// maxBackupEntry caps the size of a document read from a backup, so a
+// malformed archive can't exhaust memory
+const maxBackupEntry = 64 << 20
+
+// ExportBackup zips every stored document - settings and credentials,
+// aliases, poster overrides, tags, notes and the watchlist, comparison and
+// movie night history - into a file at path, for moving to a new machine
+func (a *App) ExportBackup(path string) error {
+	if path == "" {
+		return fmt.Errorf("no backup path given")
+	}
+	docs, err := a.store.documents()
+	if err != nil {
+		return err
+	}
+
+	tmp := path + ".tmp"
+	file, err := os.Create(tmp)
+	if err != nil {
+		return fmt.Errorf("could not create backup: %v", err)
+	}
+	defer os.Remove(tmp)
+
+	archive := zip.NewWriter(file)
+	for name, data := range docs {
+		w, err := archive.CreateHeader(&zip.FileHeader{
+			Name:     name + ".json",
+			Method:   zip.Deflate,
+			Modified: time.Now(),
+		})
+		if err == nil {
+			_, err = w.Write(data)
+		}
+		if err != nil {
+			file.Close()
+			return fmt.Errorf("could not write %s to backup: %v", name, err)
+		}
+	}
+	if err := archive.Close(); err != nil {
+		file.Close()
+		return fmt.Errorf("could not write backup: %v", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("could not write backup: %v", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("could not write backup: %v", err)
+	}
+	return nil
+}
+
+// ImportBackup replaces the stored data with a backup made by ExportBackup,
+// migrating it if it came from an older release. The backup is checked in
+// full before anything is replaced
+func (a *App) ImportBackup(path string) error {
+	archive, err := zip.OpenReader(path)
+	if err != nil {
+		return fmt.Errorf("could not open backup: %v", err)
+	}
+	defer archive.Close()
+
+	docs := make(map[string][]byte, len(archive.File))
+	for _, f := range archive.File {
+		name, ok := strings.CutSuffix(f.Name, ".json")
+		if !ok || name == "" || strings.ContainsAny(name, `/\`) || name != filepath.Base(name) {
+			return fmt.Errorf("unexpected file '%s' in backup", f.Name)
+		}
+		data, err := readBackupEntry(f)
+		if err != nil {
+			return fmt.Errorf("could not read %s from backup: %v", f.Name, err)
+		}
+		if !json.Valid(data) {
+			return fmt.Errorf("%s in backup is corrupt", f.Name)
+		}
+		docs[name] = data
+	}
+	// Every data directory records its schema version on startup
+	data, ok := docs["schema"]
+	if !ok {
+		return fmt.Errorf("%s is not a Klisse backup", filepath.Base(path))
+	}
+	var state schemaState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return fmt.Errorf("could not parse backup schema version: %v", err)
+	}
+	if state.Version > latestSchema() {
+		return fmt.Errorf("backup is from a newer release (schema version %d), update Klisse to restore it", state.Version)
+	}
+
+	if _, _, err := a.store.removeMatching("*", time.Time{}); err != nil {
+		return err
+	}
+	for name, data := range docs {
+		if err := a.store.write(name, data); err != nil {
+			return err
+		}
+	}
+	if err := migrateStore(a.store); err != nil {
+		return err
+	}
+
+	a.applySettings()
+	a.resetState()
+	return nil
+}
+
+// readBackupEntry reads one file of a backup archive
+func readBackupEntry(f *zip.File) ([]byte, error) {
+	r, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	data, err := io.ReadAll(io.LimitReader(r, maxBackupEntry+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxBackupEntry {
+		return nil, fmt.Errorf("file too large")
+	}
+	return data, nil
+}
+
+// documents returns the encoded contents of every stored document by name
+func (s *dataStore) documents() (map[string][]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	paths, _ := filepath.Glob(filepath.Join(s.dir, "*.json"))
+	docs := make(map[string][]byte, len(paths))
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("could not read %s: %v", filepath.Base(path), err)
+		}
+		docs[strings.TrimSuffix(filepath.Base(path), ".json")] = data
+	}
+	return docs, nil
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"runtime"
+	"time"
+
+	"github.com/gocolly/colly/v2"
+)
+
+// browserTimeout bounds how long the browser may take to render a page
+const browserTimeout = 45 * time.Second
+
+// browserNames are the Chromium-based browsers looked for on the PATH
+var browserNames = []string{"google-chrome", "google-chrome-stable", "chromium", "chromium-browser", "chrome", "microsoft-edge", "msedge"}
+
+// browserPaths are where Chromium-based browsers install themselves outside
+// the PATH, by GOOS
+var browserPaths = map[string][]string{
+	"darwin": {
+		"/Applications/Google Chrome.app/Contents/MacOS/Google Chrome",
+		"/Applications/Chromium.app/Contents/MacOS/Chromium",
+		"/Applications/Microsoft Edge.app/Contents/MacOS/Microsoft Edge",
+	},
+	"windows": {
+		`C:\Program Files\Google\Chrome\Application\chrome.exe`,
+		`C:\Program Files (x86)\Google\Chrome\Application\chrome.exe`,
+		`C:\Program Files (x86)\Microsoft\Edge\Application\msedge.exe`,
+	},
+}
+
+// SetBrowserFallback turns rendering Letterboxd pages in a headless browser
+// on or off, used when a static scrape finds suspiciously few films because
+// the page loads them with JavaScript. It needs Chrome, Chromium or Edge.
+func (a *App) SetBrowserFallback(enabled bool) error {
+	if enabled && findBrowser() == "" {
+		return fmt.Errorf("no Chrome, Chromium or Edge installation found")
+	}
+	settings := a.loadSettings()
+	settings.BrowserFallback = enabled
+	return a.saveSettings(settings)
+}
+
+// findBrowser returns the path of an installed Chromium-based browser, or
+// "" if there is none
+func findBrowser() string {
+	for _, name := range browserNames {
+		if path, err := exec.LookPath(name); err == nil {
+			return path
+		}
+	}
+	for _, path := range browserPaths[runtime.GOOS] {
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+	return ""
+}
+
+// suspiciouslyEmpty reports whether a scrape found so few films that the
+// page probably loads them with JavaScript: none at all, or under half the
+// count the profile shows
+func suspiciouslyEmpty(found int, expected int) bool {
+	return found == 0 || found < expected/2
+}
+
+// newBrowserCollector creates a colly collector that renders pages in a
+// headless browser, or returns nil if the fallback is off or no browser is
+// installed
+func (a *App) newBrowserCollector() *colly.Collector {
+	if !a.loadSettings().BrowserFallback {
+		return nil
+	}
+	path := findBrowser()
+	if path == "" {
+		return nil
+	}
+	c := a.newCollector()
+	c.WithTransport(&browserTransport{path: path, metrics: a.metrics})
+	return c
+}
+
+// browserTransport is an http.RoundTripper that loads GET requests in a
+// headless browser and responds with the DOM once its scripts have run
+type browserTransport struct {
+	path    string
+	metrics *metrics
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *browserTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet {
+		return nil, fmt.Errorf("the browser can only load pages, not %s requests", req.Method)
+	}
+
+	ctx, cancel := context.WithTimeout(req.Context(), browserTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, t.path,
+		"--headless=new",
+		"--disable-gpu",
+		"--disable-extensions",
+		"--mute-audio",
+		// Lets lazy loading and pagination scripts run before the DOM is dumped
+		"--virtual-time-budget=10000",
+		"--user-agent="+req.UserAgent(),
+		"--dump-dom",
+		req.URL.String(),
+	)
+	start := time.Now()
+	dom, err := cmd.Output()
+	t.metrics.since("browser_render", start)
+	if err != nil {
+		t.metrics.countError("browser")
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("browser timed out rendering %s", req.URL)
+		}
+		return nil, fmt.Errorf("browser failed to render %s: %v", req.URL, err)
+	}
+
+	return &http.Response{
+		Status:        "200 OK",
+		StatusCode:    http.StatusOK,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": {"text/html; charset=utf-8"}},
+		Body:          io.NopCloser(bytes.NewReader(dom)),
+		ContentLength: int64(len(dom)),
+		Request:       req,
+	}, nil
+}
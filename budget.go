@@ -0,0 +1,199 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// justWatchGraphQL is JustWatch's public GraphQL endpoint, the source of the
+// rent and buy prices TMDB's watch providers leave out
+const justWatchGraphQL = "https://apis.justwatch.com/graphql"
+
+// justWatchOffersQuery searches a film and returns its web offers
+const justWatchOffersQuery = `query GetOffers($country: Country!, $language: Language!, $query: String!) {
+  popularTitles(country: $country, first: 5, filter: {searchQuery: $query, objectTypes: [MOVIE]}) {
+    edges {
+      node {
+        content(country: $country, language: $language) {
+          originalReleaseYear
+          externalIds { tmdbId }
+        }
+        offers(country: $country, platform: WEB) {
+          monetizationType
+          presentationType
+          retailPriceValue
+          currency
+          standardWebURL
+          subtitleLanguages
+          audioLanguages
+          package { clearName }
+        }
+      }
+    }
+  }
+}`
+
+// Price is what renting or buying a film costs on a provider
+type Price struct {
+	Provider string  `json:"provider"`
+	Type     string  `json:"type"` // "rent" or "buy"
+	Amount   float64 `json:"amount"`
+	Currency string  `json:"currency"`
+	Quality  string  `json:"quality"` // e.g. "HD" or "4K"
+	URL      string  `json:"url"`
+}
+
+// justWatchOffers represents the GraphQL reply to justWatchOffersQuery
+type justWatchOffers struct {
+	Data struct {
+		PopularTitles struct {
+			Edges []struct {
+				Node struct {
+					Content struct {
+						OriginalReleaseYear int `json:"originalReleaseYear"`
+						ExternalIDs         struct {
+							TMDBID string `json:"tmdbId"`
+						} `json:"externalIds"`
+					} `json:"content"`
+					Offers []justWatchOffer `json:"offers"`
+				} `json:"node"`
+			} `json:"edges"`
+		} `json:"popularTitles"`
+	} `json:"data"`
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+// justWatchOffer is one way of watching a title on a JustWatch package
+type justWatchOffer struct {
+	MonetizationType  string   `json:"monetizationType"`
+	PresentationType  string   `json:"presentationType"`
+	RetailPriceValue  float64  `json:"retailPriceValue"`
+	Currency          string   `json:"currency"`
+	StandardWebURL    string   `json:"standardWebURL"`
+	SubtitleLanguages []string `json:"subtitleLanguages"`
+	AudioLanguages    []string `json:"audioLanguages"`
+	Package           struct {
+		ClearName string `json:"clearName"`
+	} `json:"package"`
+}
+
+// GetRentalPrices returns the rent and buy prices of a film in the
+// configured region, cheapest first
+func (a *App) GetRentalPrices(movie Movie) ([]Price, error) {
+	if movie.TMDBID == 0 {
+		return nil, fmt.Errorf("no TMDB match for '%s' to find prices with", movie.Title)
+	}
+	offers, err := a.justWatchOffers(movie)
+	if err != nil {
+		return nil, err
+	}
+
+	var prices []Price
+	for _, offer := range offers {
+		kind := strings.ToLower(offer.MonetizationType)
+		if (kind != "rent" && kind != "buy") || offer.RetailPriceValue <= 0 {
+			continue
+		}
+		prices = append(prices, Price{
+			Provider: offer.Package.ClearName,
+			Type:     kind,
+			Amount:   offer.RetailPriceValue,
+			Currency: offer.Currency,
+			Quality:  offer.PresentationType,
+			URL:      offer.StandardWebURL,
+		})
+	}
+
+	sort.SliceStable(prices, func(i, j int) bool {
+		if prices[i].Type != prices[j].Type {
+			return prices[i].Type == "rent"
+		}
+		return prices[i].Amount < prices[j].Amount
+	})
+	return prices, nil
+}
+
+// justWatchOffers returns a film's JustWatch web offers in the configured
+// region; it needs a TMDB match to pick the right search hit
+func (a *App) justWatchOffers(movie Movie) ([]justWatchOffer, error) {
+	title, _ := filmYear(movie.Title, movie.URL)
+
+	body, err := json.Marshal(map[string]interface{}{
+		"query": justWatchOffersQuery,
+		"variables": map[string]string{
+			"country":  a.region(),
+			"language": a.language(),
+			"query":    title,
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not encode offers query: %v", err)
+	}
+
+	client := &http.Client{Transport: sharedTransport, Timeout: 10 * time.Second}
+	start := time.Now()
+	resp, err := client.Post(justWatchGraphQL, "application/json", bytes.NewReader(body))
+	a.metrics.since("justwatch_request", start)
+	if err != nil {
+		a.metrics.countError("justwatch")
+		return nil, fmt.Errorf("failed to get offers: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		a.metrics.countError("justwatch")
+		return nil, fmt.Errorf("JustWatch error: status code %d", resp.StatusCode)
+	}
+
+	var reply justWatchOffers
+	if err := json.NewDecoder(resp.Body).Decode(&reply); err != nil {
+		return nil, fmt.Errorf("failed to parse offers: %v", err)
+	}
+	if len(reply.Errors) > 0 {
+		return nil, fmt.Errorf("JustWatch error: %s", reply.Errors[0].Message)
+	}
+
+	// Only the search hit for the same TMDB film counts, so remakes sharing
+	// a title don't lend their offers
+	tmdbID := strconv.Itoa(movie.TMDBID)
+	for _, edge := range reply.Data.PopularTitles.Edges {
+		if edge.Node.Content.ExternalIDs.TMDBID == tmdbID {
+			return edge.Node.Offers, nil
+		}
+	}
+	return nil, nil
+}
+
+// subscriptionIncluded reports whether a film streams at no extra cost,
+// on a subscription or free with ads
+func subscriptionIncluded(providers WatchProviders) bool {
+	return len(providers.Stream) > 0 || len(providers.Free) > 0
+}
+
+// filterSubscription keeps only the movies included with a subscription in
+// the configured region; movies without a TMDB match are dropped
+func (a *App) filterSubscription(movies []Movie) []Movie {
+	included := make([]bool, len(movies))
+	forEachIndex(len(movies), enrichWorkers, func(index int) {
+		if movies[index].TMDBID == 0 {
+			return
+		}
+		providers, err := a.GetWatchProviders(movies[index].TMDBID)
+		included[index] = err == nil && subscriptionIncluded(providers)
+	})
+
+	var filtered []Movie
+	for i, movie := range movies {
+		if included[i] {
+			filtered = append(filtered, movie)
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// BulkResult reports which movies a batched operation applied to; Failed
+// maps the keys that were rejected to the reason
+type BulkResult struct {
+	Succeeded []string          `json:"succeeded"`
+	Failed    map[string]string `json:"failed"`
+}
+
+// newBulkResult splits keys into valid ones and failures for empty keys
+func newBulkResult(keys []string) (BulkResult, []string) {
+	result := BulkResult{Failed: make(map[string]string)}
+	var valid []string
+	for _, key := range keys {
+		if strings.TrimSpace(key) == "" {
+			result.Failed[key] = "no movie key provided"
+			continue
+		}
+		valid = append(valid, key)
+	}
+	return result, valid
+}
+
+// BulkExclude vetoes several movies in a single call, titling them from
+// the last comparison's watchlists where possible
+func (a *App) BulkExclude(keys []string) (BulkResult, error) {
+	result, valid := newBulkResult(keys)
+
+	a.exclusionsMu.Lock()
+	defer a.exclusionsMu.Unlock()
+
+	exclusions, err := a.loadExclusions()
+	if err != nil {
+		return result, err
+	}
+
+	titles := make(map[string]string)
+	a.mu.Lock()
+	for _, watchlist := range a.watchlists {
+		for key, film := range watchlist {
+			titles[key] = film.Title
+		}
+	}
+	a.mu.Unlock()
+
+	for _, key := range valid {
+		if _, exists := exclusions[key]; !exists {
+			title := titles[key]
+			if title == "" {
+				title = key
+			}
+			exclusions[key] = Exclusion{Key: key, Title: title, ExcludedAt: time.Now()}
+		}
+		result.Succeeded = append(result.Succeeded, key)
+	}
+
+	if err := a.store.save("exclusions", exclusions); err != nil {
+		return BulkResult{Failed: result.Failed}, err
+	}
+	return result, nil
+}
+
+// BulkPin pins or unpins several movies in a single call
+func (a *App) BulkPin(keys []string, pinned bool) (BulkResult, error) {
+	result, valid := newBulkResult(keys)
+
+	a.pinsMu.Lock()
+	defer a.pinsMu.Unlock()
+
+	pins, err := a.loadPins()
+	if err != nil {
+		return result, err
+	}
+	for _, key := range valid {
+		if !pinned {
+			delete(pins, key)
+		} else if _, exists := pins[key]; !exists {
+			pins[key] = time.Now()
+		}
+		result.Succeeded = append(result.Succeeded, key)
+	}
+
+	if err := a.store.save("pins", pins); err != nil {
+		return BulkResult{Failed: result.Failed}, err
+	}
+	return result, nil
+}
+
+// BulkTag applies a tag to several movies in a single call
+func (a *App) BulkTag(keys []string, tag string) (BulkResult, error) {
+	result, valid := newBulkResult(keys)
+	if strings.TrimSpace(tag) == "" {
+		return result, fmt.Errorf("no tag provided")
+	}
+	if err := a.TagMovies(valid, tag); err != nil {
+		return result, err
+	}
+	result.Succeeded = valid
+	return result, nil
+}
@@ -0,0 +1,226 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// cacheStatusHeader marks responses served from the cache: "hit" after a
+// 304, "stale" when the network failed and the last copy was used instead
+const cacheStatusHeader = "X-Klisse-Cache"
+
+// cachedResponse is a scraped page stored on disk together with its validators
+type cachedResponse struct {
+	URL          string      `json:"url"`
+	ETag         string      `json:"etag"`
+	LastModified string      `json:"last_modified"`
+	Header       http.Header `json:"header"`
+	Body         []byte      `json:"body"`
+	StoredAt     time.Time   `json:"stored_at"`
+}
+
+// cachingTransport is an http.RoundTripper that keeps GET responses on disk and
+// revalidates them with If-None-Match / If-Modified-Since, so re-scraping an
+// unchanged page costs a single 304 instead of a full download
+type cachingTransport struct {
+	dir  string
+	next http.RoundTripper
+	// key derives the cache key of a request URL; nil uses the whole URL
+	key func(*url.URL) string
+
+	mu     sync.Mutex
+	hits   int
+	misses int
+	stale  int
+	// maxBytes caps the cache size, evicting least recently used entries;
+	// zero disables the cap
+	maxBytes int64
+	// size is the approximate cache size, -1 until first measured
+	size int64
+}
+
+// newCachingTransport creates a caching transport storing entries in dir
+func newCachingTransport(dir string, next http.RoundTripper) *cachingTransport {
+	if next == nil {
+		next = sharedTransport
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		log.Printf("Could not create scrape cache directory %s: %v", dir, err)
+	}
+	return &cachingTransport{dir: dir, next: next, size: -1}
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *cachingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet {
+		return t.next.RoundTrip(req)
+	}
+
+	key := req.URL.String()
+	if t.key != nil {
+		key = t.key(req.URL)
+	}
+	cached := t.load(key)
+
+	outReq := req
+	if cached != nil {
+		outReq = req.Clone(req.Context())
+		if cached.ETag != "" {
+			outReq.Header.Set("If-None-Match", cached.ETag)
+		}
+		if cached.LastModified != "" {
+			outReq.Header.Set("If-Modified-Since", cached.LastModified)
+		}
+	}
+
+	resp, err := t.next.RoundTrip(outReq)
+	if err != nil {
+		// Fall back to the last copy when the network drops, flagging it as stale
+		if cached != nil && req.Context().Err() == nil {
+			t.mu.Lock()
+			t.stale++
+			t.mu.Unlock()
+			stale := cached.response(req)
+			stale.Header.Set(cacheStatusHeader, "stale")
+			return stale, nil
+		}
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusNotModified && cached != nil {
+		resp.Body.Close()
+		t.record(true)
+		// A 304 carries the current values of headers such as rate limits
+		for name, values := range resp.Header {
+			if cached.Header != nil && name != "Content-Length" {
+				cached.Header[name] = values
+			}
+		}
+		cached.StoredAt = time.Now()
+		t.store(key, cached)
+		return cached.response(req), nil
+	}
+	t.record(false)
+
+	etag := resp.Header.Get("ETag")
+	lastModified := resp.Header.Get("Last-Modified")
+	if resp.StatusCode != http.StatusOK || (etag == "" && lastModified == "") {
+		return resp, nil
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	t.store(key, &cachedResponse{
+		URL:          key,
+		ETag:         etag,
+		LastModified: lastModified,
+		Header:       resp.Header.Clone(),
+		Body:         body,
+		StoredAt:     time.Now(),
+	})
+
+	return resp, nil
+}
+
+// response rebuilds an http.Response from a cache entry
+func (c *cachedResponse) response(req *http.Request) *http.Response {
+	header := c.Header.Clone()
+	if header == nil {
+		header = make(http.Header)
+	}
+	header.Set(cacheStatusHeader, "hit")
+	return &http.Response{
+		Status:        "200 OK",
+		StatusCode:    http.StatusOK,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(c.Body)),
+		ContentLength: int64(len(c.Body)),
+		Request:       req,
+	}
+}
+
+// path returns the cache file used for a URL
+func (t *cachingTransport) path(key string) string {
+	sum := sha1.Sum([]byte(key))
+	return filepath.Join(t.folder(), hex.EncodeToString(sum[:])+".json")
+}
+
+// folder returns the cache directory
+func (t *cachingTransport) folder() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.dir
+}
+
+// moveTo points the cache at another directory
+func (t *cachingTransport) moveTo(dir string) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		log.Printf("Could not create scrape cache directory %s: %v", dir, err)
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.dir = dir
+	t.size = -1
+}
+
+// load reads a cache entry, returning nil if it is missing or unreadable
+func (t *cachingTransport) load(key string) *cachedResponse {
+	data, err := os.ReadFile(t.path(key))
+	if err != nil {
+		return nil
+	}
+	var entry cachedResponse
+	if err := json.Unmarshal(data, &entry); err != nil || entry.URL != key {
+		return nil
+	}
+	return &entry
+}
+
+// store writes a cache entry to disk
+func (t *cachingTransport) store(key string, entry *cachedResponse) {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+	if err := os.WriteFile(t.path(key), data, 0o644); err != nil {
+		log.Printf("Could not write scrape cache entry for %s: %v", key, err)
+		return
+	}
+	t.grow(int64(len(data)))
+}
+
+// record counts a cache hit (304 revalidation) or miss
+func (t *cachingTransport) record(hit bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if hit {
+		t.hits++
+	} else {
+		t.misses++
+	}
+}
+
+// staleServed returns how many stale copies have been served so far
+func (t *cachingTransport) staleServed() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.stale
+}
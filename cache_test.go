@@ -0,0 +1,103 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// roundTripFunc adapts a function to an http.RoundTripper
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestCachingTransportRevalidates(t *testing.T) {
+	tests := []struct {
+		name string
+		// header validates the first response
+		header http.Header
+		// wantIfNoneMatch and wantIfModifiedSince are the validators sent
+		// with the second request
+		wantIfNoneMatch     string
+		wantIfModifiedSince string
+	}{
+		{name: "etag", header: http.Header{"Etag": {`"v1"`}}, wantIfNoneMatch: `"v1"`},
+		{name: "last modified", header: http.Header{"Last-Modified": {"Mon, 02 Jan 2006 15:04:05 GMT"}}, wantIfModifiedSince: "Mon, 02 Jan 2006 15:04:05 GMT"},
+		{name: "no validators", header: http.Header{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var sent []*http.Request
+			next := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+				sent = append(sent, req)
+				if len(sent) > 1 && (req.Header.Get("If-None-Match") != "" || req.Header.Get("If-Modified-Since") != "") {
+					return &http.Response{StatusCode: http.StatusNotModified, Header: http.Header{}, Body: http.NoBody}, nil
+				}
+				return &http.Response{StatusCode: http.StatusOK, Header: tt.header.Clone(), Body: io.NopCloser(strings.NewReader("watchlist"))}, nil
+			})
+			transport := newCachingTransport(t.TempDir(), next)
+
+			for i := 0; i < 2; i++ {
+				req, _ := http.NewRequest(http.MethodGet, "https://letterboxd.com/sam/watchlist/", nil)
+				resp, err := transport.RoundTrip(req)
+				if err != nil {
+					t.Fatal(err)
+				}
+				body, _ := io.ReadAll(resp.Body)
+				resp.Body.Close()
+				if resp.StatusCode != http.StatusOK || string(body) != "watchlist" {
+					t.Errorf("request %d: got %d %q, want 200 \"watchlist\"", i+1, resp.StatusCode, body)
+				}
+			}
+
+			if got := sent[1].Header.Get("If-None-Match"); got != tt.wantIfNoneMatch {
+				t.Errorf("If-None-Match = %q, want %q", got, tt.wantIfNoneMatch)
+			}
+			if got := sent[1].Header.Get("If-Modified-Since"); got != tt.wantIfModifiedSince {
+				t.Errorf("If-Modified-Since = %q, want %q", got, tt.wantIfModifiedSince)
+			}
+		})
+	}
+}
+
+func TestCachingTransportSkipsUncacheable(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		status int
+	}{
+		{name: "post", method: http.MethodPost, status: http.StatusOK},
+		{name: "not found", method: http.MethodGet, status: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			next := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+				calls++
+				if req.Header.Get("If-None-Match") != "" {
+					t.Error("sent validators for an uncached response")
+				}
+				return &http.Response{StatusCode: tt.status, Header: http.Header{"Etag": {`"v1"`}}, Body: http.NoBody}, nil
+			})
+			transport := newCachingTransport(t.TempDir(), next)
+
+			for i := 0; i < 2; i++ {
+				req, _ := http.NewRequest(tt.method, "https://letterboxd.com/sam/watchlist/", nil)
+				resp, err := transport.RoundTrip(req)
+				if err != nil {
+					t.Fatal(err)
+				}
+				resp.Body.Close()
+				if resp.StatusCode != tt.status {
+					t.Errorf("request %d: status %d, want %d", i+1, resp.StatusCode, tt.status)
+				}
+			}
+			if calls != 2 {
+				t.Errorf("%d requests reached the network, want 2", calls)
+			}
+		})
+	}
+}
@@ -0,0 +1,216 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// ssdpAddress is the multicast group devices answer discovery requests on
+	ssdpAddress = "239.255.255.250:1900"
+	// dialSearchTarget is the SSDP service type of DIAL devices
+	dialSearchTarget = "urn:dial-multiscreen-org:service:dial:1"
+	// castDiscoveryTimeout is how long discovery waits for devices to answer
+	castDiscoveryTimeout = 3 * time.Second
+)
+
+// CastDevice is a TV or streaming stick on the local network that can
+// launch apps over DIAL, such as Chromecasts and most smart TVs
+type CastDevice struct {
+	Name         string `json:"name"`
+	Manufacturer string `json:"manufacturer"`
+	Model        string `json:"model"`
+	// ApplicationURL is the DIAL REST endpoint apps are launched under
+	ApplicationURL string `json:"application_url"`
+}
+
+// dialDescription is the UPnP device description a DIAL device serves
+type dialDescription struct {
+	Device struct {
+		FriendlyName string `xml:"friendlyName"`
+		Manufacturer string `xml:"manufacturer"`
+		ModelName    string `xml:"modelName"`
+	} `xml:"device"`
+}
+
+// tmdbVideos represents a movie's TMDB videos response
+type tmdbVideos struct {
+	Results []struct {
+		Key      string `json:"key"`
+		Site     string `json:"site"`
+		Type     string `json:"type"`
+		Official bool   `json:"official"`
+	} `json:"results"`
+}
+
+// DiscoverCastDevices searches the local network for DIAL devices
+func (a *App) DiscoverCastDevices() ([]CastDevice, error) {
+	conn, err := net.ListenPacket("udp4", ":0")
+	if err != nil {
+		return nil, fmt.Errorf("could not open discovery socket: %v", err)
+	}
+	defer conn.Close()
+
+	group, err := net.ResolveUDPAddr("udp4", ssdpAddress)
+	if err != nil {
+		return nil, err
+	}
+	search := "M-SEARCH * HTTP/1.1\r\n" +
+		"HOST: " + ssdpAddress + "\r\n" +
+		"MAN: \"ssdp:discover\"\r\n" +
+		"MX: 2\r\n" +
+		"ST: " + dialSearchTarget + "\r\n\r\n"
+	if _, err := conn.WriteTo([]byte(search), group); err != nil {
+		return nil, fmt.Errorf("could not send discovery request: %v", err)
+	}
+
+	// Collect the description URLs of everything that answers in time
+	locations := make(map[string]bool)
+	conn.SetReadDeadline(time.Now().Add(castDiscoveryTimeout))
+	buf := make([]byte, 2048)
+	for {
+		n, _, err := conn.ReadFrom(buf)
+		if err != nil {
+			break
+		}
+		resp, err := http.ReadResponse(bufio.NewReader(bytes.NewReader(buf[:n])), nil)
+		if err != nil {
+			continue
+		}
+		resp.Body.Close()
+		if location := resp.Header.Get("Location"); location != "" {
+			locations[location] = true
+		}
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	var devices []CastDevice
+	client := &http.Client{Timeout: 5 * time.Second}
+	for location := range locations {
+		wg.Add(1)
+		go func(location string) {
+			defer wg.Done()
+			device, err := describeDIALDevice(client, location)
+			if err != nil {
+				return
+			}
+			mu.Lock()
+			devices = append(devices, device)
+			mu.Unlock()
+		}(location)
+	}
+	wg.Wait()
+
+	return devices, nil
+}
+
+// describeDIALDevice fetches a device description, whose Application-URL
+// header marks it as a DIAL server
+func describeDIALDevice(client *http.Client, location string) (CastDevice, error) {
+	resp, err := client.Get(location)
+	if err != nil {
+		return CastDevice{}, err
+	}
+	defer resp.Body.Close()
+
+	appURL := resp.Header.Get("Application-URL")
+	if appURL == "" {
+		return CastDevice{}, fmt.Errorf("'%s' is not a DIAL device", location)
+	}
+	var description dialDescription
+	if err := xml.NewDecoder(resp.Body).Decode(&description); err != nil {
+		return CastDevice{}, fmt.Errorf("could not parse device description: %v", err)
+	}
+	return CastDevice{
+		Name:           description.Device.FriendlyName,
+		Manufacturer:   description.Device.Manufacturer,
+		Model:          description.Device.ModelName,
+		ApplicationURL: strings.TrimSuffix(appURL, "/") + "/",
+	}, nil
+}
+
+// CastTrailer launches a film's YouTube trailer on a DIAL device, so the
+// group can watch it on the TV while choosing
+func (a *App) CastTrailer(movie Movie, device CastDevice) error {
+	appURL, err := url.Parse(device.ApplicationURL)
+	if err != nil || appURL.Scheme != "http" || appURL.Host == "" {
+		return fmt.Errorf("invalid cast device '%s'", device.Name)
+	}
+	if movie.TMDBID == 0 {
+		return fmt.Errorf("no TMDB match for '%s' to find a trailer with", movie.Title)
+	}
+
+	key, err := a.trailerKey(movie.TMDBID)
+	if err != nil {
+		return err
+	}
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Post(strings.TrimSuffix(device.ApplicationURL, "/")+"/YouTube",
+		"text/plain; charset=utf-8", strings.NewReader("v="+url.QueryEscape(key)))
+	if err != nil {
+		return fmt.Errorf("could not reach '%s': %v", device.Name, err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("'%s' could not launch YouTube: status code %d", device.Name, resp.StatusCode)
+	}
+	return nil
+}
+
+// trailerKey returns the YouTube video ID of a film's trailer, preferring
+// official trailers over teasers and other clips
+func (a *App) trailerKey(movieID int) (string, error) {
+	apiKey := a.getTMDBAPIKey()
+	if apiKey == "" || len(apiKey) < 10 {
+		return "", fmt.Errorf("TMDB API key not configured")
+	}
+
+	a.tmdbLimiter.wait()
+	resp, err := a.tmdbGet(fmt.Sprintf("https://api.themoviedb.org/3/movie/%d/videos?api_key=%s", movieID, apiKey))
+	if err != nil {
+		return "", fmt.Errorf("failed to get videos: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 {
+		return "", fmt.Errorf("videos API error: %v", parseTMDBError(resp))
+	}
+
+	var videos tmdbVideos
+	if err := json.NewDecoder(resp.Body).Decode(&videos); err != nil {
+		return "", fmt.Errorf("failed to parse videos: %v", err)
+	}
+
+	best, bestRank := "", 0
+	for _, video := range videos.Results {
+		if video.Site != "YouTube" || video.Key == "" {
+			continue
+		}
+		rank := 1
+		if video.Type == "Trailer" {
+			rank += 2
+		} else if video.Type == "Teaser" {
+			rank++
+		}
+		if video.Official {
+			rank++
+		}
+		if rank > bestRank {
+			best, bestRank = video.Key, rank
+		}
+	}
+	if best == "" {
+		return "", fmt.Errorf("no trailer found")
+	}
+	return best, nil
+}
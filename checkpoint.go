@@ -0,0 +1,164 @@
+package main
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// checkpointMaxAge is how long an interrupted comparison can be resumed
+	checkpointMaxAge = 6 * time.Hour
+	// checkpointSaveEvery is how many hydrated movies are recorded between
+	// checkpoint saves, since each save rewrites the whole checkpoint
+	checkpointSaveEvery = 25
+)
+
+// checkpoint is the intermediate state of a comparison, saved as it
+// progresses so an interrupted run can pick up where it stopped
+type checkpoint struct {
+	mu   sync.Mutex
+	name string
+	// unsaved counts the movies recorded since the checkpoint was last saved
+	unsaved    int
+	Group      []string                             `json:"group"`
+	StartedAt  time.Time                            `json:"started_at"`
+	Watchlists map[string]map[string]WatchlistEntry `json:"watchlists"`
+	Profiles   map[string]userProfile               `json:"profiles"`
+	Enriched   map[string]Movie                     `json:"enriched"`
+}
+
+// resumeCheckpoint loads the checkpoint of an interrupted comparison of the
+// same group, or starts a new one
+func (a *App) resumeCheckpoint(usernames []string) *checkpoint {
+	group, key := groupKey(usernames)
+	cp := &checkpoint{
+		name:       "checkpoint-" + key,
+		Group:      group,
+		StartedAt:  time.Now(),
+		Watchlists: make(map[string]map[string]WatchlistEntry),
+		Profiles:   make(map[string]userProfile),
+		Enriched:   make(map[string]Movie),
+	}
+
+	var saved checkpoint
+	if err := a.store.load(cp.name, &saved); err != nil {
+		log.Printf("Could not load checkpoint: %v", err)
+		return cp
+	}
+	if saved.StartedAt.IsZero() || time.Since(saved.StartedAt) > checkpointMaxAge {
+		return cp
+	}
+
+	log.Printf("Resuming comparison from checkpoint: %d watchlists, %d enriched movies", len(saved.Watchlists), len(saved.Enriched))
+	cp.StartedAt = saved.StartedAt
+	if saved.Watchlists != nil {
+		cp.Watchlists = saved.Watchlists
+	}
+	if saved.Profiles != nil {
+		cp.Profiles = saved.Profiles
+	}
+	if saved.Enriched != nil {
+		cp.Enriched = saved.Enriched
+	}
+	return cp
+}
+
+// watchlist returns a user's watchlist and profile if they were already scraped
+func (cp *checkpoint) watchlist(username string) (userProfile, map[string]WatchlistEntry, bool) {
+	if cp == nil {
+		return userProfile{}, nil, false
+	}
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+	movies, ok := cp.Watchlists[username]
+	return cp.Profiles[username], movies, ok
+}
+
+// enriched returns a movie whose details were already fetched
+func (cp *checkpoint) enriched(key string) (Movie, bool) {
+	if cp == nil {
+		return Movie{}, false
+	}
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+	movie, ok := cp.Enriched[key]
+	return movie, ok
+}
+
+// groupKey returns a group's sorted, lower-cased usernames and a short hash
+// identifying the group regardless of the order names were entered in
+func groupKey(usernames []string) ([]string, string) {
+	group := make([]string, len(usernames))
+	for i, username := range usernames {
+		group[i] = strings.ToLower(username)
+	}
+	sort.Strings(group)
+	sum := sha1.Sum([]byte(strings.Join(group, ",")))
+	return group, hex.EncodeToString(sum[:6])
+}
+
+// recordWatchlist saves a freshly scraped watchlist and profile to the checkpoint
+func (a *App) recordWatchlist(cp *checkpoint, username string, profile userProfile, movies map[string]WatchlistEntry) {
+	if cp == nil {
+		return
+	}
+	cp.mu.Lock()
+	cp.Watchlists[username] = movies
+	cp.Profiles[username] = profile
+	cp.mu.Unlock()
+	a.saveCheckpoint(cp)
+}
+
+// recordEnriched adds a hydrated movie to the checkpoint, saving it every
+// checkpointSaveEvery movies; flushCheckpoint saves the rest
+func (a *App) recordEnriched(cp *checkpoint, movie Movie) {
+	if cp == nil {
+		return
+	}
+	cp.mu.Lock()
+	cp.Enriched[movie.Key] = movie
+	cp.unsaved++
+	due := cp.unsaved >= checkpointSaveEvery
+	cp.mu.Unlock()
+	if due {
+		a.saveCheckpoint(cp)
+	}
+}
+
+// flushCheckpoint saves the movies recorded since the last save, if any
+func (a *App) flushCheckpoint(cp *checkpoint) {
+	if cp == nil {
+		return
+	}
+	cp.mu.Lock()
+	due := cp.unsaved > 0
+	cp.mu.Unlock()
+	if due {
+		a.saveCheckpoint(cp)
+	}
+}
+
+// saveCheckpoint persists the checkpoint
+func (a *App) saveCheckpoint(cp *checkpoint) {
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+	cp.unsaved = 0
+	if err := a.store.save(cp.name, cp); err != nil {
+		log.Printf("Could not save checkpoint: %v", err)
+	}
+}
+
+// finishCheckpoint removes the checkpoint of a comparison that completed
+func (a *App) finishCheckpoint(cp *checkpoint) {
+	if cp == nil {
+		return
+	}
+	if err := a.store.remove(cp.name); err != nil {
+		log.Printf("Could not remove checkpoint: %v", err)
+	}
+}
@@ -0,0 +1,52 @@
+package main
+
+// Classifications of hydrated movies, coarser than entry types
+const (
+	ClassificationFeature     = "feature"
+	ClassificationShort       = "short"
+	ClassificationDocumentary = "documentary"
+	ClassificationUnknown     = EntryUnknown
+)
+
+// shortsNightMaxRuntime is the runtime, in minutes, films in shorts night
+// mode must stay under; looser than shortMaxRuntime so mid-length films
+// still make the cut
+const shortsNightMaxRuntime = 45
+
+// classifyMovie tells features apart from shorts and documentaries by
+// genres, runtime and the movie's entry type, from classifyEntry.
+// Documentaries of any length are documentaries, and short concert films,
+// stand-up specials and TV movies are shorts
+func classifyMovie(entryType string, runtime int, genres []string) string {
+	for _, genre := range genres {
+		if genre == "Documentary" {
+			return ClassificationDocumentary
+		}
+	}
+	switch {
+	case isShort(runtime) || entryType == EntryShort:
+		return ClassificationShort
+	case entryType == EntryUnknown:
+		return ClassificationUnknown
+	default:
+		return ClassificationFeature
+	}
+}
+
+// filterClassifications keeps only hydrated movies of the allowed
+// classifications; movies that couldn't be classified are kept
+func filterClassifications(movies []Movie, allowed []string) []Movie {
+	return filterKinds(movies, allowed, func(movie Movie) string { return movie.Classification })
+}
+
+// filterShortsNight keeps only movies known to run under
+// shortsNightMaxRuntime
+func filterShortsNight(movies []Movie) []Movie {
+	filtered := movies[:0]
+	for _, movie := range movies {
+		if movie.Runtime > 0 && movie.Runtime < shortsNightMaxRuntime {
+			filtered = append(filtered, movie)
+		}
+	}
+	return filtered
+}
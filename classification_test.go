@@ -0,0 +1,74 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestClassifyMovie(t *testing.T) {
+	tests := []struct {
+		name      string
+		entryType string
+		runtime   int
+		genres    []string
+		want      string
+	}{
+		{"feature", EntryFeature, 120, []string{"Drama"}, ClassificationFeature},
+		{"short", EntryShort, 15, []string{"Animation"}, ClassificationShort},
+		{"short at the cut-off", EntryShort, shortMaxRuntime, nil, ClassificationShort},
+		{"just past the cut-off", EntryFeature, shortMaxRuntime + 1, nil, ClassificationFeature},
+		{"documentary feature", EntryFeature, 95, []string{"Documentary"}, ClassificationDocumentary},
+		{"documentary short", EntryShort, 20, []string{"Documentary"}, ClassificationDocumentary},
+		{"short concert film", EntryConcert, 30, []string{"Music"}, ClassificationShort},
+		{"short stand-up special", EntryStandUp, 25, []string{"Comedy"}, ClassificationShort},
+		{"short TV movie", EntryTVMovie, 35, []string{"TV Movie"}, ClassificationShort},
+		{"concert film", EntryConcert, 110, []string{"Music"}, ClassificationFeature},
+		{"TV movie", EntryTVMovie, 90, []string{"TV Movie"}, ClassificationFeature},
+		{"unknown runtime", EntryFeature, 0, nil, ClassificationFeature},
+		{"unmatched", EntryUnknown, 0, nil, ClassificationUnknown},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := classifyMovie(tt.entryType, tt.runtime, tt.genres); got != tt.want {
+				t.Errorf("classifyMovie(%q, %d, %v) = %q, want %q", tt.entryType, tt.runtime, tt.genres, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFilterShortsNight(t *testing.T) {
+	movies := []Movie{
+		{Key: "unknown"},
+		{Key: "short", Runtime: 12},
+		{Key: "at-short-cut-off", Runtime: shortMaxRuntime},
+		{Key: "mid-length", Runtime: 44},
+		{Key: "at-shorts-night-cut-off", Runtime: shortsNightMaxRuntime},
+		{Key: "feature", Runtime: 100},
+	}
+	var keys []string
+	for _, movie := range filterShortsNight(movies) {
+		keys = append(keys, movie.Key)
+	}
+	want := []string{"short", "at-short-cut-off", "mid-length"}
+	if !slices.Equal(keys, want) {
+		t.Errorf("filterShortsNight() kept %v, want %v", keys, want)
+	}
+}
+
+func TestFilterClassifications(t *testing.T) {
+	movies := []Movie{
+		{Key: "feature", Classification: ClassificationFeature},
+		{Key: "short", Classification: ClassificationShort},
+		{Key: "documentary", Classification: ClassificationDocumentary},
+		{Key: "unknown", Classification: ClassificationUnknown},
+		{Key: "bare"},
+	}
+	var keys []string
+	for _, movie := range filterClassifications(movies, []string{ClassificationShort, ClassificationDocumentary}) {
+		keys = append(keys, movie.Key)
+	}
+	want := []string{"short", "documentary", "unknown", "bare"}
+	if !slices.Equal(keys, want) {
+		t.Errorf("filterClassifications() kept %v, want %v", keys, want)
+	}
+}
@@ -0,0 +1,255 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"slices"
+	"sort"
+	"time"
+)
+
+// maxComparisons caps the comparison history
+const maxComparisons = 200
+
+// ComparisonRecord is a past comparison kept in the history
+type ComparisonRecord struct {
+	ID        int             `json:"id"`
+	RunAt     time.Time       `json:"run_at"`
+	Usernames []string        `json:"usernames"`
+	Movies    []ComparedMovie `json:"movies"`
+	// WatchlistSizes maps each participant to the number of films on their
+	// watchlist; records from before it was kept have none
+	WatchlistSizes map[string]int `json:"watchlist_sizes"`
+}
+
+// ComparedMovie is a common movie of a past comparison
+type ComparedMovie struct {
+	Key   string   `json:"key"`
+	Title string   `json:"title"`
+	URL   string   `json:"url"`
+	Users []string `json:"users"`
+	// Genres are only known for movies hydrated during the run
+	Genres []string `json:"genres"`
+}
+
+// comparisonHistory is the stored comparison history, oldest first
+type comparisonHistory struct {
+	NextID  int                `json:"next_id"`
+	Records []ComparisonRecord `json:"records"`
+}
+
+// MergedResult combines past comparisons of overlapping groups, such as a
+// club whose attendance varies week to week
+type MergedResult struct {
+	IDs       []int         `json:"ids"`
+	Usernames []string      `json:"usernames"`
+	Subgroups []Subgroup    `json:"subgroups"`
+	Movies    []MergedMovie `json:"movies"`
+}
+
+// Subgroup is one of the merged comparisons
+type Subgroup struct {
+	ID           int       `json:"id"`
+	RunAt        time.Time `json:"run_at"`
+	Usernames    []string  `json:"usernames"`
+	CommonMovies int       `json:"common_movies"`
+}
+
+// MergedMovie is a movie common to at least one merged subgroup
+type MergedMovie struct {
+	Key   string `json:"key"`
+	Title string `json:"title"`
+	URL   string `json:"url"`
+	// Users are everyone who listed the film in any subgroup
+	Users []string `json:"users"`
+	// Subgroups are the IDs of the comparisons the film was common in
+	Subgroups []int `json:"subgroups"`
+}
+
+// recordComparison adds a finished comparison to the history and returns
+// its ID
+func (a *App) recordComparison(result ComparisonResultV2) (int, error) {
+	a.comparisonsMu.Lock()
+	defer a.comparisonsMu.Unlock()
+
+	history, err := a.loadComparisons()
+	if err != nil {
+		return 0, err
+	}
+	history.NextID++
+	record := ComparisonRecord{
+		ID:             history.NextID,
+		RunAt:          result.GeneratedAt,
+		Usernames:      result.Usernames,
+		WatchlistSizes: result.Stats.WatchlistSizes,
+	}
+	for _, movie := range result.Movies {
+		compared := ComparedMovie{Key: movie.Key, Title: movie.Title, URL: movie.URL, Genres: movie.Genres}
+		for _, user := range movie.Users {
+			compared.Users = append(compared.Users, user.Name)
+		}
+		record.Movies = append(record.Movies, compared)
+	}
+
+	history.Records = append(history.Records, record)
+	if len(history.Records) > maxComparisons {
+		history.Records = history.Records[len(history.Records)-maxComparisons:]
+	}
+	return record.ID, a.store.save("comparisons", history)
+}
+
+// loadComparisons reads the comparison history
+func (a *App) loadComparisons() (comparisonHistory, error) {
+	var history comparisonHistory
+	if err := a.store.load("comparisons", &history); err != nil {
+		return history, fmt.Errorf("could not load comparison history: %v", err)
+	}
+	return history, nil
+}
+
+// GetComparisonHistory returns the past comparisons, newest first
+func (a *App) GetComparisonHistory() ([]ComparisonRecord, error) {
+	history, err := a.loadComparisons()
+	if err != nil {
+		return nil, err
+	}
+	records := history.Records
+	for i, j := 0, len(records)-1; i < j; i, j = i+1, j-1 {
+		records[i], records[j] = records[j], records[i]
+	}
+	return records, nil
+}
+
+// GetOverlappingComparisons returns the IDs of past comparisons sharing at
+// least one participant with the given one, newest first, as candidates to
+// merge with it
+func (a *App) GetOverlappingComparisons(id int) ([]int, error) {
+	records, err := a.GetComparisonHistory()
+	if err != nil {
+		return nil, err
+	}
+	var target *ComparisonRecord
+	for i := range records {
+		if records[i].ID == id {
+			target = &records[i]
+		}
+	}
+	if target == nil {
+		return nil, fmt.Errorf("comparison %d not found", id)
+	}
+
+	var ids []int
+	for _, record := range records {
+		if record.ID != id && sharesUser(record.Usernames, target.Usernames) {
+			ids = append(ids, record.ID)
+		}
+	}
+	return ids, nil
+}
+
+// MergeResults combines past comparisons into one view listing, for every
+// film, the subgroups it was common in; each comparison must share a
+// participant with another one
+func (a *App) MergeResults(ids []int) (MergedResult, error) {
+	var merged MergedResult
+	if len(ids) < 2 {
+		return merged, fmt.Errorf("at least two comparisons are needed to merge")
+	}
+	history, err := a.loadComparisons()
+	if err != nil {
+		return merged, err
+	}
+	byID := make(map[int]ComparisonRecord, len(history.Records))
+	for _, record := range history.Records {
+		byID[record.ID] = record
+	}
+
+	var records []ComparisonRecord
+	for _, id := range ids {
+		record, ok := byID[id]
+		if !ok {
+			return merged, fmt.Errorf("comparison %d not found", id)
+		}
+		records = append(records, record)
+	}
+	for i, record := range records {
+		overlaps := false
+		for j, other := range records {
+			if i != j && sharesUser(record.Usernames, other.Usernames) {
+				overlaps = true
+				break
+			}
+		}
+		if !overlaps {
+			return merged, fmt.Errorf("comparison %d shares no participants with the others", record.ID)
+		}
+	}
+
+	usernames := make(map[string]bool)
+	movies := make(map[string]*MergedMovie)
+	for _, record := range records {
+		merged.IDs = append(merged.IDs, record.ID)
+		merged.Subgroups = append(merged.Subgroups, Subgroup{
+			ID:           record.ID,
+			RunAt:        record.RunAt,
+			Usernames:    record.Usernames,
+			CommonMovies: len(record.Movies),
+		})
+		for _, username := range record.Usernames {
+			usernames[username] = true
+		}
+
+		for _, compared := range record.Movies {
+			movie, ok := movies[compared.Key]
+			if !ok {
+				movie = &MergedMovie{Key: compared.Key, Title: compared.Title, URL: compared.URL}
+				movies[compared.Key] = movie
+			}
+			movie.Subgroups = append(movie.Subgroups, record.ID)
+			for _, user := range compared.Users {
+				if !slices.Contains(movie.Users, user) {
+					movie.Users = append(movie.Users, user)
+				}
+			}
+		}
+	}
+	merged.Usernames = sortedKeys(usernames)
+
+	for _, movie := range movies {
+		sort.Strings(movie.Users)
+		merged.Movies = append(merged.Movies, *movie)
+	}
+	sort.Slice(merged.Movies, func(i, j int) bool {
+		mi, mj := merged.Movies[i], merged.Movies[j]
+		if len(mi.Subgroups) != len(mj.Subgroups) {
+			return len(mi.Subgroups) > len(mj.Subgroups)
+		}
+		if len(mi.Users) != len(mj.Users) {
+			return len(mi.Users) > len(mj.Users)
+		}
+		return mi.Title < mj.Title
+	})
+	return merged, nil
+}
+
+// sharesUser reports whether two participant lists have anyone in common
+func sharesUser(a, b []string) bool {
+	for _, x := range a {
+		for _, y := range b {
+			if x == y {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// saveComparison records a comparison in the history, setting its ID
+func (a *App) saveComparison(result *ComparisonResultV2) {
+	id, err := a.recordComparison(*result)
+	if err != nil {
+		log.Printf("Could not record comparison history: %v", err)
+		return
+	}
+	result.ID = id
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+)
+
+const (
+	// tmdbPriorVotes is how many votes a TMDB average needs before it
+	// outweighs the prior, so a 9.0 from a dozen votes doesn't top the list
+	tmdbPriorVotes = 500
+	// tmdbPriorMean is the vote average assumed for films with few votes
+	tmdbPriorMean = 6.5
+)
+
+// CompositeWeights sets how much each rating source counts towards a
+// movie's composite score; sources without a rating for a film are skipped
+type CompositeWeights struct {
+	Letterboxd float64 `json:"letterboxd"`
+	TMDB       float64 `json:"tmdb"`
+	IMDb       float64 `json:"imdb"`
+}
+
+// defaultCompositeWeights favours the Letterboxd community rating
+var defaultCompositeWeights = CompositeWeights{Letterboxd: 0.6, TMDB: 0.4, IMDb: 0}
+
+// GetCompositeWeights returns the configured composite score weights
+func (a *App) GetCompositeWeights() CompositeWeights {
+	weights := a.loadSettings().CompositeWeights
+	if weights.Letterboxd <= 0 && weights.TMDB <= 0 && weights.IMDb <= 0 {
+		return defaultCompositeWeights
+	}
+	return weights
+}
+
+// compositeConfigured reports whether the user has set their own composite
+// weights, which makes the composite score lead the default sort
+func (a *App) compositeConfigured() bool {
+	weights := a.loadSettings().CompositeWeights
+	return weights.Letterboxd > 0 || weights.TMDB > 0 || weights.IMDb > 0
+}
+
+// SetCompositeWeights stores the composite score weights; all zero
+// restores the defaults
+func (a *App) SetCompositeWeights(weights CompositeWeights) error {
+	if weights.Letterboxd < 0 || weights.TMDB < 0 || weights.IMDb < 0 {
+		return fmt.Errorf("composite weights can't be negative")
+	}
+	settings := a.loadSettings()
+	settings.CompositeWeights = weights
+	return a.saveSettings(settings)
+}
+
+// compositeScore blends the available ratings of a movie on a 0-10 scale:
+// the Letterboxd average (out of 5, doubled), the TMDB vote average shrunk
+// towards a prior by vote count, and the IMDb rating
+func compositeScore(movie Movie, weights CompositeWeights) float64 {
+	var total, weightSum float64
+	add := func(rating, weight float64) {
+		if rating > 0 && weight > 0 {
+			total += rating * weight
+			weightSum += weight
+		}
+	}
+
+	add(movie.LetterboxdRating*2, weights.Letterboxd)
+	if movie.Rating > 0 {
+		votes := float64(movie.VoteCount)
+		add((votes*movie.Rating+tmdbPriorVotes*tmdbPriorMean)/(votes+tmdbPriorVotes), weights.TMDB)
+	}
+	add(movie.IMDbRating, weights.IMDb)
+
+	if weightSum == 0 {
+		return 0
+	}
+	return total / weightSum
+}
+
+// sortMovies orders comparison results with pinned movies first, then by
+// weighted overlap, composite score, count and title; with byComposite the
+// composite score leads and the overlap breaks ties
+func sortMovies(movies []Movie, byComposite bool) {
+	sort.Slice(movies, func(i, j int) bool {
+		if movies[i].Pinned != movies[j].Pinned {
+			return movies[i].Pinned
+		}
+		if byComposite && movies[i].CompositeScore != movies[j].CompositeScore {
+			return movies[i].CompositeScore > movies[j].CompositeScore
+		}
+		if movies[i].Score != movies[j].Score {
+			return movies[i].Score > movies[j].Score
+		}
+		if movies[i].CompositeScore != movies[j].CompositeScore {
+			return movies[i].CompositeScore > movies[j].CompositeScore
+		}
+		if movies[i].Count != movies[j].Count {
+			return movies[i].Count > movies[j].Count
+		}
+		return movies[i].Title < movies[j].Title
+	})
+}
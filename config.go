@@ -2,6 +2,7 @@ package main
 
 import (
 	"os"
+	"path/filepath"
 )
 
 // GetTMDBAPIKey retrieves the TMDB API key from environment variable or returns placeholder
@@ -12,4 +13,22 @@ func GetTMDBAPIKey() string {
 		key = "YOUR_API_KEY_HERE" // Fallback placeholder
 	}
 	return key
-}
\ No newline at end of file
+}
+
+// GetCacheDir returns the directory used for on-disk caches
+func GetCacheDir() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+	return filepath.Join(dir, "klisse")
+}
+
+// GetDataDir returns the directory used for persistent local data
+func GetDataDir() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+	return filepath.Join(dir, "klisse")
+}
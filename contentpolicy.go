@@ -0,0 +1,118 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// certificationAges maps age certifications to the minimum age they allow,
+// covering the US and UK ratings; numeric ratings such as Germany's "12"
+// are read as ages directly
+var certificationAges = map[string]int{
+	"G": 0, "PG": 8, "PG-13": 13, "R": 17, "NC-17": 18,
+	"U": 0, "12A": 12, "15": 15, "18": 18, "R18": 18,
+}
+
+// ContentPolicy restricts every comparison of a profile, e.g. for family
+// movie nights; the zero value allows everything
+type ContentPolicy struct {
+	// MaxCertification drops films rated above it, e.g. "PG-13"; films with
+	// no known certification are dropped too unless AllowUnrated is set
+	MaxCertification string `json:"max_certification"`
+	AllowUnrated     bool   `json:"allow_unrated"`
+
+	// ExcludeGenres drops films in any of the given TMDB genres
+	ExcludeGenres []string `json:"exclude_genres"`
+
+	// ExcludeKeywords drops films tagged with any of the given TMDB
+	// keywords, e.g. "gore"
+	ExcludeKeywords []string `json:"exclude_keywords"`
+}
+
+// GetContentPolicy returns the active profile's content policy
+func (a *App) GetContentPolicy() ContentPolicy {
+	return a.loadSettings().ContentPolicy
+}
+
+// SetContentPolicy stores the content policy applied to every comparison
+// of the active profile
+func (a *App) SetContentPolicy(policy ContentPolicy) error {
+	policy.MaxCertification = strings.ToUpper(strings.TrimSpace(policy.MaxCertification))
+	if policy.MaxCertification != "" {
+		if _, ok := certificationAge(policy.MaxCertification); !ok {
+			return fmt.Errorf("unknown certification '%s'", policy.MaxCertification)
+		}
+	}
+	policy.ExcludeGenres = trimmedNonEmpty(policy.ExcludeGenres)
+	policy.ExcludeKeywords = trimmedNonEmpty(policy.ExcludeKeywords)
+
+	settings := a.loadSettings()
+	settings.ContentPolicy = policy
+	return a.saveSettings(settings)
+}
+
+// active reports whether the policy restricts anything
+func (p ContentPolicy) active() bool {
+	return p.MaxCertification != "" || len(p.ExcludeGenres) > 0 || len(p.ExcludeKeywords) > 0
+}
+
+// allows reports whether a hydrated movie passes the policy
+func (p ContentPolicy) allows(movie Movie) bool {
+	if p.MaxCertification != "" {
+		limit, _ := certificationAge(p.MaxCertification)
+		age, ok := certificationAge(movie.Certification)
+		if (!ok && !p.AllowUnrated) || (ok && age > limit) {
+			return false
+		}
+	}
+	for _, genre := range movie.Genres {
+		for _, excluded := range p.ExcludeGenres {
+			if strings.EqualFold(genre, excluded) {
+				return false
+			}
+		}
+	}
+	for _, keyword := range movie.Keywords {
+		for _, excluded := range p.ExcludeKeywords {
+			if strings.EqualFold(keyword, excluded) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// filterContent drops the movies the policy doesn't allow
+func filterContent(movies []Movie, policy ContentPolicy) []Movie {
+	var kept []Movie
+	for _, movie := range movies {
+		if policy.allows(movie) {
+			kept = append(kept, movie)
+		}
+	}
+	return kept
+}
+
+// certificationAge returns the minimum age a certification allows
+func certificationAge(certification string) (int, bool) {
+	certification = strings.ToUpper(strings.TrimSpace(certification))
+	if age, ok := certificationAges[certification]; ok {
+		return age, true
+	}
+	if age, err := strconv.Atoi(strings.TrimPrefix(certification, "FSK ")); err == nil && age >= 0 && age <= 21 {
+		return age, true
+	}
+	return 0, false
+}
+
+// trimmedNonEmpty returns the values trimmed, dropping empty ones
+func trimmedNonEmpty(values []string) []string {
+	var kept []string
+	for _, value := range values {
+		if value = strings.TrimSpace(value); value != "" {
+			kept = append(kept, value)
+		}
+	}
+	return kept
+}
@@ -0,0 +1,217 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Rating sources a RatingFilter can test
+const (
+	SourceRottenTomatoes = "rotten_tomatoes" // Tomatometer, 0-100
+	SourceMetacritic     = "metacritic"      // Metascore, 0-100
+	SourceIMDb           = "imdb"            // IMDb rating, 0-10
+	SourceTMDB           = "tmdb"            // TMDB vote average, 0-10
+)
+
+// Operators combining a RatingFilter's sub-filters
+const (
+	FilterAll = "all"
+	FilterAny = "any"
+)
+
+// RatingFilter is a composable predicate over critic and audience scores,
+// e.g. "Rotten Tomatoes ≥ 80 or Metascore ≥ 70" is
+//
+//	{op: "any", filters: [{source: "rotten_tomatoes", min: 80}, {source: "metacritic", min: 70}]}
+//
+// A filter either combines sub-filters with Op or, without Op, tests a
+// single Source against Min. Films without a score for a source fail it.
+type RatingFilter struct {
+	Op      string         `json:"op"`
+	Filters []RatingFilter `json:"filters"`
+	Source  string         `json:"source"`
+	Min     float64        `json:"min"`
+}
+
+// validate checks that a filter and its sub-filters are well formed
+func (f RatingFilter) validate() error {
+	switch f.Op {
+	case FilterAll, FilterAny:
+		if len(f.Filters) == 0 {
+			return fmt.Errorf("rating filter '%s' has no sub-filters", f.Op)
+		}
+		for _, sub := range f.Filters {
+			if err := sub.validate(); err != nil {
+				return err
+			}
+		}
+		return nil
+	case "":
+		switch f.Source {
+		case SourceRottenTomatoes, SourceMetacritic, SourceIMDb, SourceTMDB:
+			return nil
+		}
+		return fmt.Errorf("unknown rating source '%s'", f.Source)
+	default:
+		return fmt.Errorf("unknown rating filter operator '%s'", f.Op)
+	}
+}
+
+// matches reports whether a movie satisfies the filter
+func (f RatingFilter) matches(movie Movie) bool {
+	switch f.Op {
+	case FilterAll:
+		for _, sub := range f.Filters {
+			if !sub.matches(movie) {
+				return false
+			}
+		}
+		return true
+	case FilterAny:
+		for _, sub := range f.Filters {
+			if sub.matches(movie) {
+				return true
+			}
+		}
+		return false
+	}
+
+	var score float64
+	switch f.Source {
+	case SourceRottenTomatoes:
+		score = float64(movie.RottenTomatoes)
+	case SourceMetacritic:
+		score = float64(movie.Metascore)
+	case SourceIMDb:
+		score = movie.IMDbRating
+	case SourceTMDB:
+		score = movie.Rating
+	}
+	return score > 0 && score >= f.Min
+}
+
+// needsCritics reports whether a filter tests a score only OMDb provides
+func (f RatingFilter) needsCritics() bool {
+	if f.Source == SourceRottenTomatoes || f.Source == SourceMetacritic || f.Source == SourceIMDb {
+		return true
+	}
+	for _, sub := range f.Filters {
+		if sub.needsCritics() {
+			return true
+		}
+	}
+	return false
+}
+
+// CriticScores are a film's critic and IMDb scores from OMDb
+type CriticScores struct {
+	RottenTomatoes int     `json:"rotten_tomatoes"` // 0 when unknown
+	Metascore      int     `json:"metascore"`       // 0 when unknown
+	IMDbRating     float64 `json:"imdb_rating"`     // 0 when unknown
+}
+
+// omdbResponse represents an OMDb title response
+type omdbResponse struct {
+	Response   string `json:"Response"`
+	Error      string `json:"Error"`
+	Metascore  string `json:"Metascore"`
+	IMDbRating string `json:"imdbRating"`
+	Ratings    []struct {
+		Source string `json:"Source"`
+		Value  string `json:"Value"`
+	} `json:"Ratings"`
+}
+
+// SetOMDbAPIKey stores the OMDb API key critic scores are fetched with; an
+// empty key disables them
+func (a *App) SetOMDbAPIKey(apiKey string) error {
+	settings := a.loadSettings()
+	settings.OMDbAPIKey = strings.TrimSpace(apiKey)
+	return a.saveSettings(settings)
+}
+
+// GetCriticScores returns a film's Rotten Tomatoes, Metacritic and IMDb
+// scores from OMDb, looked up by IMDb ID
+func (a *App) GetCriticScores(movie Movie) (CriticScores, error) {
+	var scores CriticScores
+	apiKey := a.loadSettings().OMDbAPIKey
+	if apiKey == "" {
+		return scores, fmt.Errorf("OMDb API key not configured")
+	}
+	if movie.IMDBID == "" {
+		return scores, fmt.Errorf("no IMDb ID for '%s' to find critic scores with", movie.Title)
+	}
+
+	params := url.Values{"apikey": {apiKey}, "i": {movie.IMDBID}}
+	client := &http.Client{Transport: sharedTransport, Timeout: 10 * time.Second}
+	start := time.Now()
+	resp, err := client.Get("https://www.omdbapi.com/?" + params.Encode())
+	a.metrics.since("omdb_request", start)
+	if err != nil {
+		a.metrics.countError("omdb")
+		return scores, fmt.Errorf("failed to get critic scores: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		a.metrics.countError("omdb")
+		return scores, fmt.Errorf("OMDb error: status code %d", resp.StatusCode)
+	}
+
+	var data omdbResponse
+	if err := json.NewDecoder(resp.Body).Decode(&data); err != nil {
+		return scores, fmt.Errorf("failed to parse critic scores: %v", err)
+	}
+	if data.Response != "True" {
+		return scores, fmt.Errorf("OMDb error: %s", data.Error)
+	}
+
+	// Missing scores are reported as "N/A", which parse to zero
+	scores.Metascore, _ = strconv.Atoi(data.Metascore)
+	scores.IMDbRating, _ = strconv.ParseFloat(data.IMDbRating, 64)
+	for _, rating := range data.Ratings {
+		if rating.Source == "Rotten Tomatoes" {
+			scores.RottenTomatoes, _ = strconv.Atoi(strings.TrimSuffix(rating.Value, "%"))
+		}
+	}
+	return scores, nil
+}
+
+// setCriticScores copies critic scores into a movie
+func setCriticScores(movie *Movie, scores CriticScores) {
+	movie.RottenTomatoes = scores.RottenTomatoes
+	movie.Metascore = scores.Metascore
+	movie.IMDbRating = scores.IMDbRating
+}
+
+// annotateCritics looks up the critic scores of every hydrated movie and
+// refreshes their composite scores
+func (a *App) annotateCritics(movies []Movie) {
+	weights := a.GetCompositeWeights()
+	forEachIndex(len(movies), enrichWorkers, func(index int) {
+		if movies[index].IMDBID == "" {
+			return
+		}
+		scores, err := a.GetCriticScores(movies[index])
+		if err != nil {
+			return
+		}
+		setCriticScores(&movies[index], scores)
+		movies[index].CompositeScore = compositeScore(movies[index], weights)
+	})
+}
+
+// filterRatings keeps only movies satisfying the rating filter
+func filterRatings(movies []Movie, filter RatingFilter) []Movie {
+	filtered := movies[:0]
+	for _, movie := range movies {
+		if filter.matches(movie) {
+			filtered = append(filtered, movie)
+		}
+	}
+	return filtered
+}
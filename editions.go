@@ -0,0 +1,179 @@
+package main
+
+import (
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// editionRegex matches the edition a normalized title ends in, so "Blade
+// Runner: The Final Cut" and "Apocalypse Now Redux" reduce to the film
+var editionRegex = regexp.MustCompile(`\s+(the\s+)?((directors?|extended|final|theatrical|ultimate|special|international|producers?|collectors?|restored|remastered|uncut|unrated|anniversary|\d+(st|nd|rd|th) anniversary)\s+(cut|edition|version)|redux|remastered|uncut|rerelease|re release)$`)
+
+// Edition is another Letterboxd entry for the same film, such as a
+// director's cut or a re-release, merged into a result
+type Edition struct {
+	Key   string   `json:"key"`
+	Title string   `json:"title"`
+	URL   string   `json:"url"`
+	Users []string `json:"users"`
+}
+
+// baseTitle returns the normalized title of the film an edition is of and
+// whether the title named an edition at all
+func baseTitle(title string) (string, bool) {
+	normalized := normalizeTitle(title)
+	base := strings.TrimSpace(editionRegex.ReplaceAllString(normalized, ""))
+	if base == "" || base == "the" {
+		// Titles like "The Final Cut" are films, not editions
+		return normalized, false
+	}
+	return base, base != normalized
+}
+
+// mergeEditions folds the entries for other cuts of a film into the entry
+// for the film itself, so a user with the director's cut on their watchlist
+// overlaps with one who has the theatrical release. Entries are grouped by
+// title with the edition dropped; a group is merged only if at most one of
+// its entries is the plain film, otherwise remakes sharing a title couldn't
+// be told apart. Without a plain entry the edition on the most watchlists
+// stands in for it. Skipped keys, such as excluded films, are left alone.
+// The entries are replaced rather than modified since they are cached; the
+// merged-away editions are returned by the key they were merged into.
+func mergeEditions(entries map[string]*commonEntry, skip func(key string) bool) (map[string]*commonEntry, map[string][]Edition) {
+	type member struct {
+		key     string
+		edition bool
+	}
+	groups := make(map[string][]member)
+	for key, entry := range entries {
+		if skip(key) {
+			continue
+		}
+		base, edition := baseTitle(entry.Title)
+		groups[base] = append(groups[base], member{key, edition})
+	}
+
+	merged, copied := entries, false
+	editions := make(map[string][]Edition)
+	for _, members := range groups {
+		plain := 0
+		for _, m := range members {
+			if !m.edition {
+				plain++
+			}
+		}
+		if len(members) < 2 || plain > 1 {
+			continue
+		}
+
+		// The plain film comes first, then the editions on most watchlists
+		sort.Slice(members, func(i, j int) bool {
+			if members[i].edition != members[j].edition {
+				return !members[i].edition
+			}
+			if ni, nj := len(entries[members[i].key].Users), len(entries[members[j].key].Users); ni != nj {
+				return ni > nj
+			}
+			return members[i].key < members[j].key
+		})
+
+		if !copied {
+			copied = true
+			merged = make(map[string]*commonEntry, len(entries))
+			for key, entry := range entries {
+				merged[key] = entry
+			}
+		}
+
+		primary := members[0].key
+		combined := *entries[primary]
+		combined.Users = append([]string{}, combined.Users...)
+		for _, m := range members[1:] {
+			entry := entries[m.key]
+			editions[primary] = append(editions[primary], Edition{
+				Key:   m.key,
+				Title: entry.Title,
+				URL:   entry.URL,
+				Users: entry.Users,
+			})
+			for _, user := range entry.Users {
+				if !slices.Contains(combined.Users, user) {
+					combined.Users = append(combined.Users, user)
+				}
+			}
+			delete(merged, m.key)
+		}
+		sort.Strings(combined.Users)
+		merged[primary] = &combined
+	}
+	return merged, editions
+}
+
+// mergeSameFilm merges hydrated movies that matched the same TMDB film,
+// such as a re-release Letterboxd lists separately, into the one on the
+// most watchlists, recounting its overlap score
+func mergeSameFilm(movies []Movie, opts CompareOptions) []Movie {
+	sort.SliceStable(movies, func(i, j int) bool {
+		if movies[i].Count != movies[j].Count {
+			return movies[i].Count > movies[j].Count
+		}
+		return movies[i].Key < movies[j].Key
+	})
+
+	kept := make(map[int]int)
+	merged := movies[:0]
+	for _, movie := range movies {
+		at, ok := kept[movie.TMDBID]
+		if movie.TMDBID == 0 || !ok {
+			if movie.TMDBID != 0 {
+				kept[movie.TMDBID] = len(merged)
+			}
+			merged = append(merged, movie)
+			continue
+		}
+
+		into := &merged[at]
+		users := make([]string, 0, len(movie.Users))
+		for _, user := range movie.Users {
+			users = append(users, user.Name)
+		}
+		into.Editions = append(into.Editions, Edition{Key: movie.Key, Title: movie.Title, URL: movie.URL, Users: users})
+		into.Editions = append(into.Editions, movie.Editions...)
+		for _, user := range movie.Users {
+			if !hasUser(into.Users, user.Name) {
+				into.Users = append(into.Users, user)
+			}
+		}
+		into.SeenBy = appendMissing(into.SeenBy, movie.SeenBy)
+		into.LovedBy = appendMissing(into.LovedBy, movie.LovedBy)
+		into.Pinned = into.Pinned || movie.Pinned
+		into.Count = len(into.Users)
+		into.Score = 0
+		for _, user := range into.Users {
+			into.Score += opts.weight(user.Name)
+		}
+		for _, username := range into.LovedBy {
+			if !hasUser(into.Users, username) {
+				into.Score += opts.weight(username)
+			}
+		}
+	}
+	return merged
+}
+
+// hasUser reports whether a user is among users
+func hasUser(users []User, name string) bool {
+	return slices.ContainsFunc(users, func(u User) bool { return u.Name == name })
+}
+
+// appendMissing appends the values not already in list
+func appendMissing(list []string, values []string) []string {
+	for _, value := range values {
+		if !slices.Contains(list, value) {
+			list = append(list, value)
+		}
+	}
+	return list
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"sync/atomic"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// enrichWorkers is the number of concurrent TMDB enrichment workers
+const enrichWorkers = 4
+
+// EnrichmentEvent is emitted on "enrich:movie" for every title processed by EnrichMovies
+type EnrichmentEvent struct {
+	Title string `json:"title"`
+	Movie Movie  `json:"movie"`
+	Error string `json:"error"`
+}
+
+// EnrichMovies fetches TMDB details for the given titles in rate-limited
+// batches, emitting an "enrich:movie" event as each one completes and
+// "enrich:done" once all are processed
+func (a *App) EnrichMovies(titles []string) error {
+	forEachIndex(len(titles), enrichWorkers, func(index int) {
+		movie := Movie{Title: titles[index]}
+		event := EnrichmentEvent{Title: titles[index]}
+		if err := a.enrichMovie(&movie); err != nil {
+			event.Error = err.Error()
+		}
+		event.Movie = movie
+		a.emit("enrich:movie", event)
+	})
+
+	a.emit("enrich:done", len(titles))
+	return nil
+}
+
+// emit sends an event to the frontend when running inside the Wails window
+func (a *App) emit(name string, data ...interface{}) {
+	if a.ctx == nil {
+		return
+	}
+	runtime.EventsEmit(a.ctx, name, data...)
+}
+
+// hydrateBare returns a copy of movies with the bare results, those the
+// frontend hasn't hydrated yet, hydrated
+func (a *App) hydrateBare(movies []Movie) []Movie {
+	hydrated := make([]Movie, len(movies))
+	copy(hydrated, movies)
+	var bare []Movie
+	var bareIndex []int
+	for i, movie := range hydrated {
+		if movie.TMDBID == 0 && movie.Runtime == 0 {
+			bare = append(bare, movie)
+			bareIndex = append(bareIndex, i)
+		}
+	}
+	if len(bare) > 0 {
+		a.hydrateMovies(bare, nil, presets[PresetStandard])
+		for i, index := range bareIndex {
+			hydrated[index] = bare[i]
+		}
+	}
+	return hydrated
+}
+
+// hydrateMovies fetches details for comparison results concurrently with the
+// preset's workers and providers, reusing any already saved in the
+// checkpoint; it returns how many movies couldn't be matched
+func (a *App) hydrateMovies(movies []Movie, cp *checkpoint, preset Preset) int {
+	var unmatched atomic.Int32
+	chain := preset.chain(a)
+	forEachIndex(len(movies), preset.Workers, func(index int) {
+		movie := &movies[index]
+		if saved, ok := cp.enriched(movie.Key); ok {
+			saved.Users, saved.Count, saved.Score = movie.Users, movie.Count, movie.Score
+			saved.SeenBy, saved.LovedBy = movie.SeenBy, movie.LovedBy
+			saved.Note, saved.Tags, saved.Pinned = movie.Note, movie.Tags, movie.Pinned
+			*movie = saved
+			return
+		}
+		if err := a.enrichMovieWith(movie, chain); err != nil {
+			unmatched.Add(1)
+			return
+		}
+		a.recordEnriched(cp, *movie)
+	})
+	a.flushCheckpoint(cp)
+	return int(unmatched.Load())
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Entry types assigned to watchlist entries
+const (
+	EntryFeature = "feature"
+	EntryShort   = "short"
+	EntryConcert = "concert"
+	EntryStandUp = "stand-up"
+	EntryTVMovie = "tv-movie"
+	EntryUnknown = "unknown"
+)
+
+// shortMaxRuntime is the Academy's cut-off for short films, in minutes
+const shortMaxRuntime = 40
+
+// isShort reports whether a known runtime, in minutes, makes a film a short
+func isShort(runtime int) bool {
+	return runtime > 0 && runtime <= shortMaxRuntime
+}
+
+// selfCharacterRegex matches the character TMDB credits performers in
+// concert films and documentaries as, e.g. "Self" or "Himself - Vocals"
+var selfCharacterRegex = regexp.MustCompile(`(?i)^(self|himself|herself|themselves)\b`)
+
+// classifyEntry tells features apart from shorts, concert films, stand-up
+// specials and TV movies using TMDB keywords, genres and runtime
+func classifyEntry(details TMDBMovie) string {
+	genres := make(map[string]bool, len(details.Genres))
+	for _, genre := range details.Genres {
+		genres[genre.Name] = true
+	}
+
+	for _, keyword := range details.Keywords.Keywords {
+		name := strings.ToLower(keyword.Name)
+		switch {
+		case strings.Contains(name, "stand-up") || strings.Contains(name, "stand up comedy"):
+			return EntryStandUp
+		case name == "concert film" || strings.Contains(name, "concert") && genres["Music"]:
+			return EntryConcert
+		}
+	}
+
+	switch {
+	case isShort(details.Runtime):
+		return EntryShort
+	case genres["TV Movie"]:
+		return EntryTVMovie
+	default:
+		return EntryFeature
+	}
+}
+
+// concertArtist names the artist performing in a concert film: the first
+// cast member playing themselves, else the top-billed one, else the title
+// up to a colon as in "Taylor Swift: The Eras Tour"
+func concertArtist(details TMDBMovie, title string) string {
+	for _, cast := range details.Credits.Cast {
+		if selfCharacterRegex.MatchString(cast.Character) {
+			return cast.Name
+		}
+	}
+	if len(details.Credits.Cast) > 0 {
+		return details.Credits.Cast[0].Name
+	}
+	if artist, _, ok := strings.Cut(title, ":"); ok {
+		return strings.TrimSpace(artist)
+	}
+	return ""
+}
+
+// filterEntryTypes keeps only hydrated movies of the allowed entry types;
+// movies that couldn't be classified are kept
+func filterEntryTypes(movies []Movie, allowed []string) []Movie {
+	return filterKinds(movies, allowed, func(movie Movie) string { return movie.EntryType })
+}
+
+// filterKinds keeps only movies whose kind, such as their entry type, is
+// allowed; movies of unknown kind are kept
+func filterKinds(movies []Movie, allowed []string, kind func(movie Movie) string) []Movie {
+	allow := make(map[string]bool, len(allowed))
+	for _, k := range allowed {
+		allow[k] = true
+	}
+
+	filtered := movies[:0]
+	for _, movie := range movies {
+		if k := kind(movie); k == "" || k == EntryUnknown || allow[k] {
+			filtered = append(filtered, movie)
+		}
+	}
+	return filtered
+}
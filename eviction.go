@@ -0,0 +1,114 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+const (
+	// defaultCacheMaxMB caps each cache unless configured otherwise
+	defaultCacheMaxMB = 256
+	// evictionTarget is the share of the cap eviction frees the cache down
+	// to, so a full cache isn't evicted again on every write
+	evictionTarget = 0.9
+)
+
+// SetCacheLimit sets the maximum size of the page cache and of the TMDB
+// response cache in megabytes, evicting the least recently used entries if
+// they are already bigger; zero restores the default
+func (a *App) SetCacheLimit(mb int) error {
+	if mb < 0 {
+		return fmt.Errorf("cache limit can't be negative")
+	}
+	settings := a.loadSettings()
+	settings.CacheMaxMB = mb
+	if err := a.saveSettings(settings); err != nil {
+		return err
+	}
+	a.scrapeCache.setLimit(cacheLimitBytes(settings))
+	a.tmdbCache.setLimit(cacheLimitBytes(settings))
+	return nil
+}
+
+// cacheLimitBytes returns the configured cap of each cache in bytes
+func cacheLimitBytes(settings Settings) int64 {
+	mb := settings.CacheMaxMB
+	if mb <= 0 {
+		mb = defaultCacheMaxMB
+	}
+	return int64(mb) << 20
+}
+
+// setLimit changes the cache cap, evicting right away if it is exceeded
+func (t *cachingTransport) setLimit(maxBytes int64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.maxBytes = maxBytes
+	if t.size < 0 || t.size > t.maxBytes {
+		t.evict()
+	}
+}
+
+// grow accounts for a written entry, evicting once the cap is exceeded
+func (t *cachingTransport) grow(n int64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.maxBytes <= 0 {
+		return
+	}
+	if t.size < 0 {
+		t.evict()
+		return
+	}
+	// Rewrites of an entry are counted twice; evict measures the real size
+	t.size += n
+	if t.size > t.maxBytes {
+		t.evict()
+	}
+}
+
+// evict measures the cache and, if it is over the cap, removes the least
+// recently used entries until it is under evictionTarget of it. Entries are
+// rewritten whenever they are revalidated, so their modification time is
+// their last use. It must be called with t.mu held.
+func (t *cachingTransport) evict() {
+	type entry struct {
+		path    string
+		size    int64
+		modTime time.Time
+	}
+	paths, _ := filepath.Glob(filepath.Join(t.dir, "*.json"))
+	var entries []entry
+	var total int64
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+		entries = append(entries, entry{path, info.Size(), info.ModTime()})
+		total += info.Size()
+	}
+
+	if t.maxBytes > 0 && total > t.maxBytes {
+		sort.Slice(entries, func(i, j int) bool {
+			return entries[i].modTime.Before(entries[j].modTime)
+		})
+		target := int64(float64(t.maxBytes) * evictionTarget)
+		removed := 0
+		for _, e := range entries {
+			if total <= target {
+				break
+			}
+			if os.Remove(e.path) == nil {
+				total -= e.size
+				removed++
+			}
+		}
+		log.Printf("Evicted %d entries from the cache in %s", removed, t.dir)
+	}
+	t.size = total
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Exclusion is a film the group has vetoed from all future comparisons
+type Exclusion struct {
+	Key        string    `json:"key"`
+	Title      string    `json:"title"`
+	ExcludedAt time.Time `json:"excluded_at"`
+}
+
+// ExcludeMovie permanently hides a movie from future comparisons
+func (a *App) ExcludeMovie(key string, title string) error {
+	if key == "" {
+		return fmt.Errorf("no movie key provided")
+	}
+
+	a.exclusionsMu.Lock()
+	defer a.exclusionsMu.Unlock()
+
+	exclusions, err := a.loadExclusions()
+	if err != nil {
+		return err
+	}
+	if _, exists := exclusions[key]; exists {
+		return nil
+	}
+	exclusions[key] = Exclusion{Key: key, Title: title, ExcludedAt: time.Now()}
+	return a.store.save("exclusions", exclusions)
+}
+
+// IncludeMovie removes a movie from the exclusion list
+func (a *App) IncludeMovie(key string) error {
+	a.exclusionsMu.Lock()
+	defer a.exclusionsMu.Unlock()
+
+	exclusions, err := a.loadExclusions()
+	if err != nil {
+		return err
+	}
+	delete(exclusions, key)
+	return a.store.save("exclusions", exclusions)
+}
+
+// ListExclusions returns all excluded movies, most recent first
+func (a *App) ListExclusions() ([]Exclusion, error) {
+	exclusions, err := a.loadExclusions()
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]Exclusion, 0, len(exclusions))
+	for _, exclusion := range exclusions {
+		list = append(list, exclusion)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].ExcludedAt.After(list[j].ExcludedAt)
+	})
+	return list, nil
+}
+
+// loadExclusions reads the exclusion list keyed by movie key
+func (a *App) loadExclusions() (map[string]Exclusion, error) {
+	exclusions := make(map[string]Exclusion)
+	if err := a.store.load("exclusions", &exclusions); err != nil {
+		return nil, err
+	}
+	return exclusions, nil
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"encoding/base64"
+	"fmt"
+	"sort"
+	"strconv"
+)
+
+// CursorPage is a slice of a comparison session's movies fetched by cursor,
+// for virtual scrolling
+type CursorPage struct {
+	Movies []Movie `json:"movies"`
+	// NextCursor fetches the movies after this page; empty on the last one
+	NextCursor string `json:"next_cursor"`
+	// Total is the number of movies matching the query
+	Total int `json:"total"`
+}
+
+// FacetCount is how many matching movies have a facet value
+type FacetCount struct {
+	Value string `json:"value"`
+	Count int    `json:"count"`
+}
+
+// ResultFacets counts a comparison session's movies per genre and per decade,
+// for building filter chips without loading every movie
+type ResultFacets struct {
+	Total int `json:"total"`
+	// Genres and Decades are most common first; each ignores its own filter
+	// in the query, so picking a chip doesn't hide the others
+	Genres  []FacetCount `json:"genres"`
+	Decades []FacetCount `json:"decades"`
+}
+
+// GetResultsAfter returns up to limit movies of a comparison session matching
+// the query, starting after the cursor of the previous page or at the top
+// when it is empty. Cursors name the last movie seen rather than an
+// offset, so they stay valid for the session's lifetime
+func (a *App) GetResultsAfter(sessionID string, cursor string, limit int, query ResultQuery) (CursorPage, error) {
+	if limit <= 0 {
+		limit = defaultPageSize
+	}
+	if limit > maxPageSize {
+		limit = maxPageSize
+	}
+	movies, err := a.sessionMovies(sessionID)
+	if err != nil {
+		return CursorPage{}, err
+	}
+	matching, err := query.apply(movies)
+	if err != nil {
+		return CursorPage{}, err
+	}
+
+	start := 0
+	if cursor != "" {
+		key, err := base64.RawURLEncoding.DecodeString(cursor)
+		if err != nil {
+			return CursorPage{}, fmt.Errorf("invalid cursor")
+		}
+		start = -1
+		for i, movie := range matching {
+			if movie.Key == string(key) {
+				start = i + 1
+				break
+			}
+		}
+		if start < 0 {
+			return CursorPage{}, fmt.Errorf("cursor doesn't match the query, start from the top")
+		}
+	}
+
+	page := CursorPage{Total: len(matching)}
+	end := min(start+limit, len(matching))
+	page.Movies = matching[start:end]
+	if end < len(matching) {
+		page.NextCursor = base64.RawURLEncoding.EncodeToString([]byte(matching[end-1].Key))
+	}
+	return page, nil
+}
+
+// GetResultFacets counts the movies of a comparison session matching the
+// query per genre and per decade
+func (a *App) GetResultFacets(sessionID string, query ResultQuery) (ResultFacets, error) {
+	movies, err := a.sessionMovies(sessionID)
+	if err != nil {
+		return ResultFacets{}, err
+	}
+	// Ordering doesn't change counts
+	query.SortBy = ""
+
+	matching, err := query.apply(movies)
+	if err != nil {
+		return ResultFacets{}, err
+	}
+	facets := ResultFacets{Total: len(matching)}
+
+	byGenre := query
+	byGenre.Genres = nil
+	genreMovies, _ := byGenre.apply(movies)
+	genres := make(map[string]int)
+	for _, movie := range genreMovies {
+		for _, genre := range movie.Genres {
+			genres[genre]++
+		}
+	}
+	facets.Genres = facetCounts(genres)
+
+	byDecade := query
+	byDecade.Decades = nil
+	decadeMovies, _ := byDecade.apply(movies)
+	decades := make(map[string]int)
+	for _, movie := range decadeMovies {
+		if decade, ok := movieDecade(movie); ok {
+			decades[strconv.Itoa(decade)]++
+		}
+	}
+	facets.Decades = facetCounts(decades)
+	return facets, nil
+}
+
+// movieDecade returns the decade a movie was released in, e.g. 1990
+func movieDecade(movie Movie) (int, bool) {
+	year := movie.ReleaseYear
+	if year == "" {
+		_, year = filmYear(movie.Title, movie.URL)
+	}
+	y, err := strconv.Atoi(year)
+	if err != nil || y <= 0 {
+		return 0, false
+	}
+	return y / 10 * 10, true
+}
+
+// facetCounts lists counted values, most common first
+func facetCounts(counts map[string]int) []FacetCount {
+	facets := make([]FacetCount, 0, len(counts))
+	for value, count := range counts {
+		facets = append(facets, FacetCount{Value: value, Count: count})
+	}
+	sort.Slice(facets, func(i, j int) bool {
+		if facets[i].Count != facets[j].Count {
+			return facets[i].Count > facets[j].Count
+		}
+		return facets[i].Value < facets[j].Value
+	})
+	return facets
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gocolly/colly/v2"
+)
+
+// LetterboxdStats is a film's community rating and popularity on Letterboxd
+type LetterboxdStats struct {
+	Rating  float64 `json:"rating"`
+	Watches int     `json:"watches"`
+}
+
+// GetLetterboxdStats scrapes a film's average rating from its page and its
+// watch count from the stats fragment Letterboxd loads alongside it
+func (a *App) GetLetterboxdStats(filmURL string) (LetterboxdStats, error) {
+	defer a.metrics.since("film_stats_scrape", time.Now())
+	c := a.newCollector()
+
+	var stats LetterboxdStats
+	var scrapeErr error
+
+	rules := a.ruleset()
+	// The average rating is published as "3.95 out of 5"
+	c.OnHTML(rules.Selectors.FilmRating, func(e *colly.HTMLElement) {
+		if value, _, ok := strings.Cut(e.Attr("content"), " "); ok {
+			stats.Rating, _ = strconv.ParseFloat(value, 64)
+		}
+	})
+
+	// e.g. title="Watched by 1,234,567 members"
+	c.OnHTML(rules.Selectors.FilmWatches, func(e *colly.HTMLElement) {
+		if stats.Watches == 0 {
+			stats.Watches = parseCount(e.Attr("title"))
+		}
+	})
+
+	c.OnError(func(r *colly.Response, e error) {
+		a.metrics.countError("scrape")
+		scrapeErr = e
+	})
+
+	filmURL = strings.TrimSuffix(filmURL, "/") + "/"
+	if err := c.Visit(filmURL); err != nil {
+		return stats, fmt.Errorf("could not visit film page '%s': %v", filmURL, err)
+	}
+	if scrapeErr != nil {
+		return stats, scrapeErr
+	}
+
+	statsURL := forFilm(rules.URLs.FilmStats, filmURL)
+	if err := c.Visit(statsURL); err != nil {
+		return stats, fmt.Errorf("could not visit film stats '%s': %v", statsURL, err)
+	}
+	if scrapeErr != nil {
+		return stats, scrapeErr
+	}
+
+	return stats, nil
+}
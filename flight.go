@@ -0,0 +1,65 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"sync"
+)
+
+// flightGroup coalesces concurrent identical GET requests into one, in the
+// manner of golang.org/x/sync/singleflight: while a request for a URL is in
+// flight, later callers wait for it and get a copy of its response
+type flightGroup struct {
+	mu    sync.Mutex
+	calls map[string]*flight
+}
+
+// flight is a request in progress and, once done, its buffered response
+type flight struct {
+	done chan struct{}
+	resp *http.Response
+	body []byte
+	err  error
+}
+
+// get performs fetch for the key unless the same request is already in
+// flight, returning a response whose body each caller can read and close
+func (g *flightGroup) get(key string, fetch func() (*http.Response, error)) (*http.Response, error) {
+	g.mu.Lock()
+	if g.calls == nil {
+		g.calls = make(map[string]*flight)
+	}
+	if f, ok := g.calls[key]; ok {
+		g.mu.Unlock()
+		<-f.done
+		return f.response()
+	}
+	f := &flight{done: make(chan struct{})}
+	g.calls[key] = f
+	g.mu.Unlock()
+
+	f.resp, f.err = fetch()
+	if f.err == nil {
+		f.body, f.err = io.ReadAll(f.resp.Body)
+		f.resp.Body.Close()
+	}
+
+	g.mu.Lock()
+	delete(g.calls, key)
+	g.mu.Unlock()
+	close(f.done)
+	return f.response()
+}
+
+// response returns a copy of the flight's response with its own body
+func (f *flight) response() (*http.Response, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	resp := *f.resp
+	resp.Header = f.resp.Header.Clone()
+	resp.Body = io.NopCloser(bytes.NewReader(f.body))
+	resp.ContentLength = int64(len(f.body))
+	return &resp, nil
+}
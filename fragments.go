@@ -0,0 +1,110 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gocolly/colly/v2"
+)
+
+// maxFragmentPages caps the poster grid fragments fetched for a watchlist
+const maxFragmentPages = 500
+
+// onPosters collects the films of a poster grid, keyed by film
+func onPosters(c *colly.Collector, rules Ruleset, movies map[string]WatchlistEntry) {
+	selectors := rules.Selectors
+	c.OnHTML(selectors.Poster, func(e *colly.HTMLElement) {
+		link := e.ChildAttr(selectors.FilmPoster, selectors.LinkAttr)
+		title := e.ChildAttr(selectors.PosterImage, "alt")
+		if title != "" && link != "" {
+			filmURL := rules.URLs.link(link)
+			movies[movieKey(filmURL)] = WatchlistEntry{Title: title, URL: filmURL}
+		}
+	})
+}
+
+// scrapeWatchlistFragments scrapes a watchlist's first page in full, for
+// the profile header, and its other pages from the AJAX endpoint serving
+// just the poster grid, which is a fraction of the size and doesn't depend
+// on the rest of the page template
+func (a *App) scrapeWatchlistFragments(username string) (userProfile, map[string]WatchlistEntry, error) {
+	rules := a.ruleset()
+	var profile userProfile
+	movies := make(map[string]WatchlistEntry)
+	paginated := false
+	var scrapeErr error
+
+	c := a.newCollector()
+	onProfileHeader(c, rules.Selectors, &profile)
+	onPosters(c, rules, movies)
+	c.OnHTML(rules.Selectors.NextPage, func(e *colly.HTMLElement) {
+		paginated = true
+	})
+	c.OnError(func(r *colly.Response, e error) {
+		a.metrics.countError("scrape")
+		scrapeErr = e
+	})
+	if err := c.Visit(forUser(rules.URLs.Watchlist, username)); err != nil {
+		return userProfile{}, nil, fmt.Errorf("could not visit watchlist for '%s': %v", username, err)
+	}
+	if scrapeErr != nil {
+		return userProfile{}, nil, scrapeErr
+	}
+
+	for page := 2; paginated && page <= maxFragmentPages; page++ {
+		a.letterboxdLimiter.wait() // Rate limiting
+		before := len(movies)
+		found, notFound, err := a.scrapeFragment(forPage(rules.URLs.WatchlistFragment, username, page), rules, movies)
+		end, err := fragmentPageEnd(page, found, len(movies)-before, notFound, err)
+		if err != nil {
+			return userProfile{}, nil, fmt.Errorf("could not load poster grid fragment %d for '%s': %v", page, username, err)
+		}
+		if end {
+			return profile, movies, nil
+		}
+	}
+	return profile, movies, nil
+}
+
+// fragmentPageEnd reports whether a watchlist's poster grid fragments end
+// at a page, given how many posters it showed, how many of those were new
+// and whether it was missing. A page that failed to load is an error, not
+// the end, as is an empty second page, since the first page linked to it
+func fragmentPageEnd(page int, found int, added int, notFound bool, err error) (bool, error) {
+	switch {
+	case err != nil:
+		return true, err
+	case page == 2 && (notFound || found == 0):
+		return true, fmt.Errorf("the endpoint showed no posters")
+	case notFound || found == 0 || added == 0:
+		// Past the last page
+		return true, nil
+	}
+	return false, nil
+}
+
+// scrapeFragment adds the films of a poster grid fragment to movies,
+// returning how many it showed and whether the page doesn't exist
+func (a *App) scrapeFragment(fragmentURL string, rules Ruleset, movies map[string]WatchlistEntry) (int, bool, error) {
+	found := 0
+	notFound := false
+	var scrapeErr error
+
+	c := a.newCollector()
+	onPosters(c, rules, movies)
+	c.OnHTML(rules.Selectors.Poster, func(e *colly.HTMLElement) {
+		found++
+	})
+	c.OnError(func(r *colly.Response, e error) {
+		if r.StatusCode == http.StatusNotFound {
+			notFound = true
+			return
+		}
+		a.metrics.countError("scrape")
+		scrapeErr = e
+	})
+	if err := c.Visit(fragmentURL); err != nil && scrapeErr == nil && !notFound {
+		scrapeErr = err
+	}
+	return found, notFound, scrapeErr
+}
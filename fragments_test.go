@@ -0,0 +1,38 @@
+package main
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestFragmentPageEnd(t *testing.T) {
+	failed := errors.New("connection reset")
+	tests := []struct {
+		name     string
+		page     int
+		found    int
+		added    int
+		notFound bool
+		err      error
+		wantEnd  bool
+		wantErr  bool
+	}{
+		{name: "full page", page: 3, found: 28, added: 28},
+		{name: "short last page", page: 3, found: 5, added: 5},
+		{name: "missing page", page: 3, notFound: true, wantEnd: true},
+		{name: "empty page", page: 3, wantEnd: true},
+		{name: "repeated page", page: 3, found: 28, wantEnd: true},
+		{name: "failed page", page: 3, err: failed, wantEnd: true, wantErr: true},
+		{name: "failed second page", page: 2, err: failed, wantEnd: true, wantErr: true},
+		{name: "missing second page", page: 2, notFound: true, wantEnd: true, wantErr: true},
+		{name: "empty second page", page: 2, wantEnd: true, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			end, err := fragmentPageEnd(tt.page, tt.found, tt.added, tt.notFound, tt.err)
+			if end != tt.wantEnd || (err != nil) != tt.wantErr {
+				t.Errorf("fragmentPageEnd() = %v, %v; want end %v, error %v", end, err, tt.wantEnd, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+)
+
+// FranchiseEntry is a released film of a TMDB collection
+type FranchiseEntry struct {
+	TMDBID      int    `json:"tmdb_id"`
+	Title       string `json:"title"`
+	ReleaseDate string `json:"release_date"`
+	ReleaseYear string `json:"release_year"`
+	// Picked marks the film the report was made for
+	Picked bool     `json:"picked"`
+	SeenBy []string `json:"seen_by"`
+}
+
+// FranchiseViewer is a participant's progress through a collection
+type FranchiseViewer struct {
+	Username string `json:"username"`
+	Seen     int    `json:"seen"`
+	// CatchUp lists the earlier entries the participant hasn't seen, in
+	// release order
+	CatchUp []FranchiseEntry `json:"catch_up"`
+}
+
+// FranchiseReport tells the group whether a picked film needs catch-up
+// viewing of the collection it belongs to
+type FranchiseReport struct {
+	CollectionID int               `json:"collection_id"`
+	Name         string            `json:"name"`
+	Entries      []FranchiseEntry  `json:"entries"`
+	Viewers      []FranchiseViewer `json:"viewers"`
+	// NeedsCatchUp is set when any participant is missing an earlier entry
+	NeedsCatchUp bool `json:"needs_catch_up"`
+}
+
+// tmdbCollection represents a TMDB collection response
+type tmdbCollection struct {
+	ID    int    `json:"id"`
+	Name  string `json:"name"`
+	Parts []struct {
+		ID          int    `json:"id"`
+		Title       string `json:"title"`
+		ReleaseDate string `json:"release_date"`
+	} `json:"parts"`
+}
+
+// CheckFranchise reports which entries of the picked film's TMDB collection
+// each participant has seen, and which earlier ones they'd need to catch up
+// on first; participants whose watched films can't be scraped are left out
+func (a *App) CheckFranchise(movie Movie, usernames []string) (FranchiseReport, error) {
+	var report FranchiseReport
+	if movie.TMDBID == 0 {
+		return report, fmt.Errorf("no TMDB match for '%s' to find its collection with", movie.Title)
+	}
+
+	a.tmdbLimiter.wait()
+	details, err := a.fetchTMDBDetails(movie.TMDBID, "")
+	if err != nil {
+		return report, err
+	}
+	if details.BelongsToCollection == nil {
+		return report, fmt.Errorf("'%s' is not part of a collection", movie.Title)
+	}
+	collection, err := a.fetchCollection(details.BelongsToCollection.ID)
+	if err != nil {
+		return report, err
+	}
+	report.CollectionID = collection.ID
+	report.Name = collection.Name
+
+	today := time.Now().Format("2006-01-02")
+	for _, part := range collection.Parts {
+		if len(part.ReleaseDate) < 10 || part.ReleaseDate > today {
+			continue
+		}
+		report.Entries = append(report.Entries, FranchiseEntry{
+			TMDBID:      part.ID,
+			Title:       part.Title,
+			ReleaseDate: part.ReleaseDate,
+			ReleaseYear: part.ReleaseDate[:4],
+			Picked:      part.ID == movie.TMDBID,
+		})
+	}
+	sort.SliceStable(report.Entries, func(i, j int) bool {
+		return report.Entries[i].ReleaseDate < report.Entries[j].ReleaseDate
+	})
+
+	watched := a.scrapeWatchedLists(usernames)
+	var viewers []string
+	for _, username := range usernames {
+		if _, ok := watched[username]; ok {
+			viewers = append(viewers, username)
+		}
+	}
+	aliases, err := a.GetAliases()
+	if err != nil {
+		return report, err
+	}
+	for i := range report.Entries {
+		for _, username := range viewers {
+			if seenEntry(aliases, report.Entries[i], watched[username]) {
+				report.Entries[i].SeenBy = append(report.Entries[i].SeenBy, username)
+			}
+		}
+	}
+
+	for _, username := range viewers {
+		viewer := FranchiseViewer{Username: username}
+		earlier := true
+		for _, entry := range report.Entries {
+			if entry.Picked {
+				earlier = false
+			}
+			if slices.Contains(entry.SeenBy, username) {
+				viewer.Seen++
+			} else if earlier {
+				viewer.CatchUp = append(viewer.CatchUp, entry)
+			}
+		}
+		if len(viewer.CatchUp) > 0 {
+			report.NeedsCatchUp = true
+		}
+		report.Viewers = append(report.Viewers, viewer)
+	}
+	return report, nil
+}
+
+// seenEntry reports whether a collection entry is among a user's watched
+// films
+func seenEntry(aliases map[string]Alias, entry FranchiseEntry, films map[string]WatchedFilm) bool {
+	for _, film := range films {
+		if sameFilm(aliases, entry.TMDBID, entry.Title, entry.ReleaseYear, film.Title, film.URL) {
+			return true
+		}
+	}
+	return false
+}
+
+// fetchCollection gets a TMDB collection with its parts
+func (a *App) fetchCollection(collectionID int) (tmdbCollection, error) {
+	var collection tmdbCollection
+	apiKey := a.getTMDBAPIKey()
+	if apiKey == "" || len(apiKey) < 10 {
+		return collection, fmt.Errorf("TMDB API key not configured")
+	}
+
+	a.tmdbLimiter.wait()
+	resp, err := a.tmdbGet(fmt.Sprintf("https://api.themoviedb.org/3/collection/%d?api_key=%s", collectionID, apiKey))
+	if err != nil {
+		return collection, fmt.Errorf("failed to get collection: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 {
+		return collection, fmt.Errorf("collection API error: %v", parseTMDBError(resp))
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&collection); err != nil {
+		return collection, fmt.Errorf("failed to parse collection: %v", err)
+	}
+	return collection, nil
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// FriendRating is a rating left on a film by someone the signed-in user follows
+type FriendRating struct {
+	Username string  `json:"username"`
+	Name     string  `json:"name"`
+	Rating   float64 `json:"rating"`
+}
+
+// GetFriendRatings scrapes the ratings of a film by people the signed-in
+// Letterboxd user follows; it requires a configured session
+func (a *App) GetFriendRatings(filmURL string) ([]FriendRating, error) {
+	session, err := a.letterboxdSession()
+	if err != nil {
+		return nil, err
+	}
+	defer a.finishSession(session)
+
+	defer a.metrics.since("friends_scrape", time.Now())
+
+	// Participants already appear on the result, so they're not repeated here
+	a.mu.Lock()
+	participants := make(map[string]bool, len(a.watchlists))
+	for user := range a.watchlists {
+		participants[strings.ToLower(user)] = true
+	}
+	a.mu.Unlock()
+
+	// Friend activity depends on who is signed in, so it is loaded through
+	// the session rather than the shared page cache
+	friendsURL := strings.TrimSuffix(filmURL, "/") + "/friends/"
+	_, page, err := session.Page(friendsURL)
+	if err != nil {
+		a.metrics.countError("scrape")
+		return nil, fmt.Errorf("could not visit friend activity for '%s': %v", filmURL, err)
+	}
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(page))
+	if err != nil {
+		return nil, fmt.Errorf("could not parse friend activity for '%s': %v", filmURL, err)
+	}
+
+	selectors := a.ruleset().Selectors
+	var ratings []FriendRating
+	doc.Find(selectors.FriendRow).Each(func(_ int, row *goquery.Selection) {
+		class, _ := row.Find(selectors.Rating).First().Attr("class")
+		href, _ := row.Find(selectors.FriendName).First().Attr("href")
+		rating := parseRatingClass(class)
+		if rating == 0 || href == "" {
+			return
+		}
+		username := strings.Trim(href, "/")
+		if participants[strings.ToLower(username)] {
+			return
+		}
+		ratings = append(ratings, FriendRating{
+			Username: username,
+			Name:     strings.TrimSpace(row.Find(selectors.FriendName).Text()),
+			Rating:   rating,
+		})
+	})
+
+	return ratings, nil
+}
@@ -1,11 +1,26 @@
 import './style.css';
 import './app.css';
 
-import { FindCommonMovies, SetTMDBAPIKey } from '../wailsjs/go/main/App';
+import { StartSession, GetResultsPageWithQuery, HydrateSessionMovie, CloseSession, SetTMDBAPIKey } from '../wailsjs/go/main/App';
 
 // Global variables for managing state
-let currentMovies = [];
+let currentSession = null;
 let currentSort = 'count';
+let hydrationRun = 0;
+
+// Number of movie details requested from the backend at once
+const HYDRATION_CONCURRENCY = 4;
+
+// Number of movies fetched per results page
+const PAGE_SIZE = 200;
+
+// Result queries for each sort button; "count" keeps the comparison order
+const SORT_QUERIES = {
+    count: {},
+    rating: { sort_by: 'rating', descending: true },
+    runtime: { sort_by: 'runtime', descending: true },
+    year: { sort_by: 'year', descending: true },
+};
 
 // DOM Elements
 const form = document.getElementById('matcher-form');
@@ -36,7 +51,10 @@ form.addEventListener('submit', async function(e) {
     const apiKey = document.getElementById('tmdb-api-key').value.trim();
     if (apiKey) {
         try {
-            await SetTMDBAPIKey(apiKey);
+            const validation = await SetTMDBAPIKey(apiKey);
+            if (!validation.valid) {
+                console.log('Note: TMDB API key was not saved:', validation.message);
+            }
         } catch (error) {
             console.log('Note: Could not set API key in backend:', error);
         }
@@ -48,13 +66,15 @@ form.addEventListener('submit', async function(e) {
     loaderContainer.style.display = 'flex';
     
     try {
-        // Call Go backend function
-        const movies = await FindCommonMovies(usernames);
+        // Start a comparison session in the Go backend
+        await closeCurrentSession();
+        currentSession = await StartSession(usernames, {});
         
-        if (movies && movies.length > 0) {
-            currentMovies = movies;
+        if (currentSession.total > 0) {
+            const movies = await loadResults(currentSession.id, currentSort);
             displayMovies(movies);
             showResults();
+            hydrateMovies(currentSession.id, movies);
         } else {
             showNoResults(usernames);
         }
@@ -65,6 +85,65 @@ form.addEventListener('submit', async function(e) {
     }
 });
 
+// Fetch every page of a session's results in the given sort order
+async function loadResults(sessionID, sortKey) {
+    const movies = [];
+    for (let page = 1; ; page++) {
+        const result = await GetResultsPageWithQuery(sessionID, page, PAGE_SIZE, SORT_QUERIES[sortKey]);
+        movies.push(...(result.movies || []));
+        if (page >= result.total_pages) return movies;
+    }
+}
+
+// Close the current session, if any, and stop hydrating its movies
+async function closeCurrentSession() {
+    hydrationRun++;
+    if (!currentSession) return;
+    const sessionID = currentSession.id;
+    currentSession = null;
+    try {
+        await CloseSession(sessionID);
+    } catch (error) {
+        console.log('Could not close session:', error);
+    }
+}
+
+// Fetch details for each movie in the background, keeping them in the
+// session, and refresh its card
+async function hydrateMovies(sessionID, movies) {
+    const run = ++hydrationRun;
+    const queue = [...movies];
+
+    const worker = async () => {
+        while (queue.length > 0 && run === hydrationRun) {
+            const movie = queue.shift();
+            try {
+                const details = await HydrateSessionMovie(sessionID, movie.key);
+                if (run !== hydrationRun) return;
+                Object.assign(movie, details);
+                refreshMovieCard(movie);
+            } catch (error) {
+                console.log(`Could not load details for ${movie.title}:`, error);
+            }
+        }
+    };
+
+    await Promise.all(Array.from({ length: HYDRATION_CONCURRENCY }, worker));
+
+    // Once every rating is known the session has reordered equal overlaps
+    // by composite score
+    if (run === hydrationRun) {
+        displayMovies(await loadResults(sessionID, currentSort));
+    }
+}
+
+// Re-render the card for a movie once its details arrive
+function refreshMovieCard(movie) {
+    const existing = movieList.querySelector(`[data-url="${CSS.escape(movie.url)}"]`);
+    if (!existing) return;
+    existing.replaceWith(createMovieCard(movie, existing.dataset.index));
+}
+
 // Display movies in the UI
 function displayMovies(movies) {
     movieList.innerHTML = '';
@@ -75,15 +154,22 @@ function displayMovies(movies) {
     });
 }
 
+// Label a user by display name and watchlist size, e.g. "Sam (324 films on watchlist)"
+function userLabel(user) {
+    const name = user.display_name || user.name;
+    return user.watchlist_size ? `${name} (${user.watchlist_size} films on watchlist)` : name;
+}
+
 // Create individual movie card
 function createMovieCard(movie, index) {
     const li = document.createElement('li');
     li.className = 'movie-card';
     li.dataset.index = index;
+    li.dataset.url = movie.url;
     
     // User avatars
     const userAvatarsHtml = movie.users.map(user => 
-        `<img class="user-avatar" src="${user.avatar}" title="${user.name}">`
+        `<img class="user-avatar" src="${user.avatar}" title="${userLabel(user)}">`
     ).join('');
     
     // Genres (limit to first 3)
@@ -246,7 +332,7 @@ function openMoviePanel(movie) {
             
             const name = document.createElement('span');
             name.className = 'panel-user-name';
-            name.textContent = user.name;
+            name.textContent = userLabel(user);
 
             userLink.appendChild(avatar);
             userLink.appendChild(name);
@@ -316,6 +402,7 @@ function hideError() {
 
 // Reset application
 window.resetApp = function() {
+    closeCurrentSession();
     resetToMainScreen();
     closePanel();
     hideError();
@@ -323,7 +410,7 @@ window.resetApp = function() {
 };
 
 // Sort functionality
-document.getElementById('sort-controls').addEventListener('click', function(e) {
+document.getElementById('sort-controls').addEventListener('click', async function(e) {
     const button = e.target.closest('.sort-button');
     if (!button) return;
 
@@ -334,21 +421,14 @@ document.getElementById('sort-controls').addEventListener('click', function(e) {
     document.querySelectorAll('.sort-button').forEach(btn => btn.classList.remove('active'));
     button.classList.add('active');
 
-    // Sort movies
-    const sortedMovies = [...currentMovies].sort((a, b) => {
-        if (sortKey === 'year') {
-            return b.release_date.localeCompare(a.release_date);
-        } else if (sortKey === 'count') {
-            if (a.count !== b.count) {
-                return b.count - a.count;
-            }
-            return b.rating - a.rating;
-        } else {
-            return b[sortKey] - a[sortKey];
-        }
-    });
-
-    displayMovies(sortedMovies);
+    // Fetch the movies in the new order from the session
+    if (!currentSession) return;
+    try {
+        displayMovies(await loadResults(currentSession.id, sortKey));
+    } catch (error) {
+        console.error('Error sorting movies:', error);
+        showError(error.toString().replace('Error: ', ''));
+    }
 });
 
 // Panel backdrop click handler
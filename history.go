@@ -0,0 +1,124 @@
+package main
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Watchlist change kinds
+const (
+	WatchlistAdded   = "added"
+	WatchlistRemoved = "removed"
+)
+
+// maxWatchlistChanges caps the change feed kept per user
+const maxWatchlistChanges = 500
+
+// unsafeNameRegex matches characters not allowed in store document names
+var unsafeNameRegex = regexp.MustCompile(`[^a-z0-9_-]`)
+
+// WatchlistChange is a film added to or removed from a user's watchlist
+// between two refreshes
+type WatchlistChange struct {
+	Title  string    `json:"title"`
+	URL    string    `json:"url"`
+	Change string    `json:"change"`
+	At     time.Time `json:"at"`
+}
+
+// watchlistHistory is a user's last scraped watchlist and the changes
+// detected across refreshes, oldest first
+type watchlistHistory struct {
+	Snapshot  map[string]WatchlistEntry `json:"snapshot"`
+	CheckedAt time.Time                 `json:"checked_at"`
+	Changes   []WatchlistChange         `json:"changes"`
+}
+
+// historyName returns the store document holding a user's watchlist history
+func historyName(username string) string {
+	return "watchlist-" + unsafeNameRegex.ReplaceAllString(strings.ToLower(username), "_")
+}
+
+// GetWatchlistChanges returns the films added to or removed from a user's
+// watchlist since tracking began, newest first
+func (a *App) GetWatchlistChanges(username string) ([]WatchlistChange, error) {
+	a.historyMu.Lock()
+	defer a.historyMu.Unlock()
+
+	var history watchlistHistory
+	if err := a.store.load(historyName(username), &history); err != nil {
+		return nil, err
+	}
+
+	changes := make([]WatchlistChange, len(history.Changes))
+	for i, change := range history.Changes {
+		changes[len(changes)-1-i] = change
+	}
+	return changes, nil
+}
+
+// recordWatchlistSnapshot compares a freshly scraped watchlist with the
+// previous one, appending any differences to the user's change feed; the
+// first snapshot only sets the baseline
+func (a *App) recordWatchlistSnapshot(username string, movies map[string]WatchlistEntry) ([]WatchlistChange, error) {
+	a.historyMu.Lock()
+	defer a.historyMu.Unlock()
+
+	name := historyName(username)
+	var history watchlistHistory
+	if err := a.store.load(name, &history); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	var changes []WatchlistChange
+	if history.Snapshot != nil {
+		changes = diffWatchlists(history.Snapshot, movies, now)
+	}
+
+	history.Changes = append(history.Changes, changes...)
+	if len(history.Changes) > maxWatchlistChanges {
+		history.Changes = history.Changes[len(history.Changes)-maxWatchlistChanges:]
+	}
+	history.Snapshot = movies
+	history.CheckedAt = now
+
+	return changes, a.store.save(name, history)
+}
+
+// diffWatchlists lists the films added and removed between two watchlists,
+// which are keyed by Letterboxd film slug so retitled entries aren't reported
+func diffWatchlists(before, after map[string]WatchlistEntry, at time.Time) []WatchlistChange {
+	index := func(movies map[string]WatchlistEntry) map[string]WatchlistChange {
+		byKey := make(map[string]WatchlistChange, len(movies))
+		for key, film := range movies {
+			byKey[key] = WatchlistChange{Title: film.Title, URL: film.URL, At: at}
+		}
+		return byKey
+	}
+	old, current := index(before), index(after)
+
+	var changes []WatchlistChange
+	for key, change := range current {
+		if _, ok := old[key]; !ok {
+			change.Change = WatchlistAdded
+			changes = append(changes, change)
+		}
+	}
+	for key, change := range old {
+		if _, ok := current[key]; !ok {
+			change.Change = WatchlistRemoved
+			changes = append(changes, change)
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		if changes[i].Change != changes[j].Change {
+			return changes[i].Change < changes[j].Change
+		}
+		return changes[i].Title < changes[j].Title
+	})
+	return changes
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"net/http"
+	"time"
+)
+
+// sharedTransport pools connections to TMDB, Letterboxd and image hosts
+// across requests. Go's default transport keeps only two idle connections
+// per host, so parallel enrichment kept reopening TLS connections
+var sharedTransport = newSharedTransport()
+
+// sharedClient is the tuned client for one-off requests such as poster
+// downloads
+var sharedClient = &http.Client{Transport: sharedTransport, Timeout: 30 * time.Second}
+
+// newSharedTransport creates the pooled transport. Responses are requested
+// gzip-compressed and decompressed transparently, and HTTP/2 is used where
+// the server supports it
+func newSharedTransport() *http.Transport {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.MaxIdleConns = 100
+	// Enough for every enrichment worker plus a few scrapes in flight
+	t.MaxIdleConnsPerHost = 4 * enrichWorkers
+	t.IdleConnTimeout = 90 * time.Second
+	t.TLSHandshakeTimeout = 10 * time.Second
+	t.ResponseHeaderTimeout = 20 * time.Second
+	t.ExpectContinueTimeout = time.Second
+	t.ForceAttemptHTTP2 = true
+	return t
+}
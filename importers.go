@@ -0,0 +1,317 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ImportedList is a watchlist imported from another service, normalized to
+// the film-keyed watchlist format used by the matcher
+type ImportedList struct {
+	Key         string                    `json:"key"`
+	Source      string                    `json:"source"`
+	Participant string                    `json:"participant"`
+	Entries     map[string]WatchlistEntry `json:"entries"`
+	ImportedAt  time.Time                 `json:"imported_at"`
+}
+
+// importedEntry is a film read from an export before normalization
+type importedEntry struct {
+	Title  string
+	Year   int
+	IMDBID string
+	TMDBID string
+}
+
+// simklItems is the shape of Simkl's /sync/all-items response and JSON backups
+type simklItems struct {
+	Movies []struct {
+		Status string `json:"status"`
+		Movie  struct {
+			Title string `json:"title"`
+			Year  int    `json:"year"`
+			IDs   struct {
+				IMDB string          `json:"imdb"`
+				TMDB json.RawMessage `json:"tmdb"`
+			} `json:"ids"`
+		} `json:"movie"`
+	} `json:"movies"`
+}
+
+// watchlistStatuses are the export statuses treated as "want to watch"
+var watchlistStatuses = map[string]bool{
+	"plantowatch": true,
+	"watchlist":   true,
+	"to_watch":    true,
+	"towatch":     true,
+	"want_to_see": true,
+}
+
+// ImportSimklFile imports a Simkl JSON backup or CSV export as a participant
+// and returns the participant key to use in comparisons
+func (a *App) ImportSimklFile(participant string, path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("could not read Simkl export: %v", err)
+	}
+
+	var entries []importedEntry
+	if strings.EqualFold(filepath.Ext(path), ".csv") {
+		entries, err = parseCSVExport(strings.NewReader(string(data)))
+	} else {
+		entries, err = parseSimklJSON(data)
+	}
+	if err != nil {
+		return "", err
+	}
+	return a.saveImport("simkl", participant, entries)
+}
+
+// ImportSimklAPI fetches a Simkl user's plan-to-watch movies through the API
+func (a *App) ImportSimklAPI(participant string, clientID string, accessToken string) (string, error) {
+	req, err := http.NewRequest(http.MethodGet, "https://api.simkl.com/sync/all-items/movies/plantowatch", nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("simkl-api-key", clientID)
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+
+	resp, err := sharedClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("could not reach Simkl: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 {
+		return "", fmt.Errorf("Simkl API error: status code %d", resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("could not read Simkl response: %v", err)
+	}
+	entries, err := parseSimklJSON(data)
+	if err != nil {
+		return "", err
+	}
+	return a.saveImport("simkl", participant, entries)
+}
+
+// ImportTVTimeFile imports a TV Time data export CSV as a participant
+func (a *App) ImportTVTimeFile(participant string, path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("could not read TV Time export: %v", err)
+	}
+	defer f.Close()
+
+	entries, err := parseCSVExport(f)
+	if err != nil {
+		return "", err
+	}
+	return a.saveImport("tvtime", participant, entries)
+}
+
+// ListImports returns all imported lists
+func (a *App) ListImports() ([]ImportedList, error) {
+	imports, err := a.loadImports()
+	if err != nil {
+		return nil, err
+	}
+	list := make([]ImportedList, 0, len(imports))
+	for _, imported := range imports {
+		list = append(list, imported)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Key < list[j].Key })
+	return list, nil
+}
+
+// RemoveImport deletes an imported list
+func (a *App) RemoveImport(key string) error {
+	imports, err := a.loadImports()
+	if err != nil {
+		return err
+	}
+	delete(imports, key)
+	return a.store.save("imports", imports)
+}
+
+// importedList returns the imported list for a participant key such as
+// "simkl:sam"; Letterboxd usernames never contain a colon
+func (a *App) importedList(participant string) (ImportedList, bool) {
+	if !strings.Contains(participant, ":") {
+		return ImportedList{}, false
+	}
+	imports, err := a.loadImports()
+	if err != nil {
+		return ImportedList{}, false
+	}
+	imported, ok := imports[participant]
+	return imported, ok
+}
+
+// loadImports reads the imported lists keyed by participant key
+func (a *App) loadImports() (map[string]ImportedList, error) {
+	imports := make(map[string]ImportedList)
+	if err := a.store.load("imports", &imports); err != nil {
+		return nil, err
+	}
+	return imports, nil
+}
+
+// saveImport normalizes entries and stores them under "source:participant"
+func (a *App) saveImport(source string, participant string, entries []importedEntry) (string, error) {
+	participant = strings.TrimSpace(participant)
+	if participant == "" {
+		return "", fmt.Errorf("no participant name provided")
+	}
+	if len(entries) == 0 {
+		return "", fmt.Errorf("no watchlist movies found in %s export", source)
+	}
+
+	imported := ImportedList{
+		Key:         source + ":" + participant,
+		Source:      source,
+		Participant: participant,
+		Entries:     make(map[string]WatchlistEntry, len(entries)),
+		ImportedAt:  time.Now(),
+	}
+	for _, entry := range entries {
+		filmURL := a.resolveFilmURL(entry.letterboxdURL())
+		imported.Entries[movieKey(filmURL)] = WatchlistEntry{Title: entry.Title, URL: filmURL}
+	}
+
+	imports, err := a.loadImports()
+	if err != nil {
+		return "", err
+	}
+	imports[imported.Key] = imported
+	if err := a.store.save("imports", imports); err != nil {
+		return "", err
+	}
+	return imported.Key, nil
+}
+
+// letterboxdURL points at the entry's Letterboxd film page, using Letterboxd's
+// TMDB/IMDb redirect routes when an ID is known
+func (e importedEntry) letterboxdURL() string {
+	switch {
+	case e.TMDBID != "":
+		return fmt.Sprintf("https://letterboxd.com/tmdb/%s/", e.TMDBID)
+	case e.IMDBID != "":
+		return fmt.Sprintf("https://letterboxd.com/imdb/%s/", e.IMDBID)
+	default:
+		return fmt.Sprintf("https://letterboxd.com/search/films/%s/", url.PathEscape(e.Title))
+	}
+}
+
+// resolveFilmURL follows Letterboxd's TMDB/IMDb redirect routes to the
+// canonical /film/<slug>/ page so imported entries intersect by slug like
+// scraped ones; the original URL is kept if it can't be resolved
+func (a *App) resolveFilmURL(filmURL string) string {
+	if !strings.Contains(filmURL, "/tmdb/") && !strings.Contains(filmURL, "/imdb/") {
+		return filmURL
+	}
+
+	a.letterboxdLimiter.wait()
+	resp, err := sharedClient.Get(filmURL)
+	if err != nil {
+		return filmURL
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != 200 || !strings.Contains(resp.Request.URL.Path, "/film/") {
+		return filmURL
+	}
+	return a.ruleset().URLs.link(resp.Request.URL.Path)
+}
+
+// parseSimklJSON reads plan-to-watch movies from Simkl's JSON format
+func parseSimklJSON(data []byte) ([]importedEntry, error) {
+	var items simklItems
+	if err := json.Unmarshal(data, &items); err != nil {
+		return nil, fmt.Errorf("could not parse Simkl export: %v", err)
+	}
+
+	var entries []importedEntry
+	for _, item := range items.Movies {
+		if item.Status != "" && !watchlistStatuses[item.Status] {
+			continue
+		}
+		if item.Movie.Title == "" {
+			continue
+		}
+		entries = append(entries, importedEntry{
+			Title:  item.Movie.Title,
+			Year:   item.Movie.Year,
+			IMDBID: item.Movie.IDs.IMDB,
+			TMDBID: strings.Trim(string(item.Movie.IDs.TMDB), `"`),
+		})
+	}
+	return entries, nil
+}
+
+// parseCSVExport reads watchlist movies from a CSV export by header name,
+// which covers both Simkl's and TV Time's CSV layouts
+func parseCSVExport(r io.Reader) ([]importedEntry, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("could not read CSV header: %v", err)
+	}
+	columns := make(map[string]int)
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	column := func(record []string, names ...string) string {
+		for _, name := range names {
+			if i, ok := columns[name]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+		}
+		return ""
+	}
+
+	var entries []importedEntry
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("could not parse CSV export: %v", err)
+		}
+
+		if status := strings.ToLower(column(record, "status", "watchlist", "list")); status != "" && !watchlistStatuses[strings.ReplaceAll(status, " ", "_")] {
+			continue
+		}
+		if kind := strings.ToLower(column(record, "type", "entity_type")); kind != "" && kind != "movie" && kind != "movies" {
+			continue
+		}
+
+		entry := importedEntry{
+			Title:  column(record, "title", "movie_name", "name"),
+			IMDBID: column(record, "imdb", "imdb_id", "imdbid"),
+			TMDBID: column(record, "tmdb", "tmdb_id", "tmdbid"),
+		}
+		year := column(record, "year", "release_year", "release_date")
+		if len(year) >= 4 {
+			entry.Year, _ = strconv.Atoi(year[:4])
+		}
+		if entry.Title != "" {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, nil
+}
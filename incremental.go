@@ -0,0 +1,116 @@
+package main
+
+import (
+	"sort"
+	"time"
+
+	"github.com/gocolly/colly/v2"
+)
+
+// groupIntersection is a group's intersection from its last comparison,
+// together with the watchlists it was computed from
+type groupIntersection struct {
+	watchlists map[string]map[string]WatchlistEntry
+	entries    map[string]*commonEntry
+}
+
+// intersectIncrementally updates the group's cached intersection with only
+// the watchlists that changed since its last comparison, falling back to a
+// full intersection for a new group; cached entries are never modified in
+// place, so results handed out earlier stay valid
+func (a *App) intersectIncrementally(usernames []string, watchlists map[string]map[string]WatchlistEntry) map[string]*commonEntry {
+	_, key := groupKey(usernames)
+
+	a.mu.Lock()
+	cached, ok := a.intersections[key]
+	a.mu.Unlock()
+
+	var entries map[string]*commonEntry
+	if !ok || len(cached.watchlists) != len(watchlists) {
+		entries = intersectWatchlists(watchlists)
+	} else {
+		entries = make(map[string]*commonEntry, len(cached.entries))
+		for k, entry := range cached.entries {
+			entries[k] = entry
+		}
+		for user, movies := range watchlists {
+			before, known := cached.watchlists[user]
+			if !known {
+				entries = intersectWatchlists(watchlists)
+				break
+			}
+			for _, change := range diffWatchlists(before, movies, time.Now()) {
+				applyWatchlistChange(entries, user, change)
+			}
+		}
+	}
+
+	a.mu.Lock()
+	if a.intersections == nil {
+		a.intersections = make(map[string]groupIntersection)
+	}
+	a.intersections[key] = groupIntersection{watchlists: watchlists, entries: entries}
+	a.mu.Unlock()
+
+	return entries
+}
+
+// applyWatchlistChange adds or removes one user from a film's entry,
+// replacing the entry rather than modifying it
+func applyWatchlistChange(entries map[string]*commonEntry, user string, change WatchlistChange) {
+	key := movieKey(change.URL)
+	updated := &commonEntry{Title: change.Title, URL: change.URL}
+	if entry, ok := entries[key]; ok {
+		updated.Title, updated.URL = entry.Title, entry.URL
+		for _, u := range entry.Users {
+			if u != user {
+				updated.Users = append(updated.Users, u)
+			}
+		}
+	}
+
+	if change.Change == WatchlistAdded {
+		updated.Users = append(updated.Users, user)
+		sort.Strings(updated.Users)
+	}
+
+	if len(updated.Users) == 0 {
+		delete(entries, key)
+		return
+	}
+	entries[key] = updated
+}
+
+// unchangedWatchlist revalidates only the first page of a user's watchlist
+// and, if it is unchanged since the last scrape, returns the previous
+// snapshot instead of re-scraping every page; any addition or removal
+// changes the film count in the first page's header
+func (a *App) unchangedWatchlist(username string) (userProfile, map[string]WatchlistEntry, bool) {
+	a.historyMu.Lock()
+	var history watchlistHistory
+	err := a.store.load(historyName(username), &history)
+	a.historyMu.Unlock()
+	if err != nil || len(history.Snapshot) == 0 {
+		return userProfile{}, nil, false
+	}
+
+	c := a.newCollector()
+	rules := a.ruleset()
+	var profile userProfile
+	onProfileHeader(c, rules.Selectors, &profile)
+
+	cached := false
+	c.OnResponse(func(r *colly.Response) {
+		cached = r.Headers.Get(cacheStatusHeader) == "hit"
+	})
+
+	if err := c.Visit(forUser(rules.URLs.Watchlist, username)); err != nil || !cached {
+		return userProfile{}, nil, false
+	}
+
+	if profile.WatchlistSize == 0 {
+		profile.WatchlistSize = len(history.Snapshot)
+	}
+	a.refreshes.watchlistRefreshed(username)
+	return profile, history.Snapshot, true
+}
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultKodiPort is the port of Kodi's web server unless configured otherwise
+const defaultKodiPort = 8080
+
+// KodiSettings configures the Kodi instance checked for local copies
+type KodiSettings struct {
+	Host     string `json:"host"`
+	Port     int    `json:"port"`
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// kodiMovie is a movie in Kodi's video library
+type kodiMovie struct {
+	MovieID    int               `json:"movieid"`
+	Title      string            `json:"title"`
+	IMDbNumber string            `json:"imdbnumber"`
+	UniqueID   map[string]string `json:"uniqueid"`
+}
+
+// imdbID returns the IMDb ID Kodi's scraper stored for the movie
+func (m kodiMovie) imdbID() string {
+	if id := m.UniqueID["imdb"]; id != "" {
+		return id
+	}
+	return m.IMDbNumber
+}
+
+// SetKodi stores the Kodi host, port and web server credentials; an empty
+// host disables the integration
+func (a *App) SetKodi(kodi KodiSettings) error {
+	kodi.Host = strings.TrimSpace(kodi.Host)
+	if kodi.Port < 0 || kodi.Port > 65535 {
+		return fmt.Errorf("invalid Kodi port %d", kodi.Port)
+	}
+	settings := a.loadSettings()
+	settings.Kodi = kodi
+	return a.saveSettings(settings)
+}
+
+// KodiAvailability reports, by movie key, which movies are in the Kodi
+// library, matching them by IMDb ID
+func (a *App) KodiAvailability(movies []Movie) (map[string]bool, error) {
+	library, err := a.kodiLibrary()
+	if err != nil {
+		return nil, err
+	}
+	available := make(map[string]bool, len(movies))
+	for _, movie := range movies {
+		if movie.IMDBID != "" {
+			_, available[movie.Key] = library[movie.IMDBID]
+		}
+	}
+	return available, nil
+}
+
+// PlayOnKodi starts playback of a movie from the Kodi library
+func (a *App) PlayOnKodi(movie Movie) error {
+	if movie.IMDBID == "" {
+		return fmt.Errorf("no IMDb ID for '%s' to find it in Kodi with", movie.Title)
+	}
+	library, err := a.kodiLibrary()
+	if err != nil {
+		return err
+	}
+	found, ok := library[movie.IMDBID]
+	if !ok {
+		return fmt.Errorf("'%s' is not in the Kodi library", movie.Title)
+	}
+
+	params := map[string]interface{}{"item": map[string]int{"movieid": found.MovieID}}
+	return a.kodiCall("Player.Open", params, nil)
+}
+
+// kodiLibrary returns the Kodi video library's movies keyed by IMDb ID
+func (a *App) kodiLibrary() (map[string]kodiMovie, error) {
+	var result struct {
+		Movies []kodiMovie `json:"movies"`
+	}
+	params := map[string]interface{}{"properties": []string{"title", "imdbnumber", "uniqueid"}}
+	if err := a.kodiCall("VideoLibrary.GetMovies", params, &result); err != nil {
+		return nil, err
+	}
+
+	library := make(map[string]kodiMovie, len(result.Movies))
+	for _, movie := range result.Movies {
+		if id := movie.imdbID(); id != "" {
+			library[id] = movie
+		}
+	}
+	return library, nil
+}
+
+// kodiCall invokes a Kodi JSON-RPC method and decodes its result into v
+func (a *App) kodiCall(method string, params interface{}, v interface{}) error {
+	kodi := a.loadSettings().Kodi
+	if kodi.Host == "" {
+		return fmt.Errorf("Kodi not configured")
+	}
+	port := kodi.Port
+	if port == 0 {
+		port = defaultKodiPort
+	}
+
+	body, err := json.Marshal(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      1,
+		"method":  method,
+		"params":  params,
+	})
+	if err != nil {
+		return fmt.Errorf("could not encode Kodi request: %v", err)
+	}
+	endpoint := "http://" + net.JoinHostPort(kodi.Host, strconv.Itoa(port)) + "/jsonrpc"
+	req, err := http.NewRequest(http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if kodi.Username != "" {
+		req.SetBasicAuth(kodi.Username, kodi.Password)
+	}
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("could not reach Kodi: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusUnauthorized {
+		return fmt.Errorf("Kodi rejected the username or password")
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("Kodi error: status code %d", resp.StatusCode)
+	}
+
+	var reply struct {
+		Result json.RawMessage `json:"result"`
+		Error  *struct {
+			Code    int    `json:"code"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&reply); err != nil {
+		return fmt.Errorf("failed to parse Kodi response: %v", err)
+	}
+	if reply.Error != nil {
+		return fmt.Errorf("Kodi %s failed: %s", method, reply.Error.Message)
+	}
+	if v != nil {
+		if err := json.Unmarshal(reply.Result, v); err != nil {
+			return fmt.Errorf("failed to parse Kodi %s result: %v", method, err)
+		}
+	}
+	return nil
+}
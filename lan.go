@@ -0,0 +1,192 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"html/template"
+	"log"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// lanVotePage is the minimal mobile page phones open to vote on a session
+var lanVotePage = template.Must(template.New("vote").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>Klisse vote {{.}}</title>
+<style>
+body { font-family: system-ui, sans-serif; margin: 0; padding: 1rem; background: #14181c; color: #fff; }
+input { width: 100%; box-sizing: border-box; padding: .75rem; font-size: 1rem; margin-bottom: 1rem; }
+.movie { display: flex; align-items: center; gap: .75rem; padding: .5rem 0; border-bottom: 1px solid #2c3440; }
+.movie img { width: 3rem; border-radius: 4px; }
+.movie .title { flex: 1; }
+button { font-size: 1.25rem; padding: .5rem .75rem; border: 0; border-radius: 6px; background: #2c3440; color: #fff; }
+button.on { background: #00c030; }
+button.veto.on { background: #e03030; }
+</style>
+</head>
+<body>
+<h1>Movie night vote</h1>
+<input id="voter" placeholder="Your name" autocomplete="name">
+<div id="movies">Loading…</div>
+<script>
+const code = {{.}};
+const voter = document.getElementById("voter");
+voter.value = localStorage.getItem("klisse-voter") || "";
+voter.onchange = () => { localStorage.setItem("klisse-voter", voter.value.trim()); load(); };
+
+function render(session) {
+  const list = document.getElementById("movies");
+  list.innerHTML = "";
+  const name = voter.value.trim();
+  for (const movie of session.movies) {
+    const mine = (session.votes[movie.key] || {})[name] || 0;
+    const row = document.createElement("div");
+    row.className = "movie";
+    const img = document.createElement("img");
+    img.src = movie.poster_url;
+    img.alt = "";
+    const title = document.createElement("span");
+    title.className = "title";
+    title.textContent = movie.title + (movie.release_year ? " (" + movie.release_year + ")" : "");
+    row.append(img, title, button("👍 " + movie.up, "up", mine === 1, movie.key, 1),
+      button("✕ " + movie.vetoes, "veto", mine === -1, movie.key, -1));
+    list.append(row);
+  }
+}
+
+function button(label, kind, on, key, value) {
+  const b = document.createElement("button");
+  b.className = kind + (on ? " on" : "");
+  b.textContent = label;
+  b.onclick = () => vote(key, on ? 0 : value);
+  return b;
+}
+
+async function vote(key, value) {
+  if (!voter.value.trim()) { voter.focus(); return; }
+  const res = await fetch("/api/share/" + code + "/vote", {
+    method: "POST",
+    headers: { "Content-Type": "application/json" },
+    body: JSON.stringify({ voter: voter.value.trim(), key: key, value: value }),
+  });
+  const body = await res.json();
+  if (res.ok) render(body); else alert(body.error);
+}
+
+async function load() {
+  const res = await fetch("/api/share/" + code);
+  const body = await res.json();
+  if (res.ok) render(body); else document.getElementById("movies").textContent = body.error;
+}
+
+load();
+setInterval(load, 3000);
+</script>
+</body>
+</html>
+`))
+
+// LANVoting describes the local voting server phones can join
+type LANVoting struct {
+	Code string `json:"code"`
+	URL  string `json:"url"`
+	// QRCode is a PNG data URI of URL for showing on the TV screen
+	QRCode string `json:"qr_code"`
+}
+
+// StartLANVoting serves a mobile voting page for a shared session on the
+// local network, so everyone can upvote and veto from their phones; votes
+// land in the session like any other and are emitted on "share:vote".
+// Only sessions shared from this machine are served; relayed ones are voted
+// on through the relay itself
+func (a *App) StartLANVoting(code string) (LANVoting, error) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if _, err := a.share(code); err != nil {
+		return LANVoting{}, err
+	}
+	host, err := lanAddress()
+	if err != nil {
+		return LANVoting{}, err
+	}
+
+	a.lanMu.Lock()
+	defer a.lanMu.Unlock()
+
+	if a.lanServer == nil {
+		listener, err := net.Listen("tcp", ":0")
+		if err != nil {
+			return LANVoting{}, fmt.Errorf("could not start voting server: %v", err)
+		}
+
+		mux := http.NewServeMux()
+		handleShareVotes(mux, a.share, a.vote)
+		mux.HandleFunc("/v/", func(w http.ResponseWriter, r *http.Request) {
+			code := strings.ToUpper(strings.TrimPrefix(r.URL.Path, "/v/"))
+			if _, err := a.share(code); err != nil {
+				http.NotFound(w, r)
+				return
+			}
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			if err := lanVotePage.Execute(w, code); err != nil {
+				log.Printf("Could not render voting page: %v", err)
+			}
+		})
+
+		a.lanServer = &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+		a.lanPort = listener.Addr().(*net.TCPAddr).Port
+		go func(server *http.Server) {
+			if err := server.Serve(listener); err != nil && err != http.ErrServerClosed {
+				log.Printf("Voting server stopped: %v", err)
+			}
+		}(a.lanServer)
+	}
+
+	voting := LANVoting{
+		Code: code,
+		URL:  fmt.Sprintf("http://%s/v/%s", net.JoinHostPort(host, fmt.Sprint(a.lanPort)), code),
+	}
+	if qrCode, err := a.GenerateQRCode(voting.URL); err == nil {
+		voting.QRCode = "data:image/png;base64," + qrCode
+	} else {
+		log.Printf("Could not generate voting QR code: %v", err)
+	}
+	return voting, nil
+}
+
+// StopLANVoting shuts the local voting server down
+func (a *App) StopLANVoting() error {
+	a.lanMu.Lock()
+	defer a.lanMu.Unlock()
+
+	if a.lanServer == nil {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	err := a.lanServer.Shutdown(ctx)
+	a.lanServer = nil
+	return err
+}
+
+// lanAddress returns this machine's private IPv4 address on the local network
+func lanAddress() (string, error) {
+	addrs, err := net.InterfaceAddrs()
+	if err != nil {
+		return "", fmt.Errorf("could not list network interfaces: %v", err)
+	}
+	for _, addr := range addrs {
+		ipNet, ok := addr.(*net.IPNet)
+		if !ok || ipNet.IP.IsLoopback() {
+			continue
+		}
+		if ip := ipNet.IP.To4(); ip != nil && ip.IsPrivate() {
+			return ip.String(), nil
+		}
+	}
+	return "", fmt.Errorf("not connected to a local network")
+}
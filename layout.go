@@ -0,0 +1,169 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/gocolly/colly/v2"
+)
+
+// layoutCheckTTL is how long a layout check is trusted before an empty
+// scrape runs another one
+const layoutCheckTTL = time.Hour
+
+// Selectors are the CSS selectors and attributes scraping Letterboxd
+// relies on
+type Selectors struct {
+	// Poster matches each film in a watchlist, list or diary grid
+	Poster string `json:"poster"`
+	// FilmPoster matches the element inside Poster carrying LinkAttr
+	FilmPoster string `json:"film_poster"`
+	LinkAttr   string `json:"link_attr"`
+	// PosterImage matches the image inside Poster whose alt text is the title
+	PosterImage string `json:"poster_image"`
+	// NextPage matches the link to the next page of a paginated grid
+	NextPage string `json:"next_page"`
+	// OGImage matches the Open Graph image of profiles and films
+	OGImage string `json:"og_image"`
+	// Rating matches the "rated-N" star rating inside a poster, review or
+	// friend's activity row
+	Rating string `json:"rating"`
+
+	// ProfileAvatar matches the avatar image in a user's page header, whose
+	// alt text is their display name
+	ProfileAvatar string `json:"profile_avatar"`
+	// ProfileName matches the display name in a user's page header
+	ProfileName string `json:"profile_name"`
+	// ProfileLocation matches the location on a user's profile page
+	ProfileLocation string `json:"profile_location"`
+	// WatchlistCount matches the watchlist's film count in a user's header
+	WatchlistCount string `json:"watchlist_count"`
+
+	// Review matches each review on a film's reviews page
+	Review string `json:"review"`
+	// ReviewSpoilers matches inside Review if it contains spoilers
+	ReviewSpoilers string `json:"review_spoilers"`
+	ReviewBody     string `json:"review_body"`
+	ReviewAuthor   string `json:"review_author"`
+	// ReviewLink matches the link to the review's own page
+	ReviewLink string `json:"review_link"`
+
+	// FriendRow matches each person in a film's friend activity
+	FriendRow string `json:"friend_row"`
+	// FriendName matches the link to the person's profile inside FriendRow
+	FriendName string `json:"friend_name"`
+
+	// FilmRating matches the meta tag giving a film's average rating as
+	// "3.95 out of 5"
+	FilmRating string `json:"film_rating"`
+	// FilmWatches matches the element on a film's stats whose title gives
+	// its watch count
+	FilmWatches string `json:"film_watches"`
+	// FilmTMDB matches the element of a film page carrying data-tmdb-id and
+	// data-tmdb-type
+	FilmTMDB string `json:"film_tmdb"`
+}
+
+// LayoutHealth is the outcome of checking that Letterboxd pages still
+// have the structure scraping expects
+type LayoutHealth struct {
+	CheckedAt time.Time `json:"checked_at"`
+	OK        bool      `json:"ok"`
+	// Missing names the selectors that matched nothing
+	Missing []string `json:"missing"`
+	// Error is set if the check page couldn't be loaded, in which case
+	// nothing is known about the layout
+	Error string `json:"error"`
+}
+
+// CheckLetterboxdLayout scrapes a known list and reports the selectors that
+// no longer match anything, which means Letterboxd changed its layout
+func (a *App) CheckLetterboxdLayout() LayoutHealth {
+	rules := a.ruleset()
+	selectors := rules.Selectors
+	health := LayoutHealth{CheckedAt: time.Now()}
+	c := a.newCollector()
+
+	var posters, links, titles, next, ogImages int
+	c.OnHTML(selectors.Poster, func(e *colly.HTMLElement) {
+		posters++
+		if e.ChildAttr(selectors.FilmPoster, selectors.LinkAttr) != "" {
+			links++
+		}
+		if e.ChildAttr(selectors.PosterImage, "alt") != "" {
+			titles++
+		}
+	})
+	c.OnHTML(selectors.NextPage, func(e *colly.HTMLElement) {
+		next++
+	})
+	c.OnHTML(selectors.OGImage, func(e *colly.HTMLElement) {
+		ogImages++
+	})
+	var scrapeErr error
+	c.OnError(func(r *colly.Response, e error) {
+		a.metrics.countError("scrape")
+		scrapeErr = e
+	})
+
+	if err := c.Visit(rules.URLs.LayoutCheck); err != nil && scrapeErr == nil {
+		scrapeErr = err
+	}
+	if scrapeErr != nil {
+		health.Error = fmt.Sprintf("could not load the layout check page: %v", scrapeErr)
+	} else {
+		for _, check := range []struct {
+			name  string
+			count int
+		}{
+			{selectors.Poster, posters},
+			{selectors.FilmPoster + " [" + selectors.LinkAttr + "]", links},
+			{selectors.PosterImage + " [alt]", titles},
+			{selectors.NextPage, next},
+			{selectors.OGImage, ogImages},
+		} {
+			if check.count == 0 {
+				health.Missing = append(health.Missing, check.name)
+			}
+		}
+		health.OK = len(health.Missing) == 0
+	}
+
+	a.layoutMu.Lock()
+	a.layout = health
+	a.layoutMu.Unlock()
+	return health
+}
+
+// layoutChanged returns a "Letterboxd layout changed" error if the latest
+// layout check, rerun if it is out of date, found selectors missing. It is
+// used to explain scrapes that found nothing.
+func (a *App) layoutChanged() error {
+	a.layoutMu.Lock()
+	health := a.layout
+	a.layoutMu.Unlock()
+	if time.Since(health.CheckedAt) > layoutCheckTTL {
+		health = a.CheckLetterboxdLayout()
+	}
+	if health.OK || health.Error != "" {
+		return nil
+	}
+	return fmt.Errorf("Letterboxd layout changed: %s matched nothing, so scraping needs updated selectors", strings.Join(health.Missing, ", "))
+}
+
+// checkLayoutAtStartup loads the ruleset, including the remote one, and
+// checks the layout in the background, telling the frontend on
+// "letterboxd:layout" if it changed
+func (a *App) checkLayoutAtStartup() {
+	a.ReloadRuleset()
+	health := a.CheckLetterboxdLayout()
+	switch {
+	case health.Error != "":
+		log.Printf("Skipped Letterboxd layout check: %s", health.Error)
+	case !health.OK:
+		log.Printf("Letterboxd layout changed: %s matched nothing", strings.Join(health.Missing, ", "))
+		a.emit("letterboxd:layout", health)
+	}
+}
@@ -0,0 +1,265 @@
+// Package letterboxd makes signed-in requests to Letterboxd, keeping the
+// session's cookies and the CSRF token its forms require
+package letterboxd
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// SessionCookie is the cookie identifying a signed-in user
+	SessionCookie = "letterboxd.user.CURRENT"
+	// csrfCookie holds the CSRF token, which forms must repeat as __csrf
+	csrfCookie = "com.xk72.webparts.csrf"
+	// maxPageBytes caps how much of a page is read
+	maxPageBytes = 4 << 20
+)
+
+var (
+	csrfInputRegex = regexp.MustCompile(`name="__csrf"\s+value="([^"]+)"|value="([^"]+)"\s+name="__csrf"`)
+	filmIDRegex    = regexp.MustCompile(`data-film-id="(\d+)"`)
+)
+
+// Session is a signed-in Letterboxd session. Cookies Letterboxd sets, such
+// as a renewed session or CSRF cookie, are kept for later requests.
+type Session struct {
+	base      *url.URL
+	client    *http.Client
+	userAgent string
+
+	mu   sync.Mutex
+	csrf string
+}
+
+// Film is what a signed-in film page exposes for submitting forms
+type Film struct {
+	// URL is the canonical film page, after any redirect
+	URL string
+	ID  string
+}
+
+// Action is Letterboxd's JSON reply to a form submission
+type Action struct {
+	Result     bool     `json:"result"`
+	Messages   []string `json:"messages"`
+	ErrorCodes []string `json:"errorCodes"`
+}
+
+// NewSession creates a session signed in with a session cookie value,
+// sending requests through transport
+func NewSession(token string, transport http.RoundTripper, userAgent string) (*Session, error) {
+	if token == "" {
+		return nil, fmt.Errorf("no Letterboxd session provided")
+	}
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		return nil, err
+	}
+	base, _ := url.Parse("https://letterboxd.com/")
+	jar.SetCookies(base, []*http.Cookie{{Name: SessionCookie, Value: token, Path: "/"}})
+	return &Session{
+		base:      base,
+		client:    &http.Client{Transport: transport, Jar: jar, Timeout: 30 * time.Second},
+		userAgent: userAgent,
+	}, nil
+}
+
+// Token returns the session cookie's current value, which Letterboxd may
+// have renewed since the session was created
+func (s *Session) Token() string {
+	return s.cookie(SessionCookie)
+}
+
+// cookie returns the value of one of the session's cookies
+func (s *Session) cookie(name string) string {
+	for _, cookie := range s.client.Jar.Cookies(s.base) {
+		if cookie.Name == name {
+			return cookie.Value
+		}
+	}
+	return ""
+}
+
+// resolve turns a path or URL into an absolute Letterboxd URL
+func (s *Session) resolve(ref string) (string, error) {
+	parsed, err := s.base.Parse(ref)
+	if err != nil {
+		return "", fmt.Errorf("invalid Letterboxd URL '%s': %v", ref, err)
+	}
+	if parsed.Host != s.base.Host {
+		return "", fmt.Errorf("'%s' is not a Letterboxd URL", ref)
+	}
+	return parsed.String(), nil
+}
+
+// sign adds the headers Letterboxd expects on requests from its own pages
+func (s *Session) sign(req *http.Request) {
+	req.Header.Set("User-Agent", s.userAgent)
+	req.Header.Set("Referer", s.base.String())
+	if req.Method == http.MethodPost {
+		req.Header.Set("Origin", strings.TrimSuffix(s.base.String(), "/"))
+		req.Header.Set("X-Requested-With", "XMLHttpRequest")
+		req.Header.Set("Accept", "application/json")
+	}
+}
+
+// Page loads a page while signed in, returning its final URL and HTML, and
+// remembers the CSRF token found on it
+func (s *Session) Page(ref string) (string, string, error) {
+	pageURL, err := s.resolve(ref)
+	if err != nil {
+		return "", "", err
+	}
+	req, err := http.NewRequest(http.MethodGet, pageURL, nil)
+	if err != nil {
+		return "", "", err
+	}
+	s.sign(req)
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return "", "", fmt.Errorf("could not load '%s': %v", pageURL, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", "", fmt.Errorf("could not load '%s': status code %d", pageURL, resp.StatusCode)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxPageBytes))
+	if err != nil {
+		return "", "", fmt.Errorf("could not read '%s': %v", pageURL, err)
+	}
+
+	page := string(body)
+	if match := csrfInputRegex.FindStringSubmatch(page); match != nil {
+		s.mu.Lock()
+		s.csrf = html.UnescapeString(match[1] + match[2])
+		s.mu.Unlock()
+	}
+	return resp.Request.URL.String(), page, nil
+}
+
+// CSRF returns the token forms must carry, loading the home page to get one
+// if no page has shown it yet
+func (s *Session) CSRF() (string, error) {
+	if token := s.csrfToken(); token != "" {
+		return token, nil
+	}
+	if _, _, err := s.Page("/"); err != nil {
+		return "", err
+	}
+	if token := s.csrfToken(); token != "" {
+		return token, nil
+	}
+	return "", fmt.Errorf("not signed in to Letterboxd; the session may have expired")
+}
+
+// csrfToken returns the known CSRF token, preferring the cookie Letterboxd
+// checks it against
+func (s *Session) csrfToken() string {
+	if token := s.cookie(csrfCookie); token != "" {
+		return token
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.csrf
+}
+
+// Film loads a film page and reads the film's internal ID
+func (s *Session) Film(filmURL string) (Film, error) {
+	finalURL, page, err := s.Page(strings.TrimSuffix(filmURL, "/") + "/")
+	if err != nil {
+		return Film{}, err
+	}
+	// Only signed-in film pages carry forms
+	if !csrfInputRegex.MatchString(page) {
+		return Film{}, fmt.Errorf("not signed in to Letterboxd; the session may have expired")
+	}
+	film := Film{URL: finalURL}
+	if match := filmIDRegex.FindStringSubmatch(page); match != nil {
+		film.ID = match[1]
+	}
+	return film, nil
+}
+
+// Post submits a form with the CSRF token and checks Letterboxd's JSON
+// reply. A rejected token is refreshed and the form submitted once more.
+func (s *Session) Post(ref string, form url.Values) error {
+	action, err := s.post(ref, form)
+	if err == nil && !action.Result && action.csrfRejected() {
+		s.mu.Lock()
+		s.csrf = ""
+		s.mu.Unlock()
+		if _, _, err := s.Page("/"); err != nil {
+			return err
+		}
+		action, err = s.post(ref, form)
+	}
+	if err != nil {
+		return err
+	}
+	if !action.Result {
+		if len(action.Messages) > 0 {
+			return fmt.Errorf("Letterboxd rejected the request: %s", strings.Join(action.Messages, " "))
+		}
+		return fmt.Errorf("Letterboxd rejected the request")
+	}
+	return nil
+}
+
+// post submits a form once
+func (s *Session) post(ref string, form url.Values) (Action, error) {
+	var action Action
+	actionURL, err := s.resolve(ref)
+	if err != nil {
+		return action, err
+	}
+	token, err := s.CSRF()
+	if err != nil {
+		return action, err
+	}
+	values := url.Values{}
+	for key, value := range form {
+		values[key] = value
+	}
+	values.Set("__csrf", token)
+
+	req, err := http.NewRequest(http.MethodPost, actionURL, strings.NewReader(values.Encode()))
+	if err != nil {
+		return action, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded; charset=UTF-8")
+	s.sign(req)
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return action, fmt.Errorf("could not submit to Letterboxd: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxPageBytes)).Decode(&action); err != nil {
+		if resp.StatusCode == http.StatusForbidden {
+			// A stale token is refused before the form is read
+			return Action{ErrorCodes: []string{"csrf"}}, nil
+		}
+		return action, fmt.Errorf("unexpected reply from Letterboxd (status code %d): %v", resp.StatusCode, err)
+	}
+	return action, nil
+}
+
+// csrfRejected reports whether a submission failed on its CSRF token
+func (a Action) csrfRejected() bool {
+	for _, code := range a.ErrorCodes {
+		if strings.Contains(strings.ToLower(code), "csrf") {
+			return true
+		}
+	}
+	return false
+}
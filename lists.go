@@ -0,0 +1,157 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gocolly/colly/v2"
+)
+
+// ListEntry is a film on a Letterboxd list, in list order
+type ListEntry struct {
+	Title    string `json:"title"`
+	URL      string `json:"url"`
+	Position int    `json:"position"`
+}
+
+// GetList scrapes every film on a Letterboxd list such as the official Top 250
+func (a *App) GetList(listURL string) ([]ListEntry, error) {
+	listURL = strings.TrimSpace(listURL)
+	if listURL == "" {
+		return nil, fmt.Errorf("no list URL provided")
+	}
+	if !strings.HasPrefix(listURL, "http") {
+		listURL = a.ruleset().URLs.link("/" + strings.TrimPrefix(listURL, "/"))
+	}
+	if !strings.HasSuffix(listURL, "/") {
+		listURL += "/"
+	}
+
+	defer a.metrics.since("list_scrape", time.Now())
+	c := a.newCollector()
+
+	var entries []ListEntry
+	var scrapeErr error
+
+	rules := a.ruleset()
+	selectors := rules.Selectors
+	c.OnHTML(selectors.Poster, func(e *colly.HTMLElement) {
+		link := e.ChildAttr(selectors.FilmPoster, selectors.LinkAttr)
+		title := e.ChildAttr(selectors.PosterImage, "alt")
+		if link != "" && title != "" {
+			entries = append(entries, ListEntry{
+				Title:    title,
+				URL:      rules.URLs.link(link),
+				Position: len(entries) + 1,
+			})
+		}
+	})
+
+	c.OnHTML(selectors.NextPage, func(e *colly.HTMLElement) {
+		nextHref := e.Attr("href")
+		if nextHref != "" {
+			a.letterboxdLimiter.wait() // Rate limiting
+			e.Request.Visit(rules.URLs.link(nextHref))
+		}
+	})
+
+	c.OnError(func(r *colly.Response, e error) {
+		a.metrics.countError("scrape")
+		scrapeErr = e
+	})
+
+	if err := c.Visit(listURL); err != nil {
+		return nil, fmt.Errorf("could not visit list '%s': %v", listURL, err)
+	}
+	if scrapeErr != nil {
+		return nil, scrapeErr
+	}
+	if len(entries) == 0 {
+		if err := a.layoutChanged(); err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("no movies found on list '%s'", listURL)
+	}
+
+	return entries, nil
+}
+
+// CompareAgainstList intersects the group's watchlists with a canonical list
+// (Letterboxd Top 250, a festival lineup, 1001 Movies...) and returns list
+// films on at least one watchlist, most shared first and then in list order
+func (a *App) CompareAgainstList(usernames []string, listURL string) ([]Movie, error) {
+	if len(usernames) == 0 {
+		return nil, fmt.Errorf("no usernames provided")
+	}
+
+	entries, err := a.GetList(listURL)
+	if err != nil {
+		return nil, err
+	}
+
+	scrapedData, profiles, err := a.scrapeWatchlists(usernames, nil, presets[PresetStandard], nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// Index watchlists by movie key so titles don't need to match exactly
+	onWatchlists := make(map[string][]string)
+	for user, watchlist := range scrapedData {
+		for key := range watchlist {
+			onWatchlists[key] = append(onWatchlists[key], user)
+		}
+	}
+
+	exclusions, err := a.loadExclusions()
+	if err != nil {
+		return nil, err
+	}
+	notes, err := a.GetMovieNotes()
+	if err != nil {
+		return nil, err
+	}
+	tags, err := a.GetTags()
+	if err != nil {
+		return nil, err
+	}
+
+	var movies []Movie
+	for _, entry := range entries {
+		key := movieKey(entry.URL)
+		users := onWatchlists[key]
+		if len(users) == 0 {
+			continue
+		}
+		if _, excluded := exclusions[key]; excluded {
+			continue
+		}
+		sort.Strings(users)
+
+		movie := Movie{
+			Key:      key,
+			Title:    entry.Title,
+			URL:      entry.URL,
+			Count:    len(users),
+			ListRank: entry.Position,
+			Note:     notes[key].Note,
+			Tags:     tags[key],
+		}
+		for _, username := range users {
+			movie.Users = append(movie.Users, profiles[username].user(username))
+			movie.Score++
+		}
+		a.applyPlaceholders(&movie)
+		movies = append(movies, movie)
+	}
+
+	sort.SliceStable(movies, func(i, j int) bool {
+		if movies[i].Count != movies[j].Count {
+			return movies[i].Count > movies[j].Count
+		}
+		return movies[i].ListRank < movies[j].ListRank
+	})
+
+	return movies, nil
+}
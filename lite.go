@@ -0,0 +1,158 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+// liteAppends trims a details request to what MovieLite needs: release
+// dates for the certification and keywords for the entry type, but no
+// credits or images
+const liteAppends = "release_dates,keywords"
+
+// MovieLite is a compact comparison result without artwork, credits or
+// reviews, for users on metered or slow connections
+type MovieLite struct {
+	Key              string   `json:"key"`
+	Title            string   `json:"title"`
+	URL              string   `json:"url"`
+	TMDBID           int      `json:"tmdb_id"`
+	Rating           float64  `json:"rating"`
+	ReleaseYear      string   `json:"release_year"`
+	Runtime          int      `json:"runtime"`
+	FormattedRuntime string   `json:"formatted_runtime"`
+	Genres           []string `json:"genres"`
+	IMDBID           string   `json:"imdb_id"`
+	Certification    string   `json:"certification"`
+	EntryType        string   `json:"entry_type"`
+	Classification   string   `json:"classification"`
+	Status           string   `json:"status"`
+	Users            []string `json:"users"`
+	Count            int      `json:"count"`
+	Score            float64  `json:"score"`
+}
+
+// FindCommonMoviesLite runs a comparison in minimum-data mode, returning
+// compact results hydrated without images, credits or logos
+func (a *App) FindCommonMoviesLite(usernames []string, opts CompareOptions) ([]MovieLite, error) {
+	// Entry types, classifications and release status are filtered here,
+	// after the lite hydration, rather than by the full hydration
+	// FindCommonMoviesWithOptions would otherwise do
+	entryTypes, hideUnreleased := opts.EntryTypes, opts.HideUnreleased
+	classifications, shortsNight := opts.Classifications, opts.ShortsNight
+	opts.EntryTypes, opts.HideUnreleased = nil, false
+	opts.Classifications, opts.ShortsNight = nil, false
+
+	movies, err := a.FindCommonMoviesWithOptions(usernames, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]MovieLite, len(movies))
+	for i, movie := range movies {
+		results[i] = MovieLite{
+			Key:   movie.Key,
+			Title: movie.Title,
+			URL:   movie.URL,
+			Count: movie.Count,
+			Score: movie.Score,
+		}
+		for _, user := range movie.Users {
+			results[i].Users = append(results[i].Users, user.Name)
+		}
+	}
+
+	// Offline runs leave the compact results unhydrated
+	if preset, _ := presetFor(opts.Preset); preset.Offline {
+		return results, nil
+	}
+
+	forEachIndex(len(results), enrichWorkers, func(index int) {
+		if err := a.enrichLite(&results[index]); err != nil {
+			log.Printf("Could not fetch TMDB details for '%s': %v", results[index].Title, err)
+		}
+	})
+
+	if len(entryTypes) > 0 {
+		allow := make(map[string]bool, len(entryTypes))
+		for _, entryType := range entryTypes {
+			allow[entryType] = true
+		}
+		filtered := results[:0]
+		for _, movie := range results {
+			if movie.EntryType == "" || movie.EntryType == EntryUnknown || allow[movie.EntryType] {
+				filtered = append(filtered, movie)
+			}
+		}
+		results = filtered
+	}
+
+	if len(classifications) > 0 {
+		allow := make(map[string]bool, len(classifications))
+		for _, classification := range classifications {
+			allow[classification] = true
+		}
+		filtered := results[:0]
+		for _, movie := range results {
+			if movie.Classification == "" || movie.Classification == ClassificationUnknown || allow[movie.Classification] {
+				filtered = append(filtered, movie)
+			}
+		}
+		results = filtered
+	}
+
+	if shortsNight {
+		filtered := results[:0]
+		for _, movie := range results {
+			if movie.Runtime > 0 && movie.Runtime < shortsNightMaxRuntime {
+				filtered = append(filtered, movie)
+			}
+		}
+		results = filtered
+	}
+
+	if hideUnreleased {
+		filtered := results[:0]
+		for _, movie := range results {
+			if movie.Status == "" {
+				filtered = append(filtered, movie)
+			}
+		}
+		results = filtered
+	}
+
+	return results, nil
+}
+
+// enrichLite fills a compact result from a trimmed TMDB details request
+func (a *App) enrichLite(movie *MovieLite) error {
+	a.tmdbLimiter.wait()
+	var details TMDBMovie
+	var err error
+	if tmdbID := a.aliasFor(movie.Key); tmdbID != 0 {
+		details, err = a.fetchTMDBDetails(tmdbID, liteAppends)
+	} else {
+		title, year := filmYear(movie.Title, movie.URL)
+		details, err = a.lookupTMDB(title, year, liteAppends)
+	}
+	if err != nil {
+		return fmt.Errorf("lite lookup failed: %v", err)
+	}
+
+	movie.TMDBID = details.ID
+	movie.Rating = details.VoteAverage
+	movie.ReleaseYear, _, _ = strings.Cut(details.ReleaseDate, "-")
+	movie.Runtime = details.Runtime
+	movie.FormattedRuntime = formatRuntime(movie.Runtime, a.language())
+	for _, genre := range details.Genres {
+		movie.Genres = append(movie.Genres, genre.Name)
+	}
+	movie.IMDBID = details.IMDBID
+	movie.Certification = tmdbCertification(details, a.region())
+	movie.EntryType = classifyEntry(details)
+	movie.Classification = classifyMovie(movie.EntryType, movie.Runtime, movie.Genres)
+	movie.Status = releaseStatus(details.Status, details.ReleaseDate, time.Now())
+	return nil
+}
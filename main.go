@@ -2,6 +2,9 @@ package main
 
 import (
 	"embed"
+	"flag"
+	"log"
+	"strings"
 
 	"github.com/wailsapp/wails/v2"
 	"github.com/wailsapp/wails/v2/pkg/options"
@@ -12,9 +15,37 @@ import (
 var assets embed.FS
 
 func main() {
+	serveMode := flag.Bool("serve", false, "run headless and serve the comparison API and /metrics over HTTP")
+	addr := flag.String("addr", ":8080", "listen address for --serve mode")
+	tuiUsers := flag.String("tui", "", "compare these comma-separated users in an interactive terminal browser")
+	preset := flag.String("preset", "", "comparison preset for --tui mode: quick, standard, thorough or offline")
+	profile := flag.Bool("profile", false, "serve pprof endpoints and log the timing of each pipeline stage")
+	pprofAddr := flag.String("pprof-addr", defaultPprofAddr, "listen address for the pprof endpoints with --profile")
+	flag.Parse()
+
 	// Create an instance of the app structure
 	app := NewApp()
 
+	if *profile {
+		if err := app.forceProfiling(*pprofAddr); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	if *serveMode {
+		log.Fatal(serve(app, *addr))
+	}
+
+	if *tuiUsers != "" {
+		usernames := strings.FieldsFunc(*tuiUsers, func(c rune) bool {
+			return c == ',' || c == ' '
+		})
+		if err := runTUI(app, usernames, *preset); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
 	// Create application with options
 	err := wails.Run(&options.App{
 		Title:  "Klisse",
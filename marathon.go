@@ -0,0 +1,232 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+)
+
+// marathonSessionMinutes is the longest stretch of films planned for one
+// sitting; a single longer film gets a session to itself
+const marathonSessionMinutes = 360
+
+// MarathonFilm is a film in a person's filmography
+type MarathonFilm struct {
+	TMDBID           int    `json:"tmdb_id"`
+	Title            string `json:"title"`
+	ReleaseDate      string `json:"release_date"`
+	ReleaseYear      string `json:"release_year"`
+	Runtime          int    `json:"runtime"`
+	FormattedRuntime string `json:"formatted_runtime"`
+	// URL is the Letterboxd page, known when the film is on a watchlist
+	URL          string   `json:"url"`
+	OnWatchlists []string `json:"on_watchlists"`
+	// Gap marks films on none of the group's watchlists
+	Gap bool `json:"gap"`
+}
+
+// MarathonSession is a run of films watched in one sitting
+type MarathonSession struct {
+	Films            []MarathonFilm `json:"films"`
+	Runtime          int            `json:"runtime"`
+	FormattedRuntime string         `json:"formatted_runtime"`
+}
+
+// MarathonPlan orders a director's or actor's films chronologically and
+// splits them into sessions
+type MarathonPlan struct {
+	Person           Person            `json:"person"`
+	Films            []MarathonFilm    `json:"films"`
+	Gaps             int               `json:"gaps"`
+	Runtime          int               `json:"runtime"`
+	FormattedRuntime string            `json:"formatted_runtime"`
+	Sessions         []MarathonSession `json:"sessions"`
+}
+
+// tmdbPersonCredits represents a person's TMDB movie credits
+type tmdbPersonCredits struct {
+	Cast []tmdbCredit `json:"cast"`
+	Crew []tmdbCredit `json:"crew"`
+}
+
+// tmdbCredit is a film in a person's TMDB movie credits
+type tmdbCredit struct {
+	ID          int    `json:"id"`
+	Title       string `json:"title"`
+	ReleaseDate string `json:"release_date"`
+	Job         string `json:"job"`
+}
+
+// PlanMarathon finds the released films of a director or actor, marks which
+// are on the participants' watchlists and which are gaps, and splits them in
+// release order into sessions of at most marathonSessionMinutes
+func (a *App) PlanMarathon(person Person, usernames []string) (MarathonPlan, error) {
+	plan := MarathonPlan{Person: person}
+	credits, err := a.personCredits(person.ID)
+	if err != nil {
+		return plan, err
+	}
+
+	// Directors are planned by the films they directed, so acting cameos
+	// don't pad the marathon
+	filmography := credits.Cast
+	var directed []tmdbCredit
+	for _, credit := range credits.Crew {
+		if credit.Job == "Director" {
+			directed = append(directed, credit)
+		}
+	}
+	if len(directed) > 0 {
+		filmography = directed
+	}
+
+	today := time.Now().Format("2006-01-02")
+	seen := make(map[int]bool)
+	for _, credit := range filmography {
+		if seen[credit.ID] || len(credit.ReleaseDate) < 10 || credit.ReleaseDate > today {
+			continue
+		}
+		seen[credit.ID] = true
+		plan.Films = append(plan.Films, MarathonFilm{
+			TMDBID:      credit.ID,
+			Title:       credit.Title,
+			ReleaseDate: credit.ReleaseDate,
+			ReleaseYear: credit.ReleaseDate[:4],
+		})
+	}
+	if len(plan.Films) == 0 {
+		return plan, fmt.Errorf("no released films found for '%s'", person.Name)
+	}
+	sort.SliceStable(plan.Films, func(i, j int) bool {
+		return plan.Films[i].ReleaseDate < plan.Films[j].ReleaseDate
+	})
+
+	a.markWatchlisted(plan.Films, usernames)
+	a.fillRuntimes(plan.Films)
+
+	for _, film := range plan.Films {
+		if film.Gap {
+			plan.Gaps++
+		}
+		plan.Runtime += film.Runtime
+	}
+	plan.FormattedRuntime = formatRuntime(plan.Runtime, a.language())
+	plan.Sessions = a.splitSessions(plan.Films)
+	return plan, nil
+}
+
+// personCredits fetches a person's TMDB movie credits
+func (a *App) personCredits(personID int) (tmdbPersonCredits, error) {
+	var credits tmdbPersonCredits
+	apiKey := a.getTMDBAPIKey()
+	if apiKey == "" || len(apiKey) < 10 {
+		return credits, fmt.Errorf("TMDB API key not configured")
+	}
+	if personID <= 0 {
+		return credits, fmt.Errorf("invalid TMDB person ID: %d", personID)
+	}
+
+	a.tmdbLimiter.wait()
+	resp, err := a.tmdbGet(fmt.Sprintf("https://api.themoviedb.org/3/person/%d/movie_credits?api_key=%s", personID, apiKey))
+	if err != nil {
+		return credits, fmt.Errorf("failed to get credits: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 {
+		return credits, fmt.Errorf("credits API error: %v", parseTMDBError(resp))
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&credits); err != nil {
+		return credits, fmt.Errorf("failed to parse credits: %v", err)
+	}
+	return credits, nil
+}
+
+// markWatchlisted records which participants have each film on their
+// watchlist, matching by alias first and then by title and year
+func (a *App) markWatchlisted(films []MarathonFilm, usernames []string) {
+	var mu sync.Mutex
+	watchlists := make(map[string]map[string]WatchlistEntry, len(usernames))
+	a.forEachUser(usernames, 0, func(username string) {
+		watchlist, err := a.GetWatchlist(username)
+		if err != nil {
+			log.Printf("Could not scrape watchlist for '%s': %v", username, err)
+			return
+		}
+		mu.Lock()
+		watchlists[username] = watchlist
+		mu.Unlock()
+	})
+
+	aliases, err := a.GetAliases()
+	if err != nil {
+		log.Printf("Could not load aliases: %v", err)
+	}
+	for i := range films {
+		film := &films[i]
+		for _, username := range usernames {
+			for _, entry := range watchlists[username] {
+				if sameFilm(aliases, film.TMDBID, film.Title, film.ReleaseYear, entry.Title, entry.URL) {
+					film.URL = entry.URL
+					film.OnWatchlists = append(film.OnWatchlists, username)
+					break
+				}
+			}
+		}
+		film.Gap = len(film.OnWatchlists) == 0
+	}
+}
+
+// sameFilm reports whether a Letterboxd entry is the TMDB film with the
+// given ID, title and release year, going by the alias table first; it is
+// run for every pair of films, so the table is loaded once by the caller
+func sameFilm(aliases map[string]Alias, tmdbID int, tmdbTitle string, releaseYear string, title string, filmURL string) bool {
+	if id := aliases[movieKey(filmURL)].TMDBID; id != 0 {
+		return id == tmdbID
+	}
+	title, year := filmYear(title, filmURL)
+	if year != "" && year != releaseYear {
+		return false
+	}
+	return normalizeTitle(title) == normalizeTitle(tmdbTitle)
+}
+
+// fillRuntimes looks up the runtime of every film, which TMDB's credits
+// leave out
+func (a *App) fillRuntimes(films []MarathonFilm) {
+	forEachIndex(len(films), enrichWorkers, func(index int) {
+		a.tmdbLimiter.wait()
+		details, err := a.fetchTMDBDetails(films[index].TMDBID, "")
+		if err != nil {
+			log.Printf("Could not fetch runtime for '%s': %v", films[index].Title, err)
+			return
+		}
+		films[index].Runtime = details.Runtime
+		films[index].FormattedRuntime = formatRuntime(details.Runtime, a.language())
+	})
+}
+
+// splitSessions packs the films, in order, into sessions no longer than
+// marathonSessionMinutes
+func (a *App) splitSessions(films []MarathonFilm) []MarathonSession {
+	var sessions []MarathonSession
+	var current MarathonSession
+	for _, film := range films {
+		if len(current.Films) > 0 && current.Runtime+film.Runtime > marathonSessionMinutes {
+			sessions = append(sessions, current)
+			current = MarathonSession{}
+		}
+		current.Films = append(current.Films, film)
+		current.Runtime += film.Runtime
+	}
+	if len(current.Films) > 0 {
+		sessions = append(sessions, current)
+	}
+	for i := range sessions {
+		sessions[i].FormattedRuntime = formatRuntime(sessions[i].Runtime, a.language())
+	}
+	return sessions
+}
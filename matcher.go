@@ -0,0 +1,97 @@
+package main
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// WatchlistEntry is one film on a watchlist. Watchlists map the film's
+// movieKey to its entry, so same-titled films such as a remake and its
+// original stay apart
+type WatchlistEntry struct {
+	Title string `json:"title"`
+	URL   string `json:"url"`
+}
+
+// commonEntry is one film in the intersection together with the users listing it
+type commonEntry struct {
+	Title string
+	URL   string
+	Users []string
+}
+
+var (
+	punctuationRegex = regexp.MustCompile(`[^\p{L}\p{N}\s]`)
+	whitespaceRegex  = regexp.MustCompile(`\s+`)
+	slugRegex        = regexp.MustCompile(`[^a-z0-9]+`)
+)
+
+// normalizeTitle canonicalizes a title for comparison: diacritics are folded,
+// case, punctuation and a trailing "(year)" are dropped and whitespace collapsed,
+// so "Amélie (2001)" and "Amelie" compare equal
+func normalizeTitle(title string) string {
+	folder := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+	folded, _, err := transform.String(folder, title)
+	if err != nil {
+		folded = title
+	}
+
+	folded = titleYearRegex.ReplaceAllString(folded, "")
+	folded = strings.ReplaceAll(folded, "&", " and ")
+	folded = punctuationRegex.ReplaceAllString(strings.ToLower(folded), "")
+	return strings.TrimSpace(whitespaceRegex.ReplaceAllString(folded, " "))
+}
+
+// letterboxdSlug returns the film slug Letterboxd derives from a title,
+// without the year it appends to later films sharing the title, so
+// "Amélie" becomes "amelie" and "Schindler's List" "schindlers-list"
+func letterboxdSlug(title string) string {
+	folder := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+	folded, _, err := transform.String(folder, title)
+	if err != nil {
+		folded = title
+	}
+	folded = strings.NewReplacer("'", "", "’", "").Replace(strings.ToLower(folded))
+	return strings.Trim(slugRegex.ReplaceAllString(folded, "-"), "-")
+}
+
+// intersectWatchlists groups every watchlist entry by its Letterboxd film
+// slug, which is unique per film, so alternate titles of one film merge while
+// same-titled remakes stay apart; each user is counted at most once per film
+func intersectWatchlists(watchlists map[string]map[string]WatchlistEntry) map[string]*commonEntry {
+	// Visit users and films in a stable order so display titles are deterministic
+	users := make([]string, 0, len(watchlists))
+	for user := range watchlists {
+		users = append(users, user)
+	}
+	sort.Strings(users)
+
+	entries := make(map[string]*commonEntry)
+	for _, user := range users {
+		keys := make([]string, 0, len(watchlists[user]))
+		for key := range watchlists[user] {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			film := watchlists[user][key]
+			entry, ok := entries[key]
+			if !ok {
+				entry = &commonEntry{Title: film.Title, URL: film.URL}
+				entries[key] = entry
+			}
+			if len(entry.Users) == 0 || entry.Users[len(entry.Users)-1] != user {
+				entry.Users = append(entry.Users, user)
+			}
+		}
+	}
+
+	return entries
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+)
+
+// benchmarkWatchlists builds users watchlists of size films each, half of
+// them shared by every user so the intersection has work to do
+func benchmarkWatchlists(users int, size int) map[string]map[string]WatchlistEntry {
+	watchlists := make(map[string]map[string]WatchlistEntry, users)
+	for u := 0; u < users; u++ {
+		watchlist := make(map[string]WatchlistEntry, size)
+		for i := 0; i < size; i++ {
+			slug := fmt.Sprintf("film-%d", i)
+			if i%2 == 1 {
+				slug = fmt.Sprintf("film-%d-%d", u, i)
+			}
+			watchlist[slug] = WatchlistEntry{
+				Title: fmt.Sprintf("Film %d", i),
+				URL:   "https://letterboxd.com/film/" + slug + "/",
+			}
+		}
+		watchlists[fmt.Sprintf("user%d", u)] = watchlist
+	}
+	return watchlists
+}
+
+func BenchmarkIntersectWatchlists(b *testing.B) {
+	for _, size := range []int{100, 1000, 5000} {
+		watchlists := benchmarkWatchlists(4, size)
+		b.Run(fmt.Sprintf("4x%d", size), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				intersectWatchlists(watchlists)
+			}
+		})
+	}
+}
+
+func BenchmarkNormalizeTitle(b *testing.B) {
+	titles := []string{
+		"Amélie (2001)",
+		"The Lord of the Rings: The Fellowship of the Ring",
+		"Crouching Tiger, Hidden Dragon",
+		"Fast & Furious",
+		"8½",
+	}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		normalizeTitle(titles[i%len(titles)])
+	}
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// MatchCandidate is a TMDB search hit offered when correcting a match
+type MatchCandidate struct {
+	TMDBID        int    `json:"tmdb_id"`
+	Title         string `json:"title"`
+	OriginalTitle string `json:"original_title"`
+	ReleaseYear   string `json:"release_year"`
+	PosterURL     string `json:"poster_url"`
+	Overview      string `json:"overview"`
+}
+
+// SearchTMDB returns the TMDB movies matching a free-text query, so the user
+// can pick the right one when automatic matching chose the wrong film
+func (a *App) SearchTMDB(query string) ([]MatchCandidate, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, fmt.Errorf("no search query provided")
+	}
+	apiKey := a.getTMDBAPIKey()
+	if apiKey == "" || len(apiKey) < 10 {
+		return nil, fmt.Errorf("TMDB API key not configured")
+	}
+
+	// A trailing "(1998)" narrows the search to that release year
+	title, year := splitTitleYear(query)
+	includeAdult := a.loadSettings().IncludeAdult
+	searchURL := fmt.Sprintf("https://api.themoviedb.org/3/search/movie?api_key=%s&query=%s&include_adult=%t", apiKey, url.QueryEscape(title), includeAdult)
+	if year != "" {
+		searchURL += "&primary_release_year=" + year
+	}
+
+	a.tmdbLimiter.wait()
+	resp, err := a.tmdbGet(searchURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search TMDB: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 {
+		return nil, fmt.Errorf("search API error: %v", parseTMDBError(resp))
+	}
+
+	var searchResult TMDBSearchResult
+	if err := json.NewDecoder(resp.Body).Decode(&searchResult); err != nil {
+		return nil, fmt.Errorf("failed to parse search results: %v", err)
+	}
+
+	candidates := make([]MatchCandidate, 0, len(searchResult.Results))
+	for _, result := range searchResult.Results {
+		if result.Adult && !includeAdult {
+			continue
+		}
+		candidate := MatchCandidate{
+			TMDBID:        result.ID,
+			Title:         result.Title,
+			OriginalTitle: result.OriginalTitle,
+			Overview:      result.Overview,
+		}
+		candidate.ReleaseYear, _, _ = strings.Cut(result.ReleaseDate, "-")
+		if result.PosterPath != "" {
+			candidate.PosterURL = fmt.Sprintf("https://image.tmdb.org/t/p/w185%s", result.PosterPath)
+		}
+		candidates = append(candidates, candidate)
+	}
+	return candidates, nil
+}
+
+// OverrideMatch pins a movie to a user-chosen TMDB ID in the alias table,
+// used instead of the title search from then on; an ID of 0 restores
+// automatic matching
+func (a *App) OverrideMatch(key string, tmdbID int) error {
+	if key == "" {
+		return fmt.Errorf("no movie key provided")
+	}
+	if tmdbID < 0 {
+		return fmt.Errorf("invalid TMDB ID %d", tmdbID)
+	}
+	return a.setAlias(key, tmdbID, AliasManual)
+}
+
+// enrichByTMDBID fills a movie's metadata from a known TMDB ID, skipping the
+// title search
+func (a *App) enrichByTMDBID(movie *Movie, tmdbID int) error {
+	a.tmdbLimiter.wait()
+	details, err := a.fetchTMDBDetails(tmdbID, detailAppends)
+	if err != nil {
+		return err
+	}
+	title, _ := filmYear(movie.Title, movie.URL)
+	mergeMetadata(movie, a.movieFromTMDB(details, title))
+	movie.CompositeScore = compositeScore(*movie, a.GetCompositeWeights())
+	return nil
+}
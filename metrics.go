@@ -0,0 +1,114 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"time"
+)
+
+// latencyBuckets are the histogram upper bounds, in seconds
+var latencyBuckets = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60}
+
+// histogram is a cumulative latency histogram in the Prometheus style
+type histogram struct {
+	counts []int
+	sum    float64
+	count  int
+}
+
+// metrics collects pipeline timings and error counts for diagnostics and /metrics
+type metrics struct {
+	mu         sync.Mutex
+	histograms map[string]*histogram
+	errors     map[string]int
+}
+
+// newMetrics creates an empty metrics registry
+func newMetrics() *metrics {
+	return &metrics{
+		histograms: make(map[string]*histogram),
+		errors:     make(map[string]int),
+	}
+}
+
+// observe records a duration in the named histogram
+func (m *metrics) observe(name string, d time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	h, ok := m.histograms[name]
+	if !ok {
+		h = &histogram{counts: make([]int, len(latencyBuckets))}
+		m.histograms[name] = h
+	}
+	seconds := d.Seconds()
+	for i, bound := range latencyBuckets {
+		if seconds <= bound {
+			h.counts[i]++
+		}
+	}
+	h.sum += seconds
+	h.count++
+}
+
+// since records the time elapsed since start in the named histogram
+func (m *metrics) since(name string, start time.Time) {
+	m.observe(name, time.Since(start))
+}
+
+// countError increments the error counter for a pipeline stage
+func (m *metrics) countError(kind string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.errors[kind]++
+}
+
+// writePrometheus renders the metrics in the Prometheus text exposition format
+func (m *metrics) writePrometheus(w io.Writer, caches []CacheStatus) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	names := make([]string, 0, len(m.histograms))
+	for name := range m.histograms {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		h := m.histograms[name]
+		metric := "klisse_" + name + "_seconds"
+		fmt.Fprintf(w, "# TYPE %s histogram\n", metric)
+		for i, bound := range latencyBuckets {
+			fmt.Fprintf(w, "%s_bucket{le=\"%g\"} %d\n", metric, bound, h.counts[i])
+		}
+		fmt.Fprintf(w, "%s_bucket{le=\"+Inf\"} %d\n", metric, h.count)
+		fmt.Fprintf(w, "%s_sum %g\n", metric, h.sum)
+		fmt.Fprintf(w, "%s_count %d\n", metric, h.count)
+	}
+
+	kinds := make([]string, 0, len(m.errors))
+	for kind := range m.errors {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+
+	fmt.Fprintln(w, "# TYPE klisse_errors_total counter")
+	for _, kind := range kinds {
+		fmt.Fprintf(w, "klisse_errors_total{stage=%q} %d\n", kind, m.errors[kind])
+	}
+
+	fmt.Fprintln(w, "# TYPE klisse_cache_hits_total counter")
+	for _, cache := range caches {
+		fmt.Fprintf(w, "klisse_cache_hits_total{cache=%q} %d\n", cache.Name, cache.Hits)
+	}
+	fmt.Fprintln(w, "# TYPE klisse_cache_misses_total counter")
+	for _, cache := range caches {
+		fmt.Fprintf(w, "klisse_cache_misses_total{cache=%q} %d\n", cache.Name, cache.Misses)
+	}
+	fmt.Fprintln(w, "# TYPE klisse_cache_hit_ratio gauge")
+	for _, cache := range caches {
+		fmt.Fprintf(w, "klisse_cache_hit_ratio{cache=%q} %g\n", cache.Name, cache.HitRate)
+	}
+}
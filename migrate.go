@@ -0,0 +1,207 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// migration is a versioned change to the layout of the stored documents.
+// up moves the store from version-1 to version and down reverts it; both
+// must leave the store readable if they fail halfway
+type migration struct {
+	version int
+	name    string
+	up      func(s *dataStore) error
+	down    func(s *dataStore) error
+}
+
+// migrations are applied in order; append new ones with the next version
+// and never edit or reorder released ones
+var migrations = []migration{
+	{
+		// The layout every release before versioning wrote
+		version: 1,
+		name:    "baseline",
+		up:      func(s *dataStore) error { return nil },
+		down:    func(s *dataStore) error { return nil },
+	},
+	{
+		// Imported lists, watchlist snapshots and checkpoints key films by
+		// slug instead of title, so same-titled films stop overwriting each
+		// other; going back keeps one film per title
+		version: 2,
+		name:    "watchlists keyed by film",
+		up:      func(s *dataStore) error { return rekeyWatchlists(s, keyedByFilm) },
+		down:    func(s *dataStore) error { return rekeyWatchlists(s, keyedByTitle) },
+	},
+}
+
+// schemaState is the stored schema version of the data directory
+type schemaState struct {
+	Version    int       `json:"version"`
+	MigratedAt time.Time `json:"migrated_at"`
+}
+
+// latestSchema is the version the running build reads and writes
+func latestSchema() int {
+	return migrations[len(migrations)-1].version
+}
+
+// migrateStore checks the stored documents and brings them to the latest
+// schema version; it is run on startup before anything is loaded
+func migrateStore(s *dataStore) error {
+	if quarantined := s.checkIntegrity(); len(quarantined) > 0 {
+		log.Printf("Moved aside unreadable data files: %s", strings.Join(quarantined, ", "))
+	}
+
+	var state schemaState
+	if err := s.load("schema", &state); err != nil {
+		return err
+	}
+	if state.Version > latestSchema() {
+		// Written by a newer release; leave it as is rather than guess
+		return fmt.Errorf("data directory uses schema version %d, newer than the supported %d", state.Version, latestSchema())
+	}
+	return s.migrateTo(state.Version, latestSchema())
+}
+
+// migrateTo runs the migrations between two versions, up or down, saving
+// the version after every step so a failed run resumes where it stopped
+func (s *dataStore) migrateTo(from, to int) error {
+	for from < to {
+		m := migrations[from]
+		if err := m.up(s); err != nil {
+			return fmt.Errorf("migration %d (%s) failed: %v", m.version, m.name, err)
+		}
+		from = m.version
+		if err := s.save("schema", schemaState{Version: from, MigratedAt: time.Now()}); err != nil {
+			return err
+		}
+		log.Printf("Migrated data to schema version %d (%s)", m.version, m.name)
+	}
+	for from > to {
+		m := migrations[from-1]
+		if err := m.down(s); err != nil {
+			return fmt.Errorf("reverting migration %d (%s) failed: %v", m.version, m.name, err)
+		}
+		from = m.version - 1
+		if err := s.save("schema", schemaState{Version: from, MigratedAt: time.Now()}); err != nil {
+			return err
+		}
+		log.Printf("Reverted data to schema version %d", from)
+	}
+	return nil
+}
+
+// checkIntegrity renames the documents that aren't valid JSON, such as ones
+// truncated by a crash, to a .corrupt extension so they are read as missing
+// instead of failing every load; it returns their names
+func (s *dataStore) checkIntegrity() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	paths, _ := filepath.Glob(filepath.Join(s.dir, "*.json"))
+	var quarantined []string
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil || json.Valid(data) {
+			continue
+		}
+		if err := os.Rename(path, strings.TrimSuffix(path, ".json")+".corrupt"); err != nil {
+			log.Printf("Could not move aside %s: %v", filepath.Base(path), err)
+			continue
+		}
+		quarantined = append(quarantined, strings.TrimSuffix(filepath.Base(path), ".json"))
+	}
+	return quarantined
+}
+
+// rekeyWatchlists converts every stored watchlist, in imported lists,
+// watchlist snapshots and checkpoints, with convert
+func rekeyWatchlists(s *dataStore, convert func(json.RawMessage) (json.RawMessage, error)) error {
+	docs, err := s.documents()
+	if err != nil {
+		return err
+	}
+	for name, data := range docs {
+		var doc interface{}
+		switch {
+		case name == "imports":
+			var imports map[string]map[string]json.RawMessage
+			if err := json.Unmarshal(data, &imports); err != nil {
+				return fmt.Errorf("could not parse %s: %v", name, err)
+			}
+			for _, imported := range imports {
+				if imported["entries"], err = convert(imported["entries"]); err != nil {
+					return fmt.Errorf("could not convert %s: %v", name, err)
+				}
+			}
+			doc = imports
+		case strings.HasPrefix(name, "watchlist-"):
+			var history map[string]json.RawMessage
+			if err := json.Unmarshal(data, &history); err != nil {
+				return fmt.Errorf("could not parse %s: %v", name, err)
+			}
+			if history["snapshot"], err = convert(history["snapshot"]); err != nil {
+				return fmt.Errorf("could not convert %s: %v", name, err)
+			}
+			doc = history
+		case strings.HasPrefix(name, "checkpoint-"):
+			var cp map[string]json.RawMessage
+			var watchlists map[string]json.RawMessage
+			if err := json.Unmarshal(data, &cp); err != nil {
+				return fmt.Errorf("could not parse %s: %v", name, err)
+			}
+			if err := json.Unmarshal(cp["watchlists"], &watchlists); err != nil && len(cp["watchlists"]) > 0 {
+				return fmt.Errorf("could not parse %s: %v", name, err)
+			}
+			for user, watchlist := range watchlists {
+				if watchlists[user], err = convert(watchlist); err != nil {
+					return fmt.Errorf("could not convert %s: %v", name, err)
+				}
+			}
+			if watchlists != nil {
+				cp["watchlists"], _ = json.Marshal(watchlists)
+			}
+			doc = cp
+		default:
+			continue
+		}
+		if err := s.save(name, doc); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// keyedByFilm converts a title -> URL watchlist to one keyed by movieKey
+func keyedByFilm(data json.RawMessage) (json.RawMessage, error) {
+	var byTitle map[string]string
+	if len(data) == 0 || json.Unmarshal(data, &byTitle) != nil || byTitle == nil {
+		// Missing, or already converted by an interrupted run
+		return data, nil
+	}
+	byKey := make(map[string]WatchlistEntry, len(byTitle))
+	for title, filmURL := range byTitle {
+		byKey[movieKey(filmURL)] = WatchlistEntry{Title: title, URL: filmURL}
+	}
+	return json.Marshal(byKey)
+}
+
+// keyedByTitle reverts keyedByFilm; of films sharing a title one is kept
+func keyedByTitle(data json.RawMessage) (json.RawMessage, error) {
+	var byKey map[string]WatchlistEntry
+	if len(data) == 0 || json.Unmarshal(data, &byKey) != nil || byKey == nil {
+		return data, nil
+	}
+	byTitle := make(map[string]string, len(byKey))
+	for _, film := range byKey {
+		byTitle[film.Title] = film.URL
+	}
+	return json.Marshal(byTitle)
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestMigrateStore(t *testing.T) {
+	tests := []struct {
+		name    string
+		docs    map[string]string
+		wantErr bool
+		want    int
+	}{
+		{name: "fresh directory", want: latestSchema()},
+		{name: "unversioned", docs: map[string]string{"settings": `{}`}, want: latestSchema()},
+		{name: "baseline", docs: map[string]string{"schema": `{"version": 1}`}, want: latestSchema()},
+		{name: "current", docs: map[string]string{"schema": `{"version": 2}`}, want: latestSchema()},
+		{name: "newer release", docs: map[string]string{"schema": `{"version": 99}`}, wantErr: true, want: 99},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newDataStore(t.TempDir())
+			for name, data := range tt.docs {
+				if err := s.write(name, []byte(data)); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := migrateStore(s); (err != nil) != tt.wantErr {
+				t.Fatalf("migrateStore() error = %v, want error %v", err, tt.wantErr)
+			}
+			var state schemaState
+			if err := s.load("schema", &state); err != nil {
+				t.Fatal(err)
+			}
+			if state.Version != tt.want {
+				t.Errorf("schema version %d, want %d", state.Version, tt.want)
+			}
+		})
+	}
+}
+
+func TestMigrateWatchlistKeys(t *testing.T) {
+	s := newDataStore(t.TempDir())
+	byTitle := map[string]string{
+		"Alien": "https://letterboxd.com/film/alien/",
+		"Heat":  "https://letterboxd.com/film/heat-1995/",
+	}
+	if err := s.save("watchlist-sam", map[string]interface{}{"snapshot": byTitle}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.save("imports", map[string]interface{}{"list": map[string]interface{}{"entries": byTitle}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.save("schema", schemaState{Version: 1}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := migrateStore(s); err != nil {
+		t.Fatal(err)
+	}
+	var history struct {
+		Snapshot map[string]WatchlistEntry `json:"snapshot"`
+	}
+	if err := s.load("watchlist-sam", &history); err != nil {
+		t.Fatal(err)
+	}
+	wantByKey := map[string]WatchlistEntry{
+		"alien":     {Title: "Alien", URL: "https://letterboxd.com/film/alien/"},
+		"heat-1995": {Title: "Heat", URL: "https://letterboxd.com/film/heat-1995/"},
+	}
+	if !reflect.DeepEqual(history.Snapshot, wantByKey) {
+		t.Errorf("migrated snapshot %v, want %v", history.Snapshot, wantByKey)
+	}
+
+	// Running it again after an interrupted run leaves converted data alone
+	if err := rekeyWatchlists(s, keyedByFilm); err != nil {
+		t.Fatal(err)
+	}
+	var imports map[string]struct {
+		Entries map[string]WatchlistEntry `json:"entries"`
+	}
+	if err := s.load("imports", &imports); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(imports["list"].Entries, wantByKey) {
+		t.Errorf("migrated import %v, want %v", imports["list"].Entries, wantByKey)
+	}
+
+	if err := s.migrateTo(latestSchema(), 1); err != nil {
+		t.Fatal(err)
+	}
+	var reverted struct {
+		Snapshot map[string]string `json:"snapshot"`
+	}
+	if err := s.load("watchlist-sam", &reverted); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(reverted.Snapshot, byTitle) {
+		t.Errorf("reverted snapshot %v, want %v", reverted.Snapshot, byTitle)
+	}
+}
+
+func TestCheckIntegrity(t *testing.T) {
+	dir := t.TempDir()
+	s := newDataStore(dir)
+	if err := s.write("settings", []byte(`{"theme": "dark"}`)); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.write("pins", []byte(`{"alien": tr`)); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := s.checkIntegrity(); !reflect.DeepEqual(got, []string{"pins"}) {
+		t.Errorf("checkIntegrity() = %v, want [pins]", got)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "pins.corrupt")); err != nil {
+		t.Errorf("unreadable document not moved aside: %v", err)
+	}
+	var settings map[string]json.RawMessage
+	if err := s.load("settings", &settings); err != nil || settings == nil {
+		t.Errorf("readable document was touched: %v", err)
+	}
+}
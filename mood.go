@@ -0,0 +1,135 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Answer levels for the mood questions
+const (
+	MoodLow    = "low"
+	MoodMedium = "medium"
+	MoodHigh   = "high"
+)
+
+// MoodAnswers are the group's answers to the quick mood questions
+type MoodAnswers struct {
+	// Energy is how lively the group feels: MoodLow, MoodMedium or MoodHigh
+	Energy string `json:"energy"`
+	// Attention is how long the group can focus for, from MoodLow (short
+	// films only) to MoodHigh (anything goes)
+	Attention string `json:"attention"`
+	// WantToCry asks for a tearjerker; false steers away from them
+	WantToCry bool `json:"want_to_cry"`
+}
+
+// moodGenres are the genres suiting each energy level and those that don't
+var moodGenres = map[string]struct{ suit, clash []string }{
+	MoodLow: {
+		suit:  []string{"Comedy", "Animation", "Family", "Romance", "Music"},
+		clash: []string{"Action", "Thriller", "Horror", "War"},
+	},
+	MoodMedium: {
+		suit:  []string{"Adventure", "Comedy", "Mystery", "Crime", "Fantasy"},
+		clash: []string{"Documentary"},
+	},
+	MoodHigh: {
+		suit:  []string{"Action", "Adventure", "Thriller", "Horror", "Science Fiction"},
+		clash: []string{"Documentary", "History", "Drama"},
+	},
+}
+
+// moodRuntimes is the longest comfortable runtime per attention level, in
+// minutes; MoodHigh has no limit
+var moodRuntimes = map[string]int{
+	MoodLow:    100,
+	MoodMedium: 135,
+}
+
+var (
+	// sadGenres tend towards tearjerkers
+	sadGenres = []string{"Drama", "Romance", "War"}
+	// sadKeywords are TMDB keywords marking a tearjerker
+	sadKeywords = []string{"tearjerker", "grief", "terminal illness", "loss of loved one", "dying", "tragedy", "cancer"}
+)
+
+// ScoreByMood reranks movies by how well they fit the group's mood, judged
+// from genres, keywords and runtime; movies without details are hydrated
+// first
+func (a *App) ScoreByMood(movies []Movie, answers MoodAnswers) ([]Movie, error) {
+	for _, level := range []string{answers.Energy, answers.Attention} {
+		if level != MoodLow && level != MoodMedium && level != MoodHigh {
+			return nil, fmt.Errorf("invalid mood answer '%s'", level)
+		}
+	}
+
+	scored := a.hydrateBare(movies)
+
+	for i := range scored {
+		scored[i].MoodScore = moodFit(scored[i], answers)
+	}
+	sort.SliceStable(scored, func(i, j int) bool {
+		return scored[i].MoodScore > scored[j].MoodScore
+	})
+	return scored, nil
+}
+
+// moodFit scores in [0, 1] how well a movie suits the mood, averaging the
+// energy, attention and tearjerker fits
+func moodFit(movie Movie, answers MoodAnswers) float64 {
+	energy := 0.5
+	genres := moodGenres[answers.Energy]
+	for _, genre := range movie.Genres {
+		if slices.Contains(genres.suit, genre) {
+			energy += 0.25
+		}
+		if slices.Contains(genres.clash, genre) {
+			energy -= 0.25
+		}
+	}
+
+	attention := 1.0
+	if limit, ok := moodRuntimes[answers.Attention]; ok {
+		if movie.Runtime == 0 {
+			attention = 0.5
+		} else if movie.Runtime > limit {
+			// Lose all credit an hour past the comfortable length
+			attention = 1 - float64(movie.Runtime-limit)/60
+		}
+	}
+
+	sadness := 0.0
+	for _, genre := range movie.Genres {
+		if slices.Contains(sadGenres, genre) {
+			sadness += 0.3
+		}
+	}
+	for _, keyword := range movie.Keywords {
+		for _, sad := range sadKeywords {
+			if strings.Contains(keyword, sad) {
+				sadness += 0.5
+				break
+			}
+		}
+	}
+	sadness = clamp01(sadness)
+	cry := 1 - sadness
+	if answers.WantToCry {
+		cry = sadness
+	}
+
+	return (clamp01(energy) + clamp01(attention) + cry) / 3
+}
+
+// clamp01 limits a value to [0, 1]
+func clamp01(v float64) float64 {
+	if v < 0 {
+		return 0
+	}
+	if v > 1 {
+		return 1
+	}
+	return v
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	// maxMovieNights caps the movie night log
+	maxMovieNights = 100
+	// recentNights is how many of the latest movie nights variety is judged
+	// against
+	recentNights = 5
+)
+
+// MovieNight is a film the group picked and watched together
+type MovieNight struct {
+	Key       string    `json:"key"`
+	Title     string    `json:"title"`
+	TMDBID    int       `json:"tmdb_id"`
+	Genres    []string  `json:"genres"`
+	Director  Person    `json:"director"`
+	WatchedAt time.Time `json:"watched_at"`
+}
+
+// RecordMovieNight remembers a picked film so later comparisons can favour
+// something different
+func (a *App) RecordMovieNight(movie Movie) error {
+	// Bare intersection results need details to be told apart by genre
+	if movie.TMDBID == 0 && len(movie.Genres) == 0 {
+		a.enrichMovie(&movie)
+	}
+
+	a.nightsMu.Lock()
+	defer a.nightsMu.Unlock()
+
+	nights, err := a.loadMovieNights()
+	if err != nil {
+		return err
+	}
+	nights = append(nights, MovieNight{
+		Key:       movie.Key,
+		Title:     movie.Title,
+		TMDBID:    movie.TMDBID,
+		Genres:    movie.Genres,
+		Director:  movie.Director,
+		WatchedAt: time.Now(),
+	})
+	if len(nights) > maxMovieNights {
+		nights = nights[len(nights)-maxMovieNights:]
+	}
+	return a.store.save("nights", nights)
+}
+
+// GetMovieNights returns the recorded movie nights, newest first
+func (a *App) GetMovieNights() ([]MovieNight, error) {
+	nights, err := a.loadMovieNights()
+	if err != nil {
+		return nil, err
+	}
+	for i, j := 0, len(nights)-1; i < j; i, j = i+1, j-1 {
+		nights[i], nights[j] = nights[j], nights[i]
+	}
+	return nights, nil
+}
+
+// loadMovieNights reads the movie night log, oldest first
+func (a *App) loadMovieNights() ([]MovieNight, error) {
+	var nights []MovieNight
+	if err := a.store.load("nights", &nights); err != nil {
+		return nil, fmt.Errorf("could not load movie nights: %v", err)
+	}
+	return nights, nil
+}
+
+// repetition estimates in [0, 1] how much a film repeats the recent movie
+// nights, newest first; older nights count for less
+func repetition(movie Movie, recent []MovieNight) float64 {
+	worst := 0.0
+	for i, night := range recent {
+		similarity := 0.6 * genreOverlap(movie.Genres, night.Genres)
+		if movie.Director.ID != 0 && movie.Director.ID == night.Director.ID {
+			similarity += 0.4
+		}
+		decay := 1 - float64(i)/float64(len(recent))
+		if similarity*decay > worst {
+			worst = similarity * decay
+		}
+	}
+	return worst
+}
+
+// genreOverlap is the share of genres two films have in common
+func genreOverlap(a, b []string) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	seen := make(map[string]bool, len(a))
+	for _, genre := range a {
+		seen[genre] = true
+	}
+	shared, union := 0, len(a)
+	for _, genre := range b {
+		if seen[genre] {
+			shared++
+		} else {
+			union++
+		}
+	}
+	return float64(shared) / float64(union)
+}
+
+// applyVariety lowers the overlap score of hydrated movies by up to a vote,
+// in proportion to how much they repeat the last recentNights movie nights
+func (a *App) applyVariety(movies []Movie) {
+	nights, err := a.GetMovieNights()
+	if err != nil || len(nights) == 0 {
+		return
+	}
+	if len(nights) > recentNights {
+		nights = nights[:recentNights]
+	}
+	for i := range movies {
+		movies[i].Score -= voteWeight * repetition(movies[i], nights)
+	}
+}
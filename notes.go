@@ -0,0 +1,44 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// MovieNote is the group's annotation on a film, e.g. "Sam has the Blu-ray"
+type MovieNote struct {
+	Key       string    `json:"key"`
+	Note      string    `json:"note"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// SetMovieNote stores a note on a movie; an empty note removes it
+func (a *App) SetMovieNote(key string, note string) error {
+	if key == "" {
+		return fmt.Errorf("no movie key provided")
+	}
+
+	a.notesMu.Lock()
+	defer a.notesMu.Unlock()
+
+	notes, err := a.GetMovieNotes()
+	if err != nil {
+		return err
+	}
+	if note = strings.TrimSpace(note); note == "" {
+		delete(notes, key)
+	} else {
+		notes[key] = MovieNote{Key: key, Note: note, UpdatedAt: time.Now()}
+	}
+	return a.store.save("notes", notes)
+}
+
+// GetMovieNotes returns all notes keyed by movie key
+func (a *App) GetMovieNotes() (map[string]MovieNote, error) {
+	notes := make(map[string]MovieNote)
+	if err := a.store.load("notes", &notes); err != nil {
+		return nil, err
+	}
+	return notes, nil
+}
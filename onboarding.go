@@ -0,0 +1,124 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Scrape politeness bounds, in milliseconds between Letterboxd requests
+const (
+	defaultScrapeDelayMS = 500
+	minScrapeDelayMS     = 250
+)
+
+// regionRegex matches an ISO 3166-1 alpha-2 country code such as "GB"
+var regionRegex = regexp.MustCompile(`^[A-Z]{2}$`)
+
+// OnboardingState tells the frontend which first-run setup steps are done
+type OnboardingState struct {
+	Completed        bool     `json:"completed"`
+	APIKeyConfigured bool     `json:"api_key_configured"`
+	Region           string   `json:"region"`
+	ScrapeDelayMS    int      `json:"scrape_delay_ms"`
+	Usernames        []string `json:"usernames"`
+}
+
+// OnboardingSettings are the choices made in the guided first-run setup
+type OnboardingSettings struct {
+	// TMDBAPIKey is optional; without one, metadata comes from Wikidata
+	TMDBAPIKey    string   `json:"tmdb_api_key"`
+	Region        string   `json:"region"`
+	ScrapeDelayMS int      `json:"scrape_delay_ms"`
+	Usernames     []string `json:"usernames"`
+}
+
+// OnboardingResult is the outcome of completing the setup; when the API key
+// is rejected, Validation explains why and nothing else is saved
+type OnboardingResult struct {
+	State      OnboardingState   `json:"state"`
+	Validation *APIKeyValidation `json:"validation,omitempty"`
+}
+
+// GetOnboardingState reports the first-run setup progress with defaults
+// filled in for steps not yet done
+func (a *App) GetOnboardingState() OnboardingState {
+	settings := a.loadSettings()
+	key := a.getTMDBAPIKey()
+	return OnboardingState{
+		Completed:        settings.OnboardingComplete,
+		APIKeyConfigured: key != "" && len(key) >= 10,
+		Region:           a.region(),
+		ScrapeDelayMS:    scrapeDelayMS(settings),
+		Usernames:        settings.Usernames,
+	}
+}
+
+// CompleteOnboarding validates and saves the first-run setup
+func (a *App) CompleteOnboarding(choices OnboardingSettings) (OnboardingResult, error) {
+	region := strings.ToUpper(strings.TrimSpace(choices.Region))
+	if region == "" {
+		region = certificationRegion
+	}
+	if !regionRegex.MatchString(region) {
+		return OnboardingResult{State: a.GetOnboardingState()}, fmt.Errorf("invalid region '%s', expected a two-letter country code", choices.Region)
+	}
+
+	delay := choices.ScrapeDelayMS
+	if delay == 0 {
+		delay = defaultScrapeDelayMS
+	}
+	if delay < minScrapeDelayMS {
+		delay = minScrapeDelayMS
+	}
+
+	var usernames []string
+	for _, username := range choices.Usernames {
+		if username = strings.TrimSpace(username); username != "" {
+			usernames = append(usernames, username)
+		}
+	}
+
+	var result OnboardingResult
+	if key := strings.TrimSpace(choices.TMDBAPIKey); key != "" {
+		validation, err := a.SetTMDBAPIKey(key)
+		if err != nil {
+			return OnboardingResult{State: a.GetOnboardingState()}, err
+		}
+		result.Validation = &validation
+		if !validation.Valid {
+			result.State = a.GetOnboardingState()
+			return result, nil
+		}
+	}
+
+	settings := a.loadSettings()
+	settings.Region = region
+	settings.ScrapeDelayMS = delay
+	settings.Usernames = usernames
+	settings.OnboardingComplete = true
+	if err := a.saveSettings(settings); err != nil {
+		return OnboardingResult{State: a.GetOnboardingState()}, err
+	}
+	a.letterboxdLimiter.setInterval(time.Duration(delay) * time.Millisecond)
+
+	result.State = a.GetOnboardingState()
+	return result, nil
+}
+
+// region returns the country whose certifications are reported
+func (a *App) region() string {
+	if region := a.loadSettings().Region; region != "" {
+		return region
+	}
+	return certificationRegion
+}
+
+// scrapeDelayMS returns the configured spacing between Letterboxd requests
+func scrapeDelayMS(settings Settings) int {
+	if settings.ScrapeDelayMS < minScrapeDelayMS {
+		return defaultScrapeDelayMS
+	}
+	return settings.ScrapeDelayMS
+}
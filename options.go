@@ -0,0 +1,109 @@
+package main
+
+// Result orders selectable with CompareOptions.SortBy
+const (
+	// SortNewlyAvailable orders results by how recently they became
+	// available digitally in the configured region
+	SortNewlyAvailable = "newly_available"
+	// SortAwards orders results by major award wins, then nominations
+	SortAwards = "awards"
+	// SortAffinity orders results by the group's predicted enjoyment,
+	// learned from the films each participant rated
+	SortAffinity = "affinity"
+)
+
+// voteWeight is a participant's vote in the overlap score unless Weights
+// says otherwise. Boosts and penalties to the score, such as for themes,
+// trending films and variety, are a vote's worth so they sway the order
+// as much as one more participant listing the film would
+const voteWeight = 1.0
+
+// CompareOptions tunes how a comparison is run and scored
+type CompareOptions struct {
+	// Weights gives some participants a bigger vote; missing or non-positive
+	// entries count as 1
+	Weights map[string]float64 `json:"weights"`
+
+	// IncludeRewatches also includes films on fewer than two watchlists when
+	// other participants have already watched and loved them
+	IncludeRewatches bool `json:"include_rewatches"`
+
+	// EntryTypes limits results to the given entry types (e.g. "feature");
+	// empty includes everything. Setting it hydrates results eagerly.
+	EntryTypes []string `json:"entry_types"`
+
+	// Classifications limits results to feature films, shorts or
+	// documentaries (e.g. "documentary"); empty includes everything.
+	// Setting it hydrates results eagerly.
+	Classifications []string `json:"classifications"`
+
+	// ShortsNight keeps only films known to run under 45 minutes. Setting
+	// it hydrates results eagerly.
+	ShortsNight bool `json:"shorts_night"`
+
+	// HideUnreleased drops films that can't be watched yet, such as ones in
+	// production or with a future release date. Setting it hydrates results
+	// eagerly.
+	HideUnreleased bool `json:"hide_unreleased"`
+
+	// SortBy changes the result order; empty sorts by overlap score,
+	// SortNewlyAvailable by digital release date, SortAwards by major
+	// awards and SortAffinity by the group's taste. Sorting by anything but
+	// overlap hydrates results eagerly.
+	SortBy string `json:"sort_by"`
+
+	// Tags limits results to movies carrying any of the given tags
+	Tags []string `json:"tags"`
+
+	// SubscriptionOnly drops films that can only be rented or bought in the
+	// configured region. Setting it hydrates results eagerly.
+	SubscriptionOnly bool `json:"subscription_only"`
+
+	// SubtitleLanguages keeps films with subtitles in any of the given
+	// languages on the configured streaming services. Setting it hydrates
+	// results eagerly.
+	SubtitleLanguages []string `json:"subtitle_languages"`
+
+	// RequireAudioDescription keeps films with an audio-description track on
+	// the configured streaming services. Setting it hydrates results eagerly.
+	RequireAudioDescription bool `json:"require_audio_description"`
+
+	// Theme limits results to films fitting a seasonal theme (e.g.
+	// "halloween"); see SuggestTheme. Setting it hydrates results eagerly.
+	Theme string `json:"theme"`
+
+	// ThemeBoost ranks films fitting the theme higher instead of dropping
+	// the rest
+	ThemeBoost bool `json:"theme_boost"`
+
+	// VarietyBoost down-ranks films too similar in genre or director to the
+	// last few recorded movie nights. Setting it hydrates results eagerly.
+	VarietyBoost bool `json:"variety_boost"`
+
+	// TrendingBoost ranks films popular on Letterboxd this week higher
+	TrendingBoost bool `json:"trending_boost"`
+
+	// AwardWinners keeps films that won a major award, such as an Oscar or a
+	// festival's top prize. Setting it hydrates results eagerly.
+	AwardWinners bool `json:"award_winners"`
+
+	// RatingFilter keeps films whose critic and audience scores satisfy it;
+	// nil keeps everything. Setting it hydrates results eagerly.
+	RatingFilter *RatingFilter `json:"rating_filter"`
+
+	// SeparateEditions lists director's cuts, extended editions and
+	// re-releases as films of their own instead of merging them into the
+	// film, where they are listed in Movie.Editions
+	SeparateEditions bool `json:"separate_editions"`
+
+	// Preset names the pipeline preset (e.g. "quick"); empty is "standard"
+	Preset string `json:"preset"`
+}
+
+// weight returns the vote weight of a participant
+func (o CompareOptions) weight(username string) float64 {
+	if w, ok := o.Weights[username]; ok && w > 0 {
+		return w
+	}
+	return voteWeight
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+const (
+	// defaultPageSize is used when no page size is given
+	defaultPageSize = 50
+	// maxPageSize caps the movies returned in one page
+	maxPageSize = 200
+)
+
+// Result page orders selectable with ResultQuery.SortBy
+const (
+	PageSortTitle   = "title"
+	PageSortRating  = "rating"
+	PageSortYear    = "year"
+	PageSortRuntime = "runtime"
+)
+
+// ResultQuery filters and orders the movies of a comparison session before
+// paging; the zero value keeps every movie in comparison order. Only
+// hydrated results have the genres, ratings and runtimes filtered on
+type ResultQuery struct {
+	// Search keeps movies whose title contains it, ignoring case
+	Search string `json:"search"`
+	// Genres keeps movies in any of the given genres
+	Genres []string `json:"genres"`
+	// Decades keeps movies released in any of the given decades, e.g. 1990
+	Decades []int `json:"decades"`
+	// Classifications keeps feature films, shorts or documentaries, e.g.
+	// "short"
+	Classifications []string `json:"classifications"`
+	// MinRating keeps movies rated at least this on TMDB
+	MinRating float64 `json:"min_rating"`
+	// MaxRuntime keeps movies at most this many minutes long; zero keeps all
+	MaxRuntime int `json:"max_runtime"`
+	// SortBy orders the movies by PageSortTitle, PageSortRating,
+	// PageSortYear or PageSortRuntime; empty keeps the comparison order
+	SortBy string `json:"sort_by"`
+	// Descending reverses SortBy
+	Descending bool `json:"descending"`
+}
+
+// ResultsPage is one page of a comparison session's movies
+type ResultsPage struct {
+	SessionID string `json:"session_id"`
+	// Page counts from 1
+	Page     int `json:"page"`
+	PageSize int `json:"page_size"`
+	// Total is the number of movies matching the query
+	Total      int     `json:"total"`
+	TotalPages int     `json:"total_pages"`
+	Movies     []Movie `json:"movies"`
+}
+
+// GetResultsPage returns a page of a comparison session's movies in result
+// order
+func (a *App) GetResultsPage(sessionID string, page int, pageSize int) (ResultsPage, error) {
+	return a.GetResultsPageWithQuery(sessionID, page, pageSize, ResultQuery{})
+}
+
+// GetResultsPageWithQuery is GetResultsPage with the movies filtered and
+// sorted by the query first
+func (a *App) GetResultsPageWithQuery(sessionID string, page int, pageSize int, query ResultQuery) (ResultsPage, error) {
+	if pageSize <= 0 {
+		pageSize = defaultPageSize
+	}
+	if pageSize > maxPageSize {
+		pageSize = maxPageSize
+	}
+	if page < 1 {
+		return ResultsPage{}, fmt.Errorf("pages count from 1")
+	}
+	movies, err := a.sessionMovies(sessionID)
+	if err != nil {
+		return ResultsPage{}, err
+	}
+	matching, err := query.apply(movies)
+	if err != nil {
+		return ResultsPage{}, err
+	}
+
+	result := ResultsPage{
+		SessionID:  sessionID,
+		Page:       page,
+		PageSize:   pageSize,
+		Total:      len(matching),
+		TotalPages: (len(matching) + pageSize - 1) / pageSize,
+	}
+	if from := (page - 1) * pageSize; from < len(matching) {
+		result.Movies = matching[from:min(from+pageSize, len(matching))]
+	}
+	return result, nil
+}
+
+// sessionMovies returns the movies of a comparison session
+func (a *App) sessionMovies(sessionID string) ([]Movie, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	session, err := a.sessionLocked(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	return session.movies, nil
+}
+
+// apply returns the movies matching the query, in its order, without
+// modifying the given slice
+func (q ResultQuery) apply(movies []Movie) ([]Movie, error) {
+	search := strings.ToLower(strings.TrimSpace(q.Search))
+	var matching []Movie
+	for _, movie := range movies {
+		if search != "" && !strings.Contains(strings.ToLower(movie.Title), search) {
+			continue
+		}
+		if len(q.Genres) > 0 && !anyGenre(movie.Genres, q.Genres) {
+			continue
+		}
+		if decade, ok := movieDecade(movie); len(q.Decades) > 0 && (!ok || !slices.Contains(q.Decades, decade)) {
+			continue
+		}
+		if len(q.Classifications) > 0 && !slices.Contains(q.Classifications, movie.Classification) {
+			continue
+		}
+		if q.MinRating > 0 && movie.Rating < q.MinRating {
+			continue
+		}
+		if q.MaxRuntime > 0 && (movie.Runtime == 0 || movie.Runtime > q.MaxRuntime) {
+			continue
+		}
+		matching = append(matching, movie)
+	}
+
+	var less func(a, b Movie) bool
+	switch q.SortBy {
+	case "":
+		return matching, nil
+	case PageSortTitle:
+		less = func(a, b Movie) bool { return strings.ToLower(a.Title) < strings.ToLower(b.Title) }
+	case PageSortRating:
+		less = func(a, b Movie) bool { return a.Rating < b.Rating }
+	case PageSortYear:
+		less = func(a, b Movie) bool { return a.ReleaseYear < b.ReleaseYear }
+	case PageSortRuntime:
+		less = func(a, b Movie) bool { return a.Runtime < b.Runtime }
+	default:
+		return nil, fmt.Errorf("unknown sort order '%s'", q.SortBy)
+	}
+	sort.SliceStable(matching, func(i, j int) bool {
+		if q.Descending {
+			return less(matching[j], matching[i])
+		}
+		return less(matching[i], matching[j])
+	})
+	return matching, nil
+}
+
+// anyGenre reports whether a movie's genres include any of the wanted
+// ones, ignoring case
+func anyGenre(genres, wanted []string) bool {
+	for _, genre := range genres {
+		for _, w := range wanted {
+			if strings.EqualFold(genre, w) {
+				return true
+			}
+		}
+	}
+	return false
+}
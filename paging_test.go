@@ -0,0 +1,54 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestResultQueryApply(t *testing.T) {
+	movies := []Movie{
+		{Key: "heat", Title: "Heat", ReleaseYear: "1995", Genres: []string{"Crime"}, Rating: 7.9, Runtime: 170},
+		{Key: "alien", Title: "Alien", ReleaseYear: "1979", Genres: []string{"Horror", "Science Fiction"}, Rating: 8.2, Runtime: 117},
+		{Key: "ran", Title: "Ran", ReleaseYear: "1985", Genres: []string{"Drama", "War"}, Rating: 8.2, Runtime: 162},
+		{Key: "aliens", Title: "Aliens", ReleaseYear: "1986", Genres: []string{"Science Fiction"}, Rating: 7.9, Runtime: 137},
+		{Key: "bare", Title: "Bare"},
+	}
+
+	tests := []struct {
+		name    string
+		query   ResultQuery
+		want    []string
+		wantErr bool
+	}{
+		{name: "zero query", query: ResultQuery{}, want: []string{"heat", "alien", "ran", "aliens", "bare"}},
+		{name: "search", query: ResultQuery{Search: " ALIEN "}, want: []string{"alien", "aliens"}},
+		{name: "genre", query: ResultQuery{Genres: []string{"science fiction", "war"}}, want: []string{"alien", "ran", "aliens"}},
+		{name: "decade", query: ResultQuery{Decades: []int{1980}}, want: []string{"ran", "aliens"}},
+		{name: "minimum rating", query: ResultQuery{MinRating: 8}, want: []string{"alien", "ran"}},
+		{name: "maximum runtime", query: ResultQuery{MaxRuntime: 140}, want: []string{"alien", "aliens"}},
+		{name: "by title", query: ResultQuery{SortBy: PageSortTitle}, want: []string{"alien", "aliens", "bare", "heat", "ran"}},
+		{name: "by rating, stable", query: ResultQuery{SortBy: PageSortRating, Descending: true}, want: []string{"alien", "ran", "heat", "aliens", "bare"}},
+		{name: "by year", query: ResultQuery{Search: "a", SortBy: PageSortYear}, want: []string{"bare", "alien", "ran", "aliens", "heat"}},
+		{name: "by runtime", query: ResultQuery{MaxRuntime: 200, SortBy: PageSortRuntime, Descending: true}, want: []string{"heat", "ran", "aliens", "alien"}},
+		{name: "unknown order", query: ResultQuery{SortBy: "popularity"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.query.apply(movies)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("apply() error = %v, want error %v", err, tt.wantErr)
+			}
+			var keys []string
+			for _, movie := range got {
+				keys = append(keys, movie.Key)
+			}
+			if !slices.Equal(keys, tt.want) {
+				t.Errorf("apply() = %v, want %v", keys, tt.want)
+			}
+		})
+	}
+
+	if movies[0].Key != "heat" {
+		t.Error("apply() reordered the given movies")
+	}
+}
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+// PinMovie keeps a movie at the top of comparison results
+func (a *App) PinMovie(key string) error {
+	_, err := a.BulkPin([]string{key}, true)
+	return err
+}
+
+// UnpinMovie releases a pinned movie
+func (a *App) UnpinMovie(key string) error {
+	_, err := a.BulkPin([]string{key}, false)
+	return err
+}
+
+// loadPins reads the pin times keyed by movie key
+func (a *App) loadPins() (map[string]time.Time, error) {
+	pins := make(map[string]time.Time)
+	if err := a.store.load("pins", &pins); err != nil {
+		return nil, fmt.Errorf("could not load pins: %v", err)
+	}
+	return pins, nil
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"html"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+const (
+	// placeholderLineLength is the number of characters per wrapped title line
+	placeholderLineLength = 16
+	// placeholderMaxLines caps the title lines drawn on a placeholder
+	placeholderMaxLines = 5
+)
+
+// defaultPlaceholderTemplate draws the title centred on a plain poster-sized card
+const defaultPlaceholderTemplate = `<svg xmlns="http://www.w3.org/2000/svg" width="500" height="750" viewBox="0 0 500 750">
+<rect width="500" height="750" fill="{{.Background}}"/>
+<rect x="20" y="20" width="460" height="710" fill="none" stroke="{{.Foreground}}" stroke-opacity="0.2" stroke-width="2"/>
+<text x="250" y="{{.Top}}" fill="{{.Foreground}}" font-family="Nunito, sans-serif" font-size="40" font-weight="700" text-anchor="middle">{{range .Lines}}<tspan x="250" dy="52">{{.}}</tspan>{{end}}</text>
+</svg>`
+
+// placeholderData is passed to placeholder templates
+type placeholderData struct {
+	Title      string
+	Lines      []string
+	Top        int
+	Background string
+	Foreground string
+}
+
+// placeholderPoster renders an offline placeholder with the title on it and
+// returns it as a data URI. A custom placeholder.svg template in the data
+// directory replaces the built-in design.
+func (a *App) placeholderPoster(title string) string {
+	settings := a.loadSettings()
+	data := placeholderData{
+		Title:      html.EscapeString(title),
+		Lines:      wrapTitle(title),
+		Background: html.EscapeString(settings.PlaceholderBackground),
+		Foreground: html.EscapeString(settings.PlaceholderForeground),
+	}
+	if data.Background == "" {
+		data.Background = "#1f1f1f"
+	}
+	if data.Foreground == "" {
+		data.Foreground = "#ffffff"
+	}
+	data.Top = 375 - len(data.Lines)*52/2 - 40
+
+	source := defaultPlaceholderTemplate
+	if custom, err := os.ReadFile(filepath.Join(a.store.folder(), "placeholder.svg")); err == nil {
+		source = string(custom)
+	}
+
+	tmpl, err := template.New("placeholder").Parse(source)
+	if err != nil {
+		log.Printf("Invalid placeholder template, using the default: %v", err)
+		tmpl = template.Must(template.New("placeholder").Parse(defaultPlaceholderTemplate))
+	}
+
+	var svg bytes.Buffer
+	if err := tmpl.Execute(&svg, data); err != nil {
+		log.Printf("Could not render placeholder for '%s': %v", title, err)
+	}
+	return "data:image/svg+xml;base64," + base64.StdEncoding.EncodeToString(svg.Bytes())
+}
+
+// wrapTitle splits a title into escaped lines short enough for the placeholder
+func wrapTitle(title string) []string {
+	var lines []string
+	var current string
+	for _, word := range strings.Fields(title) {
+		if current != "" && len([]rune(current))+1+len([]rune(word)) > placeholderLineLength {
+			lines = append(lines, current)
+			current = word
+		} else if current == "" {
+			current = word
+		} else {
+			current += " " + word
+		}
+	}
+	if current != "" {
+		lines = append(lines, current)
+	}
+
+	if len(lines) > placeholderMaxLines {
+		lines = lines[:placeholderMaxLines]
+		lines[placeholderMaxLines-1] += "…"
+	}
+	for i, line := range lines {
+		lines[i] = html.EscapeString(line)
+	}
+	return lines
+}
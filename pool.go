@@ -0,0 +1,60 @@
+package main
+
+import "sync"
+
+// scrapeWorkers is the number of Letterboxd users scraped concurrently
+const scrapeWorkers = 4
+
+// forEachUser runs fn for every username on a bounded pool of workers, each
+// call paced by the shared Letterboxd rate limiter; a non-positive worker
+// count uses scrapeWorkers
+func (a *App) forEachUser(usernames []string, workers int, fn func(username string)) {
+	jobs := make(chan string)
+	var wg sync.WaitGroup
+
+	if workers <= 0 {
+		workers = scrapeWorkers
+	}
+	for i := 0; i < workers && i < len(usernames); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for username := range jobs {
+				a.letterboxdLimiter.wait()
+				fn(username)
+			}
+		}()
+	}
+
+	for _, username := range usernames {
+		jobs <- username
+	}
+	close(jobs)
+	wg.Wait()
+}
+
+// forEachIndex runs fn for every index below n on a bounded pool of
+// workers; a non-positive worker count uses enrichWorkers
+func forEachIndex(n int, workers int, fn func(index int)) {
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+
+	if workers <= 0 {
+		workers = enrichWorkers
+	}
+	for i := 0; i < workers && i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range jobs {
+				fn(index)
+			}
+		}()
+	}
+
+	for i := 0; i < n; i++ {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+}
@@ -0,0 +1,115 @@
+package main
+
+import "fmt"
+
+// Preset names selectable per comparison
+const (
+	PresetStandard = "standard"
+	PresetQuick    = "quick"
+	PresetThorough = "thorough"
+	PresetOffline  = "offline"
+)
+
+// Preset trades completeness for speed across the comparison pipeline
+type Preset struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+
+	// Hydrate fetches details for every result during the run instead of
+	// leaving them to be loaded on demand
+	Hydrate bool `json:"hydrate"`
+
+	// ReuseUnchanged skips rescraping watchlists whose first page hasn't
+	// changed since the last run
+	ReuseUnchanged bool `json:"reuse_unchanged"`
+
+	// Offline uses the last saved watchlists and never touches the network
+	Offline bool `json:"offline"`
+
+	// Workers is the number of users scraped and films enriched concurrently
+	Workers int `json:"workers"`
+
+	// Providers overrides the metadata provider chain; empty uses the
+	// configured one
+	Providers []string `json:"providers"`
+}
+
+// presets are the built-in pipeline presets
+var presets = map[string]Preset{
+	PresetStandard: {
+		Name:           PresetStandard,
+		Description:    "Reuses unchanged watchlists and loads details as results are viewed",
+		ReuseUnchanged: true,
+		Workers:        4,
+	},
+	PresetQuick: {
+		Name:           PresetQuick,
+		Description:    "Fastest results: more parallel requests and TMDB only",
+		ReuseUnchanged: true,
+		Workers:        8,
+		Providers:      []string{"tmdb"},
+	},
+	PresetThorough: {
+		Name:        PresetThorough,
+		Description: "Rescrapes every watchlist and fetches all details before showing results",
+		Hydrate:     true,
+		Workers:     2,
+	},
+	PresetOffline: {
+		Name:        PresetOffline,
+		Description: "Uses the last saved watchlists without any network requests",
+		Offline:     true,
+		Workers:     1,
+	},
+}
+
+// GetPresets returns the available comparison presets, fastest first
+func (a *App) GetPresets() []Preset {
+	names := []string{PresetQuick, PresetStandard, PresetThorough, PresetOffline}
+	list := make([]Preset, 0, len(names))
+	for _, name := range names {
+		list = append(list, presets[name])
+	}
+	return list
+}
+
+// presetFor resolves a preset by name; an empty name is the standard preset
+func presetFor(name string) (Preset, error) {
+	if name == "" {
+		name = PresetStandard
+	}
+	preset, ok := presets[name]
+	if !ok {
+		return Preset{}, fmt.Errorf("unknown comparison preset '%s'", name)
+	}
+	return preset, nil
+}
+
+// chain returns the metadata providers a preset enriches with
+func (p Preset) chain(a *App) []MetadataProvider {
+	if p.Offline {
+		return nil
+	}
+	if len(p.Providers) == 0 {
+		return a.providers()
+	}
+	var chain []MetadataProvider
+	for _, name := range p.Providers {
+		if provider, err := a.newProvider(name); err == nil {
+			chain = append(chain, provider)
+		}
+	}
+	return chain
+}
+
+// savedWatchlist returns the watchlist saved by a user's last refresh
+func (a *App) savedWatchlist(username string) (map[string]WatchlistEntry, bool) {
+	a.historyMu.Lock()
+	defer a.historyMu.Unlock()
+
+	var history watchlistHistory
+	if err := a.store.load(historyName(username), &history); err != nil || history.Snapshot == nil {
+		return nil, false
+	}
+	return history.Snapshot, true
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gocolly/colly/v2"
+)
+
+// userProfile is the user information shown in the header of their
+// Letterboxd pages, picked up while scraping the watchlist
+type userProfile struct {
+	Avatar        string `json:"avatar"`
+	DisplayName   string `json:"display_name"`
+	Location      string `json:"location"`
+	WatchlistSize int    `json:"watchlist_size"`
+}
+
+// user returns the profile as a User for the given username
+func (p userProfile) user(username string) User {
+	return User{
+		Name:          username,
+		Avatar:        p.Avatar,
+		DisplayName:   p.DisplayName,
+		Location:      p.Location,
+		WatchlistSize: p.WatchlistSize,
+	}
+}
+
+// onProfileHeader fills profile from the page header of a user's watchlist,
+// so the profile page itself doesn't need a separate visit; the location is
+// only shown in the full header on the profile page
+func onProfileHeader(c *colly.Collector, selectors Selectors, profile *userProfile) {
+	c.OnHTML(selectors.ProfileAvatar, func(e *colly.HTMLElement) {
+		if profile.Avatar == "" {
+			profile.Avatar = e.Attr("src")
+		}
+		if profile.DisplayName == "" {
+			profile.DisplayName = strings.TrimSpace(e.Attr("alt"))
+		}
+	})
+
+	c.OnHTML(selectors.OGImage, func(e *colly.HTMLElement) {
+		if profile.Avatar == "" {
+			profile.Avatar = e.Attr("content")
+		}
+	})
+
+	c.OnHTML(selectors.ProfileName, func(e *colly.HTMLElement) {
+		if name := strings.TrimSpace(e.Text); name != "" {
+			profile.DisplayName = name
+		}
+	})
+
+	c.OnHTML(selectors.ProfileLocation, func(e *colly.HTMLElement) {
+		if profile.Location == "" {
+			profile.Location = strings.TrimSpace(e.Text)
+		}
+	})
+
+	c.OnHTML(selectors.WatchlistCount, func(e *colly.HTMLElement) {
+		if profile.WatchlistSize == 0 {
+			profile.WatchlistSize = parseCount(e.Text)
+		}
+	})
+}
+
+// GetUserProfile scrapes a user's Letterboxd profile page for their display
+// name, location and watchlist size
+func (a *App) GetUserProfile(username string) (User, error) {
+	defer a.metrics.since("profile_scrape", time.Now())
+	c := a.newCollector()
+
+	rules := a.ruleset()
+	var profile userProfile
+	var scrapeErr error
+	onProfileHeader(c, rules.Selectors, &profile)
+
+	c.OnError(func(r *colly.Response, e error) {
+		a.metrics.countError("scrape")
+		scrapeErr = fmt.Errorf("could not fetch profile for '%s': %v", username, e)
+	})
+
+	if err := c.Visit(forUser(rules.URLs.Profile, username)); err != nil {
+		return User{}, fmt.Errorf("could not visit profile for '%s': %v", username, err)
+	}
+	if scrapeErr != nil {
+		return User{}, scrapeErr
+	}
+
+	return profile.user(username), nil
+}
+
+// parseCount extracts a number such as "1,204 films" from header text
+func parseCount(text string) int {
+	var digits strings.Builder
+	for _, r := range text {
+		switch {
+		case r >= '0' && r <= '9':
+			digits.WriteRune(r)
+		case r == ',' || r == '.':
+		case digits.Len() > 0:
+			n, _ := strconv.Atoi(digits.String())
+			return n
+		}
+	}
+	n, _ := strconv.Atoi(digits.String())
+	return n
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	// DefaultProfile is the profile used until another one is switched to;
+	// its data lives directly in the data directory
+	DefaultProfile = "default"
+	// profileFile, kept in the local data directory, names the active
+	// profile on this machine
+	profileFile = "profile"
+)
+
+// profileName matches valid profile names, which are used as directory
+// names
+var profileName = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,31}$`)
+
+// GetProfiles returns the local profiles, the default one first
+func (a *App) GetProfiles() []string {
+	profiles := []string{DefaultProfile}
+	entries, _ := os.ReadDir(filepath.Join(dataDir(), "profiles"))
+	var others []string
+	for _, entry := range entries {
+		if entry.IsDir() && profileName.MatchString(entry.Name()) && entry.Name() != DefaultProfile {
+			others = append(others, entry.Name())
+		}
+	}
+	sort.Strings(others)
+	return append(profiles, others...)
+}
+
+// GetActiveProfile returns the profile in use on this machine
+func (a *App) GetActiveProfile() string {
+	return activeProfile()
+}
+
+// SwitchProfile switches to a local profile, creating it if it doesn't
+// exist. Each profile has its own settings and API keys, friend groups,
+// caches and history, so people sharing a computer don't mix friend groups
+func (a *App) SwitchProfile(name string) error {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if !profileName.MatchString(name) {
+		return fmt.Errorf("profile names must be 1-32 letters, digits, '-' or '_'")
+	}
+	if name == activeProfile() {
+		return nil
+	}
+
+	pointer := filepath.Join(GetDataDir(), profileFile)
+	if name == DefaultProfile {
+		if err := os.Remove(pointer); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("could not switch profile: %v", err)
+		}
+	} else {
+		if err := os.MkdirAll(GetDataDir(), 0o755); err != nil {
+			return fmt.Errorf("could not create data directory: %v", err)
+		}
+		if err := os.WriteFile(pointer, []byte(name), 0o644); err != nil {
+			return fmt.Errorf("could not switch profile: %v", err)
+		}
+	}
+
+	a.store.release()
+	base := dataDir()
+	a.store.moveTo(profileDataDir(base, name), base != GetDataDir())
+	if err := migrateStore(a.store); err != nil {
+		return err
+	}
+	a.scrapeCache.moveTo(filepath.Join(profileCacheDir(name), "pages"))
+	a.tmdbCache.moveTo(filepath.Join(profileCacheDir(name), "tmdb"))
+	a.applySettings()
+	a.resetState()
+	return nil
+}
+
+// activeProfile returns the profile in use on this machine
+func activeProfile() string {
+	data, err := os.ReadFile(filepath.Join(GetDataDir(), profileFile))
+	if err != nil {
+		return DefaultProfile
+	}
+	if name := strings.TrimSpace(string(data)); profileName.MatchString(name) {
+		return name
+	}
+	return DefaultProfile
+}
+
+// profileDataDir returns where a profile keeps its data under a data
+// directory
+func profileDataDir(base, profile string) string {
+	if profile == DefaultProfile {
+		return base
+	}
+	return filepath.Join(base, "profiles", profile)
+}
+
+// profileCacheDir returns the directory holding a profile's caches
+func profileCacheDir(profile string) string {
+	if profile == DefaultProfile {
+		return GetCacheDir()
+	}
+	return filepath.Join(GetCacheDir(), "profiles", profile)
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"strings"
+	"time"
+)
+
+// defaultPprofAddr is where the pprof endpoints listen when profiling; it
+// is bound to localhost since profiles expose internals
+const defaultPprofAddr = "localhost:6060"
+
+// SetProfiling turns the debug profiling setting on or off: it serves the
+// pprof endpoints on localhost and logs the timing of every pipeline stage
+func (a *App) SetProfiling(enabled bool) error {
+	settings := a.loadSettings()
+	settings.Profiling = enabled
+	if err := a.saveSettings(settings); err != nil {
+		return err
+	}
+	return a.applyProfiling(settings)
+}
+
+// forceProfiling enables profiling for this run regardless of the setting,
+// for the --profile flag
+func (a *App) forceProfiling(addr string) error {
+	a.pprofMu.Lock()
+	a.profilingForced = true
+	if addr != "" {
+		a.pprofAddr = addr
+	}
+	a.pprofMu.Unlock()
+	return a.applyProfiling(a.loadSettings())
+}
+
+// profiling reports whether profiling is on
+func (a *App) profiling() bool {
+	a.pprofMu.Lock()
+	defer a.pprofMu.Unlock()
+	return a.pprofServer != nil
+}
+
+// applyProfiling starts or stops the pprof server to match the setting
+func (a *App) applyProfiling(settings Settings) error {
+	a.pprofMu.Lock()
+	defer a.pprofMu.Unlock()
+
+	enabled := a.profilingForced || settings.Profiling
+	if !enabled && a.pprofServer != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		err := a.pprofServer.Shutdown(ctx)
+		a.pprofServer = nil
+		return err
+	}
+	if !enabled || a.pprofServer != nil {
+		return nil
+	}
+
+	addr := a.pprofAddr
+	if addr == "" {
+		addr = defaultPprofAddr
+	}
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("could not start pprof server: %v", err)
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+
+	a.pprofServer = &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	go func(server *http.Server) {
+		if err := server.Serve(listener); err != nil && err != http.ErrServerClosed {
+			log.Printf("pprof server stopped: %v", err)
+		}
+	}(a.pprofServer)
+	log.Printf("Profiling enabled, pprof at http://%s/debug/pprof/", listener.Addr())
+	return nil
+}
+
+// stageTimer times the stages of a pipeline run. Each stage is recorded in
+// the metrics and, while profiling, the run is logged as one line of
+// key=value pairs
+type stageTimer struct {
+	app      *App
+	pipeline string
+	start    time.Time
+	last     time.Time
+	stages   []string
+}
+
+// newStageTimer starts timing a pipeline run
+func (a *App) newStageTimer(pipeline string) *stageTimer {
+	now := time.Now()
+	return &stageTimer{app: a, pipeline: pipeline, start: now, last: now}
+}
+
+// mark ends the current stage
+func (t *stageTimer) mark(stage string) {
+	now := time.Now()
+	elapsed := now.Sub(t.last)
+	t.last = now
+	t.app.metrics.observe(t.pipeline+"_"+stage, elapsed)
+	t.stages = append(t.stages, fmt.Sprintf("%s=%s", stage, elapsed.Round(time.Millisecond)))
+}
+
+// done logs the stage timings of the run while profiling
+func (t *stageTimer) done(fields ...string) {
+	if !t.app.profiling() {
+		return
+	}
+	line := append([]string{"pipeline=" + t.pipeline}, fields...)
+	line = append(line, t.stages...)
+	line = append(line, "total="+time.Since(t.start).Round(time.Millisecond).String())
+	log.Print(strings.Join(line, " "))
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+)
+
+// MetadataProvider resolves films against an external metadata database
+type MetadataProvider interface {
+	// Name identifies the provider in settings and logs
+	Name() string
+
+	// SearchMovie returns the provider's ID for a title released in year
+	// (if known)
+	SearchMovie(title string, year string) (string, error)
+
+	// GetDetails returns the metadata fields of a Movie for a provider ID
+	GetDetails(id string, title string) (Movie, error)
+}
+
+// defaultProviders is the provider chain used when none is configured;
+// Wikidata takes over when no TMDB key is set or TMDB is unavailable
+var defaultProviders = []string{"tmdb", "wikidata"}
+
+// newProvider creates a provider by name
+func (a *App) newProvider(name string) (MetadataProvider, error) {
+	switch strings.ToLower(name) {
+	case "tmdb":
+		return &tmdbProvider{app: a}, nil
+	case "wikidata":
+		return newWikidataProvider(a), nil
+	default:
+		return nil, fmt.Errorf("unknown metadata provider: '%s'", name)
+	}
+}
+
+// SetMetadataProviders configures the provider chain, tried in order; an
+// empty chain restores the default
+func (a *App) SetMetadataProviders(names []string) error {
+	for _, name := range names {
+		if _, err := a.newProvider(name); err != nil {
+			return err
+		}
+	}
+	settings := a.loadSettings()
+	settings.MetadataProviders = names
+	return a.saveSettings(settings)
+}
+
+// providers returns the configured provider chain, tried in order
+func (a *App) providers() []MetadataProvider {
+	names := a.loadSettings().MetadataProviders
+	if len(names) == 0 {
+		names = defaultProviders
+	}
+
+	var chain []MetadataProvider
+	for _, name := range names {
+		provider, err := a.newProvider(name)
+		if err != nil {
+			log.Printf("Skipping metadata provider: %v", err)
+			continue
+		}
+		chain = append(chain, provider)
+	}
+	return chain
+}
+
+// lookupMetadata resolves a film with the first provider in the chain that
+// knows it, so an outage of one provider doesn't leave films without details
+func (a *App) lookupMetadata(chain []MetadataProvider, title string, year string) (Movie, error) {
+	var errs []string
+	for _, provider := range chain {
+		id, err := provider.SearchMovie(title, year)
+		if err == nil {
+			var details Movie
+			if details, err = provider.GetDetails(id, title); err == nil {
+				return details, nil
+			}
+		}
+		errs = append(errs, fmt.Sprintf("%s: %v", provider.Name(), err))
+	}
+	if len(errs) == 0 {
+		return Movie{}, fmt.Errorf("no metadata providers configured")
+	}
+	return Movie{}, fmt.Errorf("no provider could resolve '%s': %s", title, strings.Join(errs, "; "))
+}
+
+// mergeMetadata copies provider metadata into a movie, keeping its
+// Letterboxd identity and comparison fields
+func mergeMetadata(movie *Movie, details Movie) {
+	movie.TMDBID = details.TMDBID
+	movie.Rating = details.Rating
+	movie.VoteCount = details.VoteCount
+	movie.IMDbRating = details.IMDbRating
+	movie.FormattedRating = details.FormattedRating
+	movie.PosterURL = details.PosterURL
+	movie.BackdropURL = details.BackdropURL
+	movie.LogoURL = details.LogoURL
+	movie.ReleaseDate = details.ReleaseDate
+	movie.ReleaseYear = details.ReleaseYear
+	movie.Runtime = details.Runtime
+	movie.FormattedRuntime = details.FormattedRuntime
+	movie.Genres = details.Genres
+	movie.IMDBID = details.IMDBID
+	movie.OriginalLanguage = details.OriginalLanguage
+	movie.Certification = details.Certification
+	movie.EntryType = details.EntryType
+	movie.Classification = details.Classification
+	movie.Artist = details.Artist
+	movie.Themes = details.Themes
+	movie.Keywords = details.Keywords
+	movie.Status = details.Status
+	movie.DigitalReleaseDate = details.DigitalReleaseDate
+	movie.PhysicalReleaseDate = details.PhysicalReleaseDate
+	movie.Overview = details.Overview
+	movie.Director = details.Director
+	movie.Cast = details.Cast
+}
+
+// tmdbProvider is the default provider, backed by the TMDB API
+type tmdbProvider struct {
+	app *App
+}
+
+// Name implements MetadataProvider
+func (p *tmdbProvider) Name() string {
+	return "tmdb"
+}
+
+// SearchMovie implements MetadataProvider
+func (p *tmdbProvider) SearchMovie(title string, year string) (string, error) {
+	p.app.tmdbLimiter.wait()
+	id, err := p.app.searchTMDB(title, year)
+	if err != nil {
+		return "", err
+	}
+	return strconv.Itoa(id), nil
+}
+
+// GetDetails implements MetadataProvider
+func (p *tmdbProvider) GetDetails(id string, title string) (Movie, error) {
+	movieID, err := strconv.Atoi(id)
+	if err != nil {
+		return Movie{}, fmt.Errorf("invalid TMDB ID '%s'", id)
+	}
+	details, err := p.app.fetchTMDBDetails(movieID, detailAppends)
+	if err != nil {
+		return Movie{}, err
+	}
+	return p.app.movieFromTMDB(details, title), nil
+}
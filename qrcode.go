@@ -0,0 +1,32 @@
+package main
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	qrcode "github.com/skip2/go-qrcode"
+)
+
+// qrScale is the size in pixels of a module in generated QR codes, large
+// enough to scan from across the room on a TV
+const qrScale = 8
+
+// GenerateQRCode renders a URL, such as a Letterboxd page, trailer or the
+// LAN voting page, as a QR code PNG encoded in base64
+func (a *App) GenerateQRCode(url string) (string, error) {
+	url = strings.TrimSpace(url)
+	if url == "" {
+		return "", fmt.Errorf("no URL provided")
+	}
+	code, err := qrcode.New(url, qrcode.Medium)
+	if err != nil {
+		return "", fmt.Errorf("could not encode QR code: %v", err)
+	}
+	// A negative size sets the pixels per module rather than the image width
+	data, err := code.PNG(-qrScale)
+	if err != nil {
+		return "", fmt.Errorf("could not render QR code: %v", err)
+	}
+	return base64.StdEncoding.EncodeToString(data), nil
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// lowBudgetFraction is the share of a budget below which requests are paced
+const lowBudgetFraction = 0.2
+
+// QuotaStatus reports how much of a request budget is left
+type QuotaStatus struct {
+	Name          string `json:"name"`
+	Limit         int    `json:"limit"`
+	Used          int    `json:"used"`
+	Remaining     int    `json:"remaining"`
+	WindowSeconds int    `json:"window_seconds"`
+	// ServerLimit and ServerRemaining come from rate-limit response headers,
+	// -1 when the service doesn't send them
+	ServerLimit     int       `json:"server_limit"`
+	ServerRemaining int       `json:"server_remaining"`
+	PausedUntil     time.Time `json:"paused_until"`
+	PacedRequests   int       `json:"paced_requests"`
+}
+
+// requestBudget is a sliding-window request budget that slows callers down
+// as it runs low instead of letting them hit the service's rate limit
+type requestBudget struct {
+	mu              sync.Mutex
+	name            string
+	limit           int
+	window          time.Duration
+	requests        []time.Time
+	serverLimit     int
+	serverRemaining int
+	pausedUntil     time.Time
+	paced           int
+}
+
+// newRequestBudget creates a budget of limit requests per window
+func newRequestBudget(name string, limit int, window time.Duration) *requestBudget {
+	return &requestBudget{name: name, limit: limit, window: window, serverLimit: -1, serverRemaining: -1}
+}
+
+// acquire waits until the budget allows another request and records it
+func (b *requestBudget) acquire() {
+	paced := false
+	for {
+		b.mu.Lock()
+		now := time.Now()
+		b.prune(now)
+
+		var delay time.Duration
+		switch {
+		case now.Before(b.pausedUntil):
+			delay = b.pausedUntil.Sub(now)
+		case len(b.requests) >= b.limit:
+			delay = b.requests[0].Add(b.window).Sub(now)
+		case !paced && b.low():
+			// Spread the remaining budget out instead of spending it in a burst
+			delay = b.window / time.Duration(b.limit)
+		}
+
+		if delay <= 0 {
+			b.requests = append(b.requests, now)
+			b.mu.Unlock()
+			return
+		}
+		if !paced {
+			b.paced++
+			paced = true
+		}
+		b.mu.Unlock()
+		time.Sleep(delay)
+	}
+}
+
+// low reports whether the internal or server-reported budget is nearly spent
+func (b *requestBudget) low() bool {
+	if float64(b.limit-len(b.requests)) <= float64(b.limit)*lowBudgetFraction {
+		return true
+	}
+	return b.serverLimit > 0 && b.serverRemaining >= 0 &&
+		float64(b.serverRemaining) <= float64(b.serverLimit)*lowBudgetFraction
+}
+
+// prune drops requests that have left the window; callers hold the lock
+func (b *requestBudget) prune(now time.Time) {
+	cutoff := now.Add(-b.window)
+	i := 0
+	for i < len(b.requests) && b.requests[i].Before(cutoff) {
+		i++
+	}
+	b.requests = b.requests[i:]
+}
+
+// observe updates the budget from a response's rate-limit headers and
+// pauses it when the service asks us to back off
+func (b *requestBudget) observe(resp *http.Response) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if limit, err := strconv.Atoi(resp.Header.Get("X-RateLimit-Limit")); err == nil {
+		b.serverLimit = limit
+	}
+	if remaining, err := strconv.Atoi(resp.Header.Get("X-RateLimit-Remaining")); err == nil {
+		b.serverRemaining = remaining
+	}
+	if resp.StatusCode == http.StatusTooManyRequests {
+		pause := 2 * time.Second
+		if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds > 0 {
+			pause = time.Duration(seconds) * time.Second
+		}
+		b.pausedUntil = time.Now().Add(pause)
+	}
+}
+
+// status snapshots the budget
+func (b *requestBudget) status() QuotaStatus {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.prune(time.Now())
+
+	return QuotaStatus{
+		Name:            b.name,
+		Limit:           b.limit,
+		Used:            len(b.requests),
+		Remaining:       b.limit - len(b.requests),
+		WindowSeconds:   int(b.window.Seconds()),
+		ServerLimit:     b.serverLimit,
+		ServerRemaining: b.serverRemaining,
+		PausedUntil:     b.pausedUntil,
+		PacedRequests:   b.paced,
+	}
+}
+
+// budgetTransport charges every outgoing request against a budget
+type budgetTransport struct {
+	budget *requestBudget
+	next   http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *budgetTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.budget.acquire()
+	resp, err := t.next.RoundTrip(req)
+	if err == nil {
+		t.budget.observe(resp)
+	}
+	return resp, err
+}
+
+// GetQuotaStatus reports the remaining TMDB and Letterboxd request budgets
+func (a *App) GetQuotaStatus() []QuotaStatus {
+	return []QuotaStatus{a.tmdbBudget.status(), a.letterboxdBudget.status()}
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestRequestBudgetLow(t *testing.T) {
+	tests := []struct {
+		name            string
+		used            int
+		serverLimit     int
+		serverRemaining int
+		want            bool
+	}{
+		{name: "fresh", used: 0, serverLimit: -1, serverRemaining: -1},
+		{name: "half spent", used: 5, serverLimit: -1, serverRemaining: -1},
+		{name: "nearly spent", used: 8, serverLimit: -1, serverRemaining: -1, want: true},
+		{name: "server plenty", used: 0, serverLimit: 40, serverRemaining: 30},
+		{name: "server nearly spent", used: 0, serverLimit: 40, serverRemaining: 8, want: true},
+		{name: "server remaining without limit", used: 0, serverLimit: -1, serverRemaining: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := newRequestBudget("test", 10, time.Minute)
+			for i := 0; i < tt.used; i++ {
+				b.requests = append(b.requests, time.Now())
+			}
+			b.serverLimit, b.serverRemaining = tt.serverLimit, tt.serverRemaining
+			if got := b.low(); got != tt.want {
+				t.Errorf("low() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRequestBudgetWindow(t *testing.T) {
+	b := newRequestBudget("test", 10, time.Minute)
+	now := time.Now()
+	b.requests = []time.Time{now.Add(-2 * time.Minute), now.Add(-90 * time.Second), now.Add(-time.Second)}
+
+	status := b.status()
+	if status.Used != 1 || status.Remaining != 9 {
+		t.Errorf("status() used %d, remaining %d; want 1 and 9", status.Used, status.Remaining)
+	}
+
+	b.acquire()
+	if status := b.status(); status.Used != 2 || status.PacedRequests != 0 {
+		t.Errorf("acquire() with budget left: used %d, paced %d; want 2 and 0", status.Used, status.PacedRequests)
+	}
+}
+
+func TestRequestBudgetObserve(t *testing.T) {
+	tests := []struct {
+		name          string
+		status        int
+		header        http.Header
+		wantLimit     int
+		wantRemaining int
+		wantPause     time.Duration
+	}{
+		{name: "no headers", status: http.StatusOK, header: http.Header{}, wantLimit: -1, wantRemaining: -1},
+		{name: "rate-limit headers", status: http.StatusOK, header: http.Header{"X-Ratelimit-Limit": {"40"}, "X-Ratelimit-Remaining": {"12"}}, wantLimit: 40, wantRemaining: 12},
+		{name: "too many requests", status: http.StatusTooManyRequests, header: http.Header{}, wantLimit: -1, wantRemaining: -1, wantPause: 2 * time.Second},
+		{name: "retry after", status: http.StatusTooManyRequests, header: http.Header{"Retry-After": {"30"}}, wantLimit: -1, wantRemaining: -1, wantPause: 30 * time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := newRequestBudget("test", 10, time.Minute)
+			before := time.Now()
+			b.observe(&http.Response{StatusCode: tt.status, Header: tt.header})
+
+			status := b.status()
+			if status.ServerLimit != tt.wantLimit || status.ServerRemaining != tt.wantRemaining {
+				t.Errorf("server budget %d/%d, want %d/%d", status.ServerRemaining, status.ServerLimit, tt.wantRemaining, tt.wantLimit)
+			}
+			if tt.wantPause == 0 {
+				if !status.PausedUntil.IsZero() {
+					t.Errorf("paused until %v, want no pause", status.PausedUntil)
+				}
+				return
+			}
+			if pause := status.PausedUntil.Sub(before); pause < tt.wantPause || pause > tt.wantPause+time.Second {
+				t.Errorf("paused for %v, want %v", pause, tt.wantPause)
+			}
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// releaseStatus describes why a film can't be watched yet, from its TMDB
+// status and release date: "In production", "Coming 2025-09-12" and the
+// like; released films and films with unknown status get an empty status
+func releaseStatus(status string, releaseDate string, now time.Time) string {
+	if date, err := time.Parse("2006-01-02", releaseDate); err == nil && date.After(now) {
+		return "Coming " + releaseDate
+	}
+
+	switch status {
+	case "", "Released":
+		return ""
+	case "Canceled":
+		return "Canceled"
+	default:
+		// "Rumored", "Planned", "In Production" and "Post Production"
+		return strings.ToUpper(status[:1]) + strings.ToLower(status[1:])
+	}
+}
+
+// filterUnreleased drops hydrated movies that can't be watched yet
+func filterUnreleased(movies []Movie) []Movie {
+	filtered := movies[:0]
+	for _, movie := range movies {
+		if movie.Status == "" {
+			filtered = append(filtered, movie)
+		}
+	}
+	return filtered
+}
+
+// sortNewlyAvailable moves films already released digitally to the front,
+// most recent first; the rest keep their order
+func sortNewlyAvailable(movies []Movie, now time.Time) {
+	today := now.Format("2006-01-02")
+	available := func(movie Movie) bool {
+		return movie.DigitalReleaseDate != "" && movie.DigitalReleaseDate <= today
+	}
+	sort.SliceStable(movies, func(i, j int) bool {
+		a, b := available(movies[i]), available(movies[j])
+		if a != b {
+			return a
+		}
+		return a && movies[i].DigitalReleaseDate > movies[j].DigitalReleaseDate
+	})
+}
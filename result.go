@@ -0,0 +1,100 @@
+package main
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ComparisonSchemaVersion is the version of the ComparisonResultV2 envelope;
+// it changes whenever fields are removed or change meaning
+const ComparisonSchemaVersion = 2
+
+// ComparisonResultV2 is the versioned response envelope of a comparison, so
+// the frontend and API consumers can evolve independently of Movie.
+// FindCommonMovies remains as a shim returning only the movies.
+type ComparisonResultV2 struct {
+	SchemaVersion int `json:"schema_version"`
+	// ID identifies the comparison in the history, for MergeResults
+	ID          int             `json:"id"`
+	GeneratedAt time.Time       `json:"generated_at"`
+	Usernames   []string        `json:"usernames"`
+	Movies      []Movie         `json:"movies"`
+	Stats       ComparisonStats `json:"stats"`
+	Warnings    []Warning       `json:"warnings"`
+}
+
+// ComparisonStats summarizes a comparison run
+type ComparisonStats struct {
+	Participants   int            `json:"participants"`
+	WatchlistSizes map[string]int `json:"watchlist_sizes"`
+	CommonMovies   int            `json:"common_movies"`
+	Excluded       int            `json:"excluded"`
+	DurationMS     int64          `json:"duration_ms"`
+}
+
+// Warning codes for soft failures that don't stop a comparison
+const (
+	WarningCheckpointResumed  = "checkpoint_resumed"
+	WarningWatchlistRetried   = "watchlist_retried"
+	WarningStaleCache         = "stale_cache"
+	WarningUnmatchedTitles    = "unmatched_titles"
+	WarningWatchedUnavailable = "watched_unavailable"
+	WarningOfflineFilters     = "offline_filters"
+	WarningSettingsHydrate    = "settings_hydrate"
+)
+
+// Warning is a non-fatal problem encountered during a comparison, so the UI
+// can show what was imperfect without failing the run
+type Warning struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	// Subject is the user or film the warning is about, if any
+	Subject string `json:"subject,omitempty"`
+	// Count is how many items were affected, for aggregated warnings
+	Count int `json:"count,omitempty"`
+}
+
+// warnings collects warnings from concurrent comparison stages; a nil
+// collector discards them
+type warnings struct {
+	mu   sync.Mutex
+	list []Warning
+}
+
+// add records a warning
+func (w *warnings) add(warning Warning) {
+	if w == nil {
+		return
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.list = append(w.list, warning)
+}
+
+// addf records a warning with a formatted message
+func (w *warnings) addf(code string, subject string, count int, format string, args ...interface{}) {
+	w.add(Warning{Code: code, Subject: subject, Count: count, Message: fmt.Sprintf(format, args...)})
+}
+
+// all returns the collected warnings
+func (w *warnings) all() []Warning {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return append([]Warning{}, w.list...)
+}
+
+// newComparisonResult creates an empty result envelope for a group
+func newComparisonResult(usernames []string) ComparisonResultV2 {
+	return ComparisonResultV2{
+		SchemaVersion: ComparisonSchemaVersion,
+		GeneratedAt:   time.Now(),
+		Usernames:     usernames,
+		Movies:        []Movie{},
+		Stats: ComparisonStats{
+			Participants:   len(usernames),
+			WatchlistSizes: make(map[string]int),
+		},
+		Warnings: []Warning{},
+	}
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gocolly/colly/v2"
+)
+
+// unmatched reports whether a movie only has placeholder metadata
+func unmatched(movie Movie) bool {
+	return movie.TMDBID == 0
+}
+
+// RetryUnmatched re-attempts the metadata lookup for movies in a comparison
+// result that only got placeholder data, so one flaky call doesn't leave
+// permanent "No Poster" entries. Movies the title search still can't match
+// are resolved through the TMDB ID on their Letterboxd film page
+func (a *App) RetryUnmatched(result ComparisonResultV2) ComparisonResultV2 {
+	remaining := 0
+	for i := range result.Movies {
+		movie := &result.Movies[i]
+		if !unmatched(*movie) {
+			continue
+		}
+		if err := a.retryMovie(movie); err != nil {
+			log.Printf("Could not match '%s' on retry: %v", movie.Title, err)
+			remaining++
+		}
+	}
+
+	warnings := make([]Warning, 0, len(result.Warnings))
+	for _, warning := range result.Warnings {
+		if warning.Code != WarningUnmatchedTitles {
+			warnings = append(warnings, warning)
+		}
+	}
+	if remaining > 0 {
+		warnings = append(warnings, Warning{
+			Code:    WarningUnmatchedTitles,
+			Count:   remaining,
+			Message: fmt.Sprintf("%d titles could not be matched on TMDB", remaining),
+		})
+	}
+	result.Warnings = warnings
+
+	return result
+}
+
+// retryMovie looks a placeholder movie up again, first through the metadata
+// providers and then by the TMDB ID Letterboxd links the film to
+func (a *App) retryMovie(movie *Movie) error {
+	if err := a.enrichMovie(movie); err == nil {
+		return nil
+	}
+
+	tmdbID, err := a.letterboxdTMDBID(movie.URL)
+	if err != nil {
+		return err
+	}
+	if err := a.enrichByTMDBID(movie, tmdbID); err != nil {
+		return err
+	}
+	// Remember the film page's match so later comparisons skip the search
+	if err := a.setAlias(movie.Key, tmdbID, AliasLetterboxd); err != nil {
+		log.Printf("Could not save alias for '%s': %v", movie.Title, err)
+	}
+	return nil
+}
+
+// letterboxdTMDBID reads the TMDB movie ID a Letterboxd film page links to
+func (a *App) letterboxdTMDBID(filmURL string) (int, error) {
+	defer a.metrics.since("film_page_scrape", time.Now())
+	c := a.newCollector()
+
+	var tmdbID, tmdbType string
+	var scrapeErr error
+
+	c.OnHTML(a.ruleset().Selectors.FilmTMDB, func(e *colly.HTMLElement) {
+		tmdbID = e.Attr("data-tmdb-id")
+		tmdbType = e.Attr("data-tmdb-type")
+	})
+
+	c.OnError(func(r *colly.Response, e error) {
+		a.metrics.countError("scrape")
+		scrapeErr = e
+	})
+
+	filmURL = strings.TrimSuffix(filmURL, "/") + "/"
+	if err := c.Visit(filmURL); err != nil {
+		return 0, fmt.Errorf("could not visit film page '%s': %v", filmURL, err)
+	}
+	if scrapeErr != nil {
+		return 0, scrapeErr
+	}
+
+	if tmdbType != "" && tmdbType != "movie" {
+		return 0, fmt.Errorf("film page '%s' links to a TMDB %s entry", filmURL, tmdbType)
+	}
+	id, err := strconv.Atoi(tmdbID)
+	if err != nil || id <= 0 {
+		return 0, fmt.Errorf("film page '%s' has no TMDB ID", filmURL)
+	}
+	return id, nil
+}
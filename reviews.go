@@ -0,0 +1,78 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gocolly/colly/v2"
+)
+
+const (
+	// reviewsPerMovie is how many review snippets are attached to a movie
+	reviewsPerMovie = 3
+	// reviewSnippetLength caps the characters kept from each review
+	reviewSnippetLength = 280
+)
+
+// Review is a short, spoiler-free Letterboxd review snippet
+type Review struct {
+	Author string  `json:"author"`
+	Rating float64 `json:"rating"`
+	Text   string  `json:"text"`
+	URL    string  `json:"url"`
+}
+
+// GetReviews scrapes the most popular spoiler-free reviews of a Letterboxd film
+func (a *App) GetReviews(filmURL string) ([]Review, error) {
+	defer a.metrics.since("reviews_scrape", time.Now())
+	c := a.newCollector()
+
+	var reviews []Review
+	var scrapeErr error
+
+	rules := a.ruleset()
+	selectors := rules.Selectors
+	c.OnHTML(selectors.Review, func(e *colly.HTMLElement) {
+		if len(reviews) >= reviewsPerMovie {
+			return
+		}
+		// Never show text Letterboxd flags as containing spoilers
+		if e.DOM.Find(selectors.ReviewSpoilers).Length() > 0 {
+			return
+		}
+
+		text := strings.Join(strings.Fields(e.ChildText(selectors.ReviewBody)), " ")
+		if text == "" {
+			return
+		}
+		if runes := []rune(text); len(runes) > reviewSnippetLength {
+			text = strings.TrimSpace(string(runes[:reviewSnippetLength])) + "…"
+		}
+
+		review := Review{
+			Author: e.ChildText(selectors.ReviewAuthor),
+			Rating: parseRatingClass(e.ChildAttr(selectors.Rating, "class")),
+			Text:   text,
+		}
+		if link := e.ChildAttr(selectors.ReviewLink, "href"); link != "" {
+			review.URL = rules.URLs.link(link)
+		}
+		reviews = append(reviews, review)
+	})
+
+	c.OnError(func(r *colly.Response, e error) {
+		a.metrics.countError("scrape")
+		scrapeErr = e
+	})
+
+	reviewsURL := strings.TrimSuffix(filmURL, "/") + "/reviews/by/activity/"
+	if err := c.Visit(reviewsURL); err != nil {
+		return nil, fmt.Errorf("could not visit reviews for '%s': %v", filmURL, err)
+	}
+	if scrapeErr != nil {
+		return nil, scrapeErr
+	}
+
+	return reviews, nil
+}
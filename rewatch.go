@@ -0,0 +1,68 @@
+package main
+
+import (
+	"log"
+	"sort"
+	"sync"
+)
+
+// lovedRating is the star rating from which a watched film counts as loved
+const lovedRating = 4.0
+
+// scrapeWatchedLists scrapes every participant's watched films concurrently,
+// keyed by user and then by movie key; users whose films can't be scraped
+// are left out
+func (a *App) scrapeWatchedLists(usernames []string) map[string]map[string]WatchedFilm {
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	watched := make(map[string]map[string]WatchedFilm)
+
+	for _, username := range usernames {
+		if _, imported := a.importedList(username); imported {
+			continue
+		}
+		wg.Add(1)
+		go func(user string) {
+			defer wg.Done()
+			films, err := a.GetWatchedFilms(user)
+			if err != nil {
+				log.Printf("Could not fetch watched films for '%s': %v", user, err)
+				return
+			}
+			byKey := make(map[string]WatchedFilm, len(films))
+			for _, film := range films {
+				byKey[movieKey(film.URL)] = film
+			}
+			mu.Lock()
+			watched[user] = byKey
+			mu.Unlock()
+		}(username)
+	}
+	wg.Wait()
+
+	return watched
+}
+
+// rewatchInfo returns the participants who have already seen a film and the
+// subset who loved it (rated 4+ stars); users who still have it on their
+// watchlist are listed as seen but never as loved, so they aren't counted twice
+func rewatchInfo(key string, listers []string, watched map[string]map[string]WatchedFilm) (seenBy []string, lovedBy []string) {
+	listed := make(map[string]bool, len(listers))
+	for _, user := range listers {
+		listed[user] = true
+	}
+
+	for user, films := range watched {
+		film, ok := films[key]
+		if !ok {
+			continue
+		}
+		seenBy = append(seenBy, user)
+		if film.Rating >= lovedRating && !listed[user] {
+			lovedBy = append(lovedBy, user)
+		}
+	}
+	sort.Strings(seenBy)
+	sort.Strings(lovedBy)
+	return seenBy, lovedBy
+}
@@ -0,0 +1,243 @@
+package main
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// rulesetFile, kept in the local data directory, overrides the built-in
+// ruleset on this machine
+const rulesetFile = "ruleset.json"
+
+// builtinRuleset is the ruleset shipped with this release
+//
+//go:embed ruleset.json
+var builtinRuleset []byte
+
+// Ruleset holds the selectors and URL templates scraping Letterboxd relies
+// on, so a layout change can be fixed by shipping a new ruleset rather than
+// a new release. The built-in ruleset is overridden by the local ruleset
+// file and then by the remote ruleset, if configured; each only needs the
+// fields it changes.
+type Ruleset struct {
+	Version   int          `json:"version"`
+	Selectors Selectors    `json:"selectors"`
+	URLs      URLTemplates `json:"urls"`
+	// Sources lists where the ruleset was loaded from, e.g. "built-in"
+	Sources []string `json:"sources"`
+}
+
+// URLTemplates are the Letterboxd URLs scraped; "{username}" stands for
+// the user's name and "{page}" for a page number
+type URLTemplates struct {
+	// Base is prefixed to the site-relative links found on pages
+	Base      string `json:"base"`
+	Profile   string `json:"profile"`
+	Watchlist string `json:"watchlist"`
+	// WatchlistFragment is the AJAX endpoint serving a page of a
+	// watchlist's poster grid without the rest of the page
+	WatchlistFragment string `json:"watchlist_fragment"`
+	Watched           string `json:"watched"`
+	PopularThisWeek   string `json:"popular_this_week"`
+	// FilmStats is the fragment with a film's watch count; "{film}" stands
+	// for the film's slug
+	FilmStats string `json:"film_stats"`
+	// LayoutCheck is a long-standing public list the layout check scrapes,
+	// showing posters, pagination and Open Graph tags like a watchlist does
+	LayoutCheck string `json:"layout_check"`
+}
+
+// forUser fills in a URL template for a user
+func forUser(template string, username string) string {
+	return strings.ReplaceAll(template, "{username}", url.PathEscape(username))
+}
+
+// forFilm fills in a URL template for a film
+func forFilm(template string, filmURL string) string {
+	return strings.ReplaceAll(template, "{film}", url.PathEscape(movieKey(filmURL)))
+}
+
+// forPage fills in a URL template for a page of a user's list
+func forPage(template string, username string, page int) string {
+	return strings.ReplaceAll(forUser(template, username), "{page}", strconv.Itoa(page))
+}
+
+// link resolves a site-relative link found on a page, e.g. "/film/alien/"
+func (u URLTemplates) link(path string) string {
+	return strings.TrimSuffix(u.Base, "/") + path
+}
+
+// validate checks that a ruleset has every selector and URL
+func (r Ruleset) validate() error {
+	for _, field := range []struct{ name, value string }{
+		{"selectors.poster", r.Selectors.Poster},
+		{"selectors.film_poster", r.Selectors.FilmPoster},
+		{"selectors.link_attr", r.Selectors.LinkAttr},
+		{"selectors.poster_image", r.Selectors.PosterImage},
+		{"selectors.next_page", r.Selectors.NextPage},
+		{"selectors.og_image", r.Selectors.OGImage},
+		{"selectors.rating", r.Selectors.Rating},
+		{"selectors.profile_avatar", r.Selectors.ProfileAvatar},
+		{"selectors.profile_name", r.Selectors.ProfileName},
+		{"selectors.profile_location", r.Selectors.ProfileLocation},
+		{"selectors.watchlist_count", r.Selectors.WatchlistCount},
+		{"selectors.review", r.Selectors.Review},
+		{"selectors.review_spoilers", r.Selectors.ReviewSpoilers},
+		{"selectors.review_body", r.Selectors.ReviewBody},
+		{"selectors.review_author", r.Selectors.ReviewAuthor},
+		{"selectors.review_link", r.Selectors.ReviewLink},
+		{"selectors.friend_row", r.Selectors.FriendRow},
+		{"selectors.friend_name", r.Selectors.FriendName},
+		{"selectors.film_rating", r.Selectors.FilmRating},
+		{"selectors.film_watches", r.Selectors.FilmWatches},
+		{"selectors.film_tmdb", r.Selectors.FilmTMDB},
+		{"urls.base", r.URLs.Base},
+		{"urls.profile", r.URLs.Profile},
+		{"urls.watchlist", r.URLs.Watchlist},
+		{"urls.watchlist_fragment", r.URLs.WatchlistFragment},
+		{"urls.watched", r.URLs.Watched},
+		{"urls.popular_this_week", r.URLs.PopularThisWeek},
+		{"urls.film_stats", r.URLs.FilmStats},
+		{"urls.layout_check", r.URLs.LayoutCheck},
+	} {
+		if strings.TrimSpace(field.value) == "" {
+			return fmt.Errorf("ruleset has no %s", field.name)
+		}
+	}
+	return nil
+}
+
+// overlay applies a partial ruleset in JSON on top of a ruleset, returning
+// the ruleset unchanged if the result isn't complete
+func (r Ruleset) overlay(data []byte, source string) (Ruleset, error) {
+	out := r
+	out.Sources = append([]string{}, r.Sources...)
+	if err := json.Unmarshal(data, &out); err != nil {
+		return r, fmt.Errorf("failed to parse %s ruleset: %v", source, err)
+	}
+	if err := out.validate(); err != nil {
+		return r, fmt.Errorf("invalid %s ruleset: %v", source, err)
+	}
+	out.Sources = append(out.Sources, source)
+	return out, nil
+}
+
+// ruleset returns the ruleset in use, loading the built-in and local ones
+// the first time
+func (a *App) ruleset() Ruleset {
+	a.layoutMu.Lock()
+	rules := a.rules
+	a.layoutMu.Unlock()
+	if len(rules.Sources) > 0 {
+		return rules
+	}
+
+	rules, _ = a.loadRuleset(false)
+	a.layoutMu.Lock()
+	defer a.layoutMu.Unlock()
+	if len(a.rules.Sources) == 0 {
+		a.rules = rules
+	}
+	return a.rules
+}
+
+// GetRuleset returns the scraping ruleset in use and where it came from
+func (a *App) GetRuleset() Ruleset {
+	return a.ruleset()
+}
+
+// SetRulesetURL sets the URL of a remote ruleset overriding the built-in
+// and local ones and loads it; an empty URL stops using one
+func (a *App) SetRulesetURL(rulesetURL string) error {
+	rulesetURL = strings.TrimSpace(rulesetURL)
+	if rulesetURL != "" {
+		parsed, err := url.Parse(rulesetURL)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return fmt.Errorf("invalid ruleset URL '%s'", rulesetURL)
+		}
+	}
+	settings := a.loadSettings()
+	settings.RulesetURL = rulesetURL
+	if err := a.saveSettings(settings); err != nil {
+		return err
+	}
+	return a.ReloadRuleset()
+}
+
+// ReloadRuleset reloads the local ruleset file and the remote ruleset, e.g.
+// after the file was edited; the layers that load are used even if another
+// fails
+func (a *App) ReloadRuleset() error {
+	rules, err := a.loadRuleset(true)
+	a.layoutMu.Lock()
+	a.rules = rules
+	a.layout = LayoutHealth{}
+	a.layoutMu.Unlock()
+	return err
+}
+
+// loadRuleset layers the local ruleset file and, if remote is set, the
+// configured remote ruleset over the built-in one, skipping layers that
+// fail to load
+func (a *App) loadRuleset(remote bool) (Ruleset, error) {
+	var rules Ruleset
+	rules, err := rules.overlay(builtinRuleset, "built-in")
+	if err != nil {
+		// The built-in ruleset is part of the release
+		panic(err)
+	}
+
+	var errs []string
+	path := filepath.Join(GetDataDir(), rulesetFile)
+	if data, err := os.ReadFile(path); err == nil {
+		if rules, err = rules.overlay(data, path); err != nil {
+			errs = append(errs, err.Error())
+		}
+	} else if !os.IsNotExist(err) {
+		errs = append(errs, fmt.Sprintf("could not read %s: %v", path, err))
+	}
+
+	if remote {
+		if rulesetURL := a.loadSettings().RulesetURL; rulesetURL != "" {
+			if data, err := fetchRuleset(rulesetURL); err != nil {
+				errs = append(errs, err.Error())
+			} else if rules, err = rules.overlay(data, rulesetURL); err != nil {
+				errs = append(errs, err.Error())
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		log.Printf("Using ruleset from %s: %s", strings.Join(rules.Sources, ", "), strings.Join(errs, "; "))
+		return rules, fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+	return rules, nil
+}
+
+// fetchRuleset downloads a remote ruleset
+func fetchRuleset(rulesetURL string) ([]byte, error) {
+	client := &http.Client{Transport: sharedTransport, Timeout: 10 * time.Second}
+	resp, err := client.Get(rulesetURL)
+	if err != nil {
+		return nil, fmt.Errorf("could not load remote ruleset: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("could not load remote ruleset: status code %d", resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, fmt.Errorf("could not load remote ruleset: %v", err)
+	}
+	return data, nil
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// defaultLanguage is the interface language used when none is configured
+const defaultLanguage = "en"
+
+// runtimeUnits are the hour and minute formats of a language; each takes
+// the number as its only argument
+type runtimeUnits struct {
+	hours, minutes, separator string
+}
+
+// runtimeFormats localizes runtimes such as "2h 14m", by ISO 639-1 language
+var runtimeFormats = map[string]runtimeUnits{
+	"en": {"%dh", "%dm", " "},
+	"de": {"%d Std.", "%d Min.", " "},
+	"fr": {"%d h", "%d min", " "},
+	"es": {"%d h", "%d min", " "},
+	"it": {"%d h", "%d min", " "},
+	"pt": {"%d h", "%d min", " "},
+	"nl": {"%d u", "%d min", " "},
+	"sv": {"%d tim", "%d min", " "},
+	"pl": {"%d godz.", "%d min", " "},
+	"ja": {"%d時間", "%d分", ""},
+	"zh": {"%d小时", "%d分钟", ""},
+	"ko": {"%d시간", "%d분", " "},
+}
+
+// language returns the configured interface language
+func (a *App) language() string {
+	if language := a.loadSettings().Language; language != "" {
+		return language
+	}
+	return defaultLanguage
+}
+
+// formatRuntime renders a runtime in minutes as hours and minutes, e.g.
+// "2h 14m" or "45m" in English; unknown languages fall back to English
+func formatRuntime(minutes int, language string) string {
+	if minutes <= 0 {
+		return ""
+	}
+
+	base, _, _ := strings.Cut(strings.ToLower(language), "-")
+	units, ok := runtimeFormats[base]
+	if !ok {
+		units = runtimeFormats[defaultLanguage]
+	}
+
+	hours, rest := minutes/60, minutes%60
+	switch {
+	case hours == 0:
+		return fmt.Sprintf(units.minutes, rest)
+	case rest == 0:
+		return fmt.Sprintf(units.hours, hours)
+	default:
+		return fmt.Sprintf(units.hours, hours) + units.separator + fmt.Sprintf(units.minutes, rest)
+	}
+}
+
+// SetLanguage sets the language formatted values are localized in
+func (a *App) SetLanguage(language string) error {
+	settings := a.loadSettings()
+	settings.Language = strings.ToLower(strings.TrimSpace(language))
+	return a.saveSettings(settings)
+}
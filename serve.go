@@ -0,0 +1,127 @@
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+)
+
+// serve runs Klisse headless, exposing the comparison API and Prometheus
+// metrics over HTTP for self-hosted deployments
+func serve(app *App, addr string) error {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/api/compare", func(w http.ResponseWriter, r *http.Request) {
+		usernames := strings.FieldsFunc(r.URL.Query().Get("users"), func(c rune) bool {
+			return c == ',' || c == ' '
+		})
+		var movies interface{}
+		var err error
+		if r.URL.Query().Get("lite") != "" {
+			movies, err = app.FindCommonMoviesLite(usernames, CompareOptions{Preset: r.URL.Query().Get("preset")})
+		} else {
+			movies, err = app.FindCommonMovies(usernames, r.URL.Query().Get("preset"))
+		}
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusOK, movies)
+	})
+
+	mux.HandleFunc("/api/v2/compare", func(w http.ResponseWriter, r *http.Request) {
+		usernames := strings.FieldsFunc(r.URL.Query().Get("users"), func(c rune) bool {
+			return c == ',' || c == ' '
+		})
+		result, err := app.CompareV2(usernames, CompareOptions{Preset: r.URL.Query().Get("preset")})
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusOK, result)
+	})
+
+	mux.HandleFunc("/api/share", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "use POST to share a shortlist"})
+			return
+		}
+		var movies []SharedMovie
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxShareBody)).Decode(&movies); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid shortlist"})
+			return
+		}
+		session, err := app.createShare(movies)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusOK, session)
+	})
+	handleShareVotes(mux, app.share, app.vote)
+
+	mux.HandleFunc("/api/details", func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		movie, err := app.GetMovieDetails(query.Get("title"), query.Get("url"))
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusOK, movie)
+	})
+
+	mux.HandleFunc("/api/status", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, app.GetStatus())
+	})
+
+	mux.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		app.metrics.writePrometheus(w, []CacheStatus{app.scrapeCache.stats("letterboxd_pages"), app.tmdbCache.stats("tmdb_responses")})
+	})
+
+	log.Printf("Serving Klisse on %s (metrics at /metrics)", addr)
+	return http.ListenAndServe(addr, mux)
+}
+
+// writeJSON encodes v as the JSON response body
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("Could not write response: %v", err)
+	}
+}
+
+// handleShareVotes serves shared sessions and records votes on them:
+// GET /api/share/{code} returns a session, POST /api/share/{code}/vote votes.
+// get and vote find the session, which the LAN voting server may proxy to
+// a relay
+func handleShareVotes(mux *http.ServeMux, get func(code string) (VoteSession, error), vote func(code, voter, key string, value int) (VoteSession, error)) {
+	mux.HandleFunc("/api/share/", func(w http.ResponseWriter, r *http.Request) {
+		code, action, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/api/share/"), "/")
+		code = strings.ToUpper(code)
+
+		var session VoteSession
+		var err error
+		switch {
+		case action == "" && r.Method == http.MethodGet:
+			session, err = get(code)
+		case action == "vote" && r.Method == http.MethodPost:
+			var req voteRequest
+			if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 4<<10)).Decode(&req); err != nil {
+				writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid vote"})
+				return
+			}
+			session, err = vote(code, req.Voter, req.Key, req.Value)
+		default:
+			writeJSON(w, http.StatusNotFound, map[string]string{"error": "not found"})
+			return
+		}
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusOK, session)
+	})
+}
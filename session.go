@@ -0,0 +1,425 @@
+package main
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+)
+
+// maxSessions caps the comparison sessions kept in memory; the least
+// recently used are dropped first
+const maxSessions = 8
+
+// ComparisonSession is a comparison the frontend works on by ID: its
+// participants and options, its results, fetched in pages with
+// GetResultsPage, and the votes and shortlist gathered while picking.
+// Its votes are kept on a local share under ShareCode, so friends voting
+// on that code from their phones vote on the session itself
+type ComparisonSession struct {
+	ID           string          `json:"id"`
+	CreatedAt    time.Time       `json:"created_at"`
+	Participants []string        `json:"participants"`
+	Options      CompareOptions  `json:"options"`
+	GeneratedAt  time.Time       `json:"generated_at"`
+	Total        int             `json:"total"`
+	Stats        ComparisonStats `json:"stats"`
+	Warnings     []Warning       `json:"warnings"`
+	// ShareCode is the code the session's results are shared under, e.g.
+	// for StartLANVoting
+	ShareCode string `json:"share_code"`
+	// Votes maps movie key to voter to VoteUp or VoteVeto
+	Votes map[string]map[string]int `json:"votes"`
+	// Shortlist holds the keys of the movies shortlisted so far, in the
+	// order they were added
+	Shortlist []string `json:"shortlist"`
+	// CanUndo and CanRedo describe the edits Undo and Redo would revert or
+	// reapply, e.g. "pin"; empty when there are none
+	CanUndo string `json:"can_undo"`
+	CanRedo string `json:"can_redo"`
+
+	// movies are the results; they are replaced, never modified in place,
+	// so pages handed out stay valid
+	movies    []Movie
+	lastUsed  time.Time
+	undoStack []sessionEdit
+	redoStack []sessionEdit
+}
+
+// StartSession runs a comparison and keeps it as a session, returning it
+// without its movies, which are fetched with GetResultsPage
+func (a *App) StartSession(usernames []string, opts CompareOptions) (ComparisonSession, error) {
+	result, err := a.CompareV2(usernames, opts)
+	if err != nil {
+		return ComparisonSession{}, err
+	}
+	id, err := newToken()
+	if err != nil {
+		return ComparisonSession{}, err
+	}
+	share, err := a.openShare(sharedMovies(result.Movies), time.Time{})
+	if err != nil {
+		return ComparisonSession{}, err
+	}
+	session := &ComparisonSession{
+		ID:           id,
+		CreatedAt:    time.Now(),
+		Participants: usernames,
+		Options:      opts,
+		ShareCode:    share.Code,
+	}
+	session.setResult(result)
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.sessions == nil {
+		a.sessions = make(map[string]*ComparisonSession)
+	}
+	a.sessions[id] = session
+	for len(a.sessions) > maxSessions {
+		oldest := ""
+		for key, s := range a.sessions {
+			if oldest == "" || s.lastUsed.Before(a.sessions[oldest].lastUsed) {
+				oldest = key
+			}
+		}
+		a.closeShare(a.sessions[oldest].ShareCode)
+		delete(a.sessions, oldest)
+	}
+	return a.sessionView(session), nil
+}
+
+// GetSession returns a session without its movies
+func (a *App) GetSession(sessionID string) (ComparisonSession, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	session, err := a.sessionLocked(sessionID)
+	if err != nil {
+		return ComparisonSession{}, err
+	}
+	return a.sessionView(session), nil
+}
+
+// RefreshSession reruns a session's comparison with the same participants
+// and options, keeping its votes and shortlist
+func (a *App) RefreshSession(sessionID string) (ComparisonSession, error) {
+	session, err := a.GetSession(sessionID)
+	if err != nil {
+		return session, err
+	}
+	result, err := a.CompareV2(session.Participants, session.Options)
+	if err != nil {
+		return session, err
+	}
+	return a.updateSession(sessionID, func(s *ComparisonSession) error {
+		s.setResult(result)
+		a.setShareMovies(s.ShareCode, sharedMovies(s.movies))
+		return nil
+	})
+}
+
+// CloseSession frees a session
+func (a *App) CloseSession(sessionID string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if session, ok := a.sessions[sessionID]; ok {
+		a.closeShare(session.ShareCode)
+		delete(a.sessions, sessionID)
+	}
+}
+
+// HydrateSessionMovie fetches the details of one of a session's results,
+// as GetMovieDetails does, and keeps them in the session so its pages can
+// be filtered and sorted by rating, runtime or year. The comparison's own
+// fields, such as the users and score, are kept
+func (a *App) HydrateSessionMovie(sessionID string, key string) (Movie, error) {
+	movie, err := a.sessionMovie(sessionID, key)
+	if err != nil {
+		return Movie{}, err
+	}
+	details, err := a.GetMovieDetails(movie.Title, movie.URL)
+	if err != nil {
+		return movie, err
+	}
+	details.Key = movie.Key
+	details.Users, details.Count, details.Score = movie.Users, movie.Count, movie.Score
+	details.SeenBy, details.LovedBy, details.Editions = movie.SeenBy, movie.LovedBy, movie.Editions
+	details.Note, details.Tags, details.Pinned = movie.Note, movie.Tags, movie.Pinned
+	details.ListRank, details.AffinityScore, details.MoodScore = movie.ListRank, movie.AffinityScore, movie.MoodScore
+
+	_, err = a.updateSession(sessionID, func(s *ComparisonSession) error {
+		movies := slices.Clone(s.movies)
+		for i := range movies {
+			if movies[i].Key == key {
+				movies[i] = details
+			}
+		}
+		sortResults(movies, s.Options, a.compositeConfigured())
+		s.movies = movies
+		a.setShareMovies(s.ShareCode, sharedMovies(movies))
+		return nil
+	})
+	return details, err
+}
+
+// SessionVote records a participant's upvote (VoteUp) or veto (VoteVeto)
+// on one of the session's movies; voting 0 withdraws the vote. It can be
+// undone with Undo
+func (a *App) SessionVote(sessionID string, participant string, key string, value int) (ComparisonSession, error) {
+	before, err := a.GetSession(sessionID)
+	if err != nil {
+		return before, err
+	}
+	previous := before.Votes[key][participant]
+
+	session, err := a.applyVote(sessionID, participant, key, value)
+	if err != nil || previous == value {
+		return session, err
+	}
+	return a.recordEdit(sessionID, sessionEdit{
+		label: "vote",
+		undo:  func() error { _, err := a.applyVote(sessionID, participant, key, previous); return err },
+		redo:  func() error { _, err := a.applyVote(sessionID, participant, key, value); return err },
+	})
+}
+
+// SetShortlisted adds one of the session's movies to its shortlist or
+// removes it. It can be undone with Undo
+func (a *App) SetShortlisted(sessionID string, key string, shortlisted bool) (ComparisonSession, error) {
+	before, err := a.GetSession(sessionID)
+	if err != nil {
+		return before, err
+	}
+	session, err := a.applyShortlisted(sessionID, key, shortlisted)
+	if err != nil || slices.Contains(before.Shortlist, key) == shortlisted {
+		return session, err
+	}
+	return a.recordEdit(sessionID, sessionEdit{
+		label: "shortlist",
+		undo:  func() error { _, err := a.applyShortlisted(sessionID, key, !shortlisted); return err },
+		redo:  func() error { _, err := a.applyShortlisted(sessionID, key, shortlisted); return err },
+	})
+}
+
+// SetSessionPin pins or unpins one of the session's movies, for this and
+// future comparisons, and reorders the session's results. It can be undone
+// with Undo
+func (a *App) SetSessionPin(sessionID string, key string, pinned bool) (ComparisonSession, error) {
+	movie, err := a.sessionMovie(sessionID, key)
+	if err != nil {
+		return ComparisonSession{}, err
+	}
+	session, err := a.applyPin(sessionID, key, pinned)
+	if err != nil || movie.Pinned == pinned {
+		return session, err
+	}
+	return a.recordEdit(sessionID, sessionEdit{
+		label: "pin",
+		undo:  func() error { _, err := a.applyPin(sessionID, key, !pinned); return err },
+		redo:  func() error { _, err := a.applyPin(sessionID, key, pinned); return err },
+	})
+}
+
+// ExcludeSessionMovie vetoes one of the session's movies from this and
+// future comparisons, dropping it from the session's results. It can be
+// undone with Undo
+func (a *App) ExcludeSessionMovie(sessionID string, key string) (ComparisonSession, error) {
+	a.mu.Lock()
+	session, err := a.sessionLocked(sessionID)
+	if err != nil {
+		a.mu.Unlock()
+		return ComparisonSession{}, err
+	}
+	snapshot := a.sessionView(session)
+	snapshot.movies = session.movies
+	a.mu.Unlock()
+	if _, ok := snapshot.movie(key); !ok {
+		return snapshot.view(), fmt.Errorf("movie '%s' is not in this session", key)
+	}
+
+	view, err := a.applyExclude(sessionID, key)
+	if err != nil {
+		return view, err
+	}
+	return a.recordEdit(sessionID, sessionEdit{
+		label: "exclusion",
+		undo:  func() error { return a.restoreExcluded(sessionID, key, snapshot) },
+		redo:  func() error { _, err := a.applyExclude(sessionID, key); return err },
+	})
+}
+
+// applyVote sets a participant's vote on a session's movie
+func (a *App) applyVote(sessionID string, participant string, key string, value int) (ComparisonSession, error) {
+	if value != VoteUp && value != VoteVeto && value != 0 {
+		return ComparisonSession{}, fmt.Errorf("invalid vote %d", value)
+	}
+	return a.updateSession(sessionID, func(s *ComparisonSession) error {
+		if !slices.Contains(s.Participants, participant) {
+			return fmt.Errorf("'%s' is not in this session", participant)
+		}
+		if _, ok := s.movie(key); !ok {
+			return fmt.Errorf("movie '%s' is not in this session", key)
+		}
+		_, err := a.recordVote(s.ShareCode, participant, key, value)
+		return err
+	})
+}
+
+// applyShortlisted adds a session's movie to its shortlist or removes it
+func (a *App) applyShortlisted(sessionID string, key string, shortlisted bool) (ComparisonSession, error) {
+	return a.updateSession(sessionID, func(s *ComparisonSession) error {
+		if _, ok := s.movie(key); !ok {
+			return fmt.Errorf("movie '%s' is not in this session", key)
+		}
+		at := slices.Index(s.Shortlist, key)
+		switch {
+		case shortlisted && at < 0:
+			s.Shortlist = append(s.Shortlist, key)
+		case !shortlisted && at >= 0:
+			s.Shortlist = slices.Delete(slices.Clone(s.Shortlist), at, at+1)
+		}
+		return nil
+	})
+}
+
+// applyPin pins or unpins a session's movie and reorders the results
+func (a *App) applyPin(sessionID string, key string, pinned bool) (ComparisonSession, error) {
+	if _, err := a.BulkPin([]string{key}, pinned); err != nil {
+		return ComparisonSession{}, err
+	}
+	return a.updateSession(sessionID, func(s *ComparisonSession) error {
+		movies := slices.Clone(s.movies)
+		for i := range movies {
+			if movies[i].Key == key {
+				movies[i].Pinned = pinned
+			}
+		}
+		sortResults(movies, s.Options, a.compositeConfigured())
+		s.movies = movies
+		return nil
+	})
+}
+
+// applyExclude excludes a session's movie and drops it from the results,
+// shortlist and votes
+func (a *App) applyExclude(sessionID string, key string) (ComparisonSession, error) {
+	movie, err := a.sessionMovie(sessionID, key)
+	if err != nil {
+		return ComparisonSession{}, err
+	}
+	if err := a.ExcludeMovie(key, movie.Title); err != nil {
+		return ComparisonSession{}, err
+	}
+	return a.updateSession(sessionID, func(s *ComparisonSession) error {
+		s.movies = slices.DeleteFunc(slices.Clone(s.movies), func(m Movie) bool { return m.Key == key })
+		s.Total = len(s.movies)
+		s.Shortlist = slices.DeleteFunc(slices.Clone(s.Shortlist), func(k string) bool { return k == key })
+		a.setShareMovies(s.ShareCode, sharedMovies(s.movies))
+		return nil
+	})
+}
+
+// updateSession applies a change to a session under the lock, returning
+// the updated session without its movies
+func (a *App) updateSession(sessionID string, change func(s *ComparisonSession) error) (ComparisonSession, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	session, err := a.sessionLocked(sessionID)
+	if err != nil {
+		return ComparisonSession{}, err
+	}
+	if err := change(session); err != nil {
+		return a.sessionView(session), err
+	}
+	return a.sessionView(session), nil
+}
+
+// sessionMovie returns one of a session's movies
+func (a *App) sessionMovie(sessionID string, key string) (Movie, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	session, err := a.sessionLocked(sessionID)
+	if err != nil {
+		return Movie{}, err
+	}
+	movie, ok := session.movie(key)
+	if !ok {
+		return Movie{}, fmt.Errorf("movie '%s' is not in this session", key)
+	}
+	return movie, nil
+}
+
+// sessionLocked looks a session up, marking it used; a.mu must be held
+func (a *App) sessionLocked(sessionID string) (*ComparisonSession, error) {
+	session, ok := a.sessions[sessionID]
+	if !ok {
+		return nil, fmt.Errorf("session '%s' not found or expired", sessionID)
+	}
+	session.lastUsed = time.Now()
+	return session, nil
+}
+
+// setResult replaces the session's results with a comparison's
+func (s *ComparisonSession) setResult(result ComparisonResultV2) {
+	s.GeneratedAt = result.GeneratedAt
+	s.Stats = result.Stats
+	s.Warnings = result.Warnings
+	s.movies = result.Movies
+	s.Total = len(result.Movies)
+	s.lastUsed = time.Now()
+}
+
+// movie returns the session's movie with a key
+func (s *ComparisonSession) movie(key string) (Movie, bool) {
+	for _, movie := range s.movies {
+		if movie.Key == key {
+			return movie, true
+		}
+	}
+	return Movie{}, false
+}
+
+// sessionView returns a copy of a session safe to hand out, with the votes
+// cast on its share; a.mu must be held
+func (a *App) sessionView(s *ComparisonSession) ComparisonSession {
+	out := s.view()
+	if share, err := a.share(s.ShareCode); err == nil {
+		out.Votes = share.Votes
+	}
+	return out
+}
+
+// view returns a copy of the session safe to hand out
+func (s *ComparisonSession) view() ComparisonSession {
+	out := *s
+	out.Participants = slices.Clone(s.Participants)
+	out.Shortlist = slices.Clone(s.Shortlist)
+	out.Votes = make(map[string]map[string]int, len(s.Votes))
+	for key, voters := range s.Votes {
+		out.Votes[key] = maps.Clone(voters)
+	}
+	out.movies = nil
+	out.undoStack, out.redoStack = nil, nil
+	out.CanUndo, out.CanRedo = "", ""
+	if n := len(s.undoStack); n > 0 {
+		out.CanUndo = s.undoStack[n-1].label
+	}
+	if n := len(s.redoStack); n > 0 {
+		out.CanRedo = s.redoStack[n-1].label
+	}
+	return out
+}
+
+// sortResults orders comparison results the way CompareV2 does
+func sortResults(movies []Movie, opts CompareOptions, byComposite bool) {
+	sortMovies(movies, byComposite)
+	if opts.SortBy == SortNewlyAvailable {
+		sortNewlyAvailable(movies, time.Now())
+	}
+	if opts.SortBy == SortAwards {
+		sortAwards(movies)
+	}
+	if opts.SortBy == SortAffinity {
+		sortAffinity(movies)
+	}
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"log"
+	"strings"
+	"time"
+)
+
+// Settings are user preferences persisted in the data directory
+type Settings struct {
+	// TMDBAPIKey is the last TMDB API key that passed validation
+	TMDBAPIKey string `json:"tmdb_api_key"`
+
+	// LetterboxdSession is the value of the signed-in "letterboxd.user.CURRENT"
+	// cookie, enabling features that need an authenticated session
+	LetterboxdSession string `json:"letterboxd_session"`
+
+	// PlaceholderBackground and PlaceholderForeground colour the generated
+	// artwork shown for films without a poster
+	PlaceholderBackground string `json:"placeholder_background"`
+	PlaceholderForeground string `json:"placeholder_foreground"`
+
+	// MetadataProviders is the chain of metadata providers tried in order,
+	// e.g. ["tmdb", "wikidata"]; empty uses the default chain
+	MetadataProviders []string `json:"metadata_providers"`
+
+	// Language is the ISO 639-1 code used to localize formatted values
+	Language string `json:"language"`
+
+	// Region is the country whose age certifications are shown
+	Region string `json:"region"`
+
+	// ScrapeDelayMS is the spacing between Letterboxd requests
+	ScrapeDelayMS int `json:"scrape_delay_ms"`
+
+	// Usernames are the participants saved during onboarding
+	Usernames []string `json:"usernames"`
+
+	// LogoLanguages orders the preferred languages of title logos, e.g.
+	// ["de", "en", "xx"] where "xx" means no text
+	LogoLanguages []string `json:"logo_languages"`
+
+	// HighResArtwork requests posters at their original resolution
+	HighResArtwork bool `json:"high_res_artwork"`
+
+	// CompositeWeights blends the rating sources into the composite score
+	CompositeWeights CompositeWeights `json:"composite_weights"`
+
+	// OnboardingComplete is set once the first-run setup has been finished
+	OnboardingComplete bool `json:"onboarding_complete"`
+
+	// ShareRelay is the server shared vote sessions are published to; empty
+	// keeps them on this instance
+	ShareRelay string `json:"share_relay"`
+
+	// CacheMaxMB caps the scraped page cache and the TMDB response cache
+	// each; zero uses the default
+	CacheMaxMB int `json:"cache_max_mb"`
+
+	// OMDbAPIKey enables Rotten Tomatoes, Metacritic and IMDb scores
+	OMDbAPIKey string `json:"omdb_api_key"`
+
+	// Kodi is the Kodi instance checked for local copies of films
+	Kodi KodiSettings `json:"kodi"`
+
+	// IncludeAdult lets TMDB searches return adult titles
+	IncludeAdult bool `json:"include_adult"`
+
+	// AnimeMetadata adds studios, source material and franchise info from
+	// AniList or Jikan to animated films
+	AnimeMetadata bool `json:"anime_metadata"`
+
+	// AudioPreference filters foreign-language films by whether they can be
+	// watched subtitled or dubbed; empty doesn't filter
+	AudioPreference string `json:"audio_preference"`
+
+	// RulesetURL is a remote scraping ruleset overriding the built-in and
+	// local ones; empty doesn't use one
+	RulesetURL string `json:"ruleset_url"`
+
+	// BrowserFallback renders watchlists in a headless browser when the
+	// static scrape finds suspiciously few films
+	BrowserFallback bool `json:"browser_fallback"`
+
+	// Profiling serves pprof endpoints and logs pipeline stage timings
+	Profiling bool `json:"profiling"`
+
+	// ContentPolicy restricts the films every comparison returns
+	ContentPolicy ContentPolicy `json:"content_policy"`
+
+	// StreamingServices are the services the group subscribes to, by
+	// JustWatch name; accessibility data is only read from these
+	StreamingServices []string `json:"streaming_services"`
+}
+
+// loadSettings reads the persisted settings, returning defaults on error
+func (a *App) loadSettings() Settings {
+	var settings Settings
+	if err := a.store.load("settings", &settings); err != nil {
+		log.Printf("Could not load settings: %v", err)
+	}
+	return settings
+}
+
+// applySettings configures the app from the stored settings, on startup or
+// after they were replaced
+func (a *App) applySettings() {
+	settings := a.loadSettings()
+	// Use the last key that passed validation until a new one is set
+	a.runtimeAPIKey = settings.TMDBAPIKey
+	a.letterboxdLimiter.setInterval(time.Duration(scrapeDelayMS(settings)) * time.Millisecond)
+	a.scrapeCache.setLimit(cacheLimitBytes(settings))
+	a.tmdbCache.setLimit(cacheLimitBytes(settings))
+	if err := a.applyProfiling(settings); err != nil {
+		log.Printf("Could not apply profiling setting: %v", err)
+	}
+}
+
+// saveSettings persists the settings
+func (a *App) saveSettings(settings Settings) error {
+	return a.store.save("settings", settings)
+}
+
+// SetLetterboxdSession stores the Letterboxd session cookie used for
+// authenticated features; an empty value signs out
+func (a *App) SetLetterboxdSession(cookie string) error {
+	settings := a.loadSettings()
+	settings.LetterboxdSession = strings.TrimSpace(cookie)
+	return a.saveSettings(settings)
+}
+
+// SetIncludeAdult sets whether TMDB searches may match adult titles
+func (a *App) SetIncludeAdult(include bool) error {
+	settings := a.loadSettings()
+	settings.IncludeAdult = include
+	return a.saveSettings(settings)
+}
@@ -0,0 +1,423 @@
+package main
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"math/big"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	// shareCodeAlphabet leaves out look-alike characters so codes can be read aloud
+	shareCodeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+	// shareCodeLength is the number of characters in a share code
+	shareCodeLength = 6
+	// shareTTL is how long a shared session stays open for votes
+	shareTTL = 24 * time.Hour
+	// maxShares caps the shared sessions open at once, so a public relay
+	// can't be made to hold unbounded state; the oldest are closed first
+	maxShares = 256
+	// maxSharedMovies caps the films on one shared shortlist
+	maxSharedMovies = 100
+	// maxShareVoters caps the voters on one shared session
+	maxShareVoters = 50
+	// maxVoterName caps the length of a voter's name, in bytes
+	maxVoterName = 64
+	// maxShareBody caps the size of a shortlist posted to the relay
+	maxShareBody = 256 << 10
+)
+
+// Vote values
+const (
+	VoteUp   = 1
+	VoteVeto = -1
+)
+
+// SharedMovie is a film on a shared shortlist with its running vote tally
+type SharedMovie struct {
+	Key         string `json:"key"`
+	Title       string `json:"title"`
+	URL         string `json:"url"`
+	PosterURL   string `json:"poster_url"`
+	ReleaseYear string `json:"release_year"`
+	Up          int    `json:"up"`
+	Vetoes      int    `json:"vetoes"`
+}
+
+// VoteSession is a comparison result published under a short code so remote
+// friends can open the same shortlist and vote from their own devices
+type VoteSession struct {
+	Code      string    `json:"code"`
+	CreatedAt time.Time `json:"created_at"`
+	// ExpiresAt is zero for the share backing a comparison session, which
+	// stays open until the session is closed
+	ExpiresAt time.Time     `json:"expires_at"`
+	Movies    []SharedMovie `json:"movies"`
+	// Votes maps movie key to voter name to VoteUp or VoteVeto
+	Votes map[string]map[string]int `json:"votes"`
+}
+
+// expired reports whether the session has closed for votes
+func (s *VoteSession) expired(now time.Time) bool {
+	return !s.ExpiresAt.IsZero() && now.After(s.ExpiresAt)
+}
+
+// tally fills in each movie's vote counts
+func (s *VoteSession) tally() {
+	for i := range s.Movies {
+		s.Movies[i].Up, s.Movies[i].Vetoes = 0, 0
+		for _, value := range s.Votes[s.Movies[i].Key] {
+			if value == VoteUp {
+				s.Movies[i].Up++
+			} else if value == VoteVeto {
+				s.Movies[i].Vetoes++
+			}
+		}
+	}
+}
+
+// voters returns the names of everyone with a vote on the session
+func (s *VoteSession) voters() map[string]bool {
+	voters := make(map[string]bool)
+	for _, votes := range s.Votes {
+		for voter := range votes {
+			voters[voter] = true
+		}
+	}
+	return voters
+}
+
+// copy returns a deep copy that is safe to hand out while votes keep arriving
+func (s *VoteSession) copy() VoteSession {
+	out := *s
+	out.Movies = append([]SharedMovie{}, s.Movies...)
+	out.Votes = make(map[string]map[string]int, len(s.Votes))
+	for key, voters := range s.Votes {
+		out.Votes[key] = make(map[string]int, len(voters))
+		for voter, value := range voters {
+			out.Votes[key][voter] = value
+		}
+	}
+	out.tally()
+	return out
+}
+
+// voteRequest is the body of a vote submission
+type voteRequest struct {
+	Voter string `json:"voter"`
+	Key   string `json:"key"`
+	Value int    `json:"value"`
+}
+
+// SetShareRelay configures the relay that shared sessions are published to,
+// e.g. a Klisse instance running with --serve; empty keeps sessions local
+func (a *App) SetShareRelay(relayURL string) error {
+	relayURL = strings.TrimSuffix(strings.TrimSpace(relayURL), "/")
+	if relayURL != "" {
+		parsed, err := url.Parse(relayURL)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return fmt.Errorf("invalid relay URL '%s'", relayURL)
+		}
+	}
+	settings := a.loadSettings()
+	settings.ShareRelay = relayURL
+	return a.saveSettings(settings)
+}
+
+// ShareResult publishes a shortlist under a short code, on the configured
+// relay if there is one and otherwise on this instance
+func (a *App) ShareResult(movies []Movie) (VoteSession, error) {
+	if len(movies) == 0 {
+		return VoteSession{}, fmt.Errorf("nothing to share")
+	}
+	shared := sharedMovies(movies)
+	if relay := a.loadSettings().ShareRelay; relay != "" {
+		var session VoteSession
+		err := a.relayJSON(http.MethodPost, relay+"/api/share", shared, &session)
+		return session, err
+	}
+	return a.createShare(shared)
+}
+
+// sharedMovies converts results to the films of a shared shortlist
+func sharedMovies(movies []Movie) []SharedMovie {
+	shared := make([]SharedMovie, len(movies))
+	for i, movie := range movies {
+		shared[i] = SharedMovie{
+			Key:         movie.Key,
+			Title:       movie.Title,
+			URL:         movie.URL,
+			PosterURL:   movie.PosterURL,
+			ReleaseYear: movie.ReleaseYear,
+		}
+	}
+	return shared
+}
+
+// GetShare returns a shared session with its current vote tally
+func (a *App) GetShare(code string) (VoteSession, error) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if relay := a.relayFor(code); relay != "" {
+		var session VoteSession
+		err := a.relayJSON(http.MethodGet, relay+"/api/share/"+url.PathEscape(code), nil, &session)
+		return session, err
+	}
+	return a.share(code)
+}
+
+// CastVote records a voter's upvote (VoteUp) or veto (VoteVeto) on a shared
+// movie; voting 0 withdraws the vote
+func (a *App) CastVote(code string, voter string, key string, value int) (VoteSession, error) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if relay := a.relayFor(code); relay != "" {
+		var session VoteSession
+		err := a.relayJSON(http.MethodPost, relay+"/api/share/"+url.PathEscape(code)+"/vote",
+			voteRequest{Voter: voter, Key: key, Value: value}, &session)
+		return session, err
+	}
+	return a.vote(code, voter, key, value)
+}
+
+// relayFor returns the relay a shared session lives on, or "" if it is
+// local; comparison sessions' own shares are always local
+func (a *App) relayFor(code string) string {
+	a.sharesMu.Lock()
+	_, local := a.shares[code]
+	a.sharesMu.Unlock()
+	if local {
+		return ""
+	}
+	return a.loadSettings().ShareRelay
+}
+
+// createShare opens a local session under a new code
+func (a *App) createShare(movies []SharedMovie) (VoteSession, error) {
+	if len(movies) == 0 {
+		return VoteSession{}, fmt.Errorf("nothing to share")
+	}
+	if len(movies) > maxSharedMovies {
+		return VoteSession{}, fmt.Errorf("a shared shortlist can hold at most %d films", maxSharedMovies)
+	}
+	return a.openShare(movies, time.Now().Add(shareTTL))
+}
+
+// openShare opens a local session under a new code that closes at
+// expiresAt, or never if it is zero
+func (a *App) openShare(movies []SharedMovie, expiresAt time.Time) (VoteSession, error) {
+	a.sharesMu.Lock()
+	defer a.sharesMu.Unlock()
+
+	now := time.Now()
+	for code, session := range a.shares {
+		if session.expired(now) {
+			delete(a.shares, code)
+		}
+	}
+	a.evictSharesLocked(maxShares - 1)
+
+	var code string
+	for code == "" || a.shares[code] != nil {
+		var err error
+		if code, err = newShareCode(); err != nil {
+			return VoteSession{}, err
+		}
+	}
+
+	session := &VoteSession{
+		Code:      code,
+		CreatedAt: now,
+		ExpiresAt: expiresAt,
+		Movies:    movies,
+		Votes:     make(map[string]map[string]int),
+	}
+	if a.shares == nil {
+		a.shares = make(map[string]*VoteSession)
+	}
+	a.shares[code] = session
+	return session.copy(), nil
+}
+
+// evictSharesLocked closes the oldest expiring sessions until at most keep
+// are open; sessions backing a comparison session are left to it.
+// a.sharesMu must be held
+func (a *App) evictSharesLocked(keep int) {
+	for len(a.shares) > keep {
+		oldest := ""
+		for code, session := range a.shares {
+			if session.ExpiresAt.IsZero() {
+				continue
+			}
+			if oldest == "" || session.CreatedAt.Before(a.shares[oldest].CreatedAt) {
+				oldest = code
+			}
+		}
+		if oldest == "" {
+			return
+		}
+		delete(a.shares, oldest)
+	}
+}
+
+// share returns a local session by code
+func (a *App) share(code string) (VoteSession, error) {
+	a.sharesMu.Lock()
+	defer a.sharesMu.Unlock()
+
+	session, ok := a.shares[code]
+	if !ok || session.expired(time.Now()) {
+		return VoteSession{}, fmt.Errorf("no shared session with code '%s'", code)
+	}
+	return session.copy(), nil
+}
+
+// vote records a vote on a local session and emits the updated tally on
+// "share:vote" so the desktop window follows votes from other devices
+func (a *App) vote(code string, voter string, key string, value int) (VoteSession, error) {
+	session, err := a.recordVote(code, voter, key, value)
+	if err == nil {
+		a.emit("share:vote", session)
+	}
+	return session, err
+}
+
+// recordVote applies a vote to a local session
+func (a *App) recordVote(code string, voter string, key string, value int) (VoteSession, error) {
+	voter = strings.TrimSpace(voter)
+	if voter == "" {
+		return VoteSession{}, fmt.Errorf("no voter name provided")
+	}
+	if len(voter) > maxVoterName {
+		return VoteSession{}, fmt.Errorf("voter name is longer than %d characters", maxVoterName)
+	}
+	if value != VoteUp && value != VoteVeto && value != 0 {
+		return VoteSession{}, fmt.Errorf("invalid vote %d", value)
+	}
+
+	a.sharesMu.Lock()
+	defer a.sharesMu.Unlock()
+
+	session, ok := a.shares[code]
+	if !ok || session.expired(time.Now()) {
+		return VoteSession{}, fmt.Errorf("no shared session with code '%s'", code)
+	}
+	found := false
+	for _, movie := range session.Movies {
+		found = found || movie.Key == key
+	}
+	if !found {
+		return VoteSession{}, fmt.Errorf("'%s' is not on the shared shortlist", key)
+	}
+	if voters := session.voters(); value != 0 && !voters[voter] && len(voters) >= maxShareVoters {
+		return VoteSession{}, fmt.Errorf("the shared session already has %d voters", maxShareVoters)
+	}
+
+	if value == 0 {
+		delete(session.Votes[key], voter)
+		if len(session.Votes[key]) == 0 {
+			delete(session.Votes, key)
+		}
+	} else {
+		if session.Votes[key] == nil {
+			session.Votes[key] = make(map[string]int)
+		}
+		session.Votes[key][voter] = value
+	}
+	return session.copy(), nil
+}
+
+// setShareMovies replaces the films of a local session, dropping the votes
+// on films no longer on it
+func (a *App) setShareMovies(code string, movies []SharedMovie) {
+	a.sharesMu.Lock()
+	defer a.sharesMu.Unlock()
+
+	session, ok := a.shares[code]
+	if !ok {
+		return
+	}
+	session.Movies = movies
+	for key := range session.Votes {
+		if !slices.ContainsFunc(movies, func(m SharedMovie) bool { return m.Key == key }) {
+			delete(session.Votes, key)
+		}
+	}
+}
+
+// setShareVotes replaces the votes on one film of a local session
+func (a *App) setShareVotes(code string, key string, votes map[string]int) {
+	a.sharesMu.Lock()
+	defer a.sharesMu.Unlock()
+
+	if session, ok := a.shares[code]; ok && len(votes) > 0 {
+		session.Votes[key] = maps.Clone(votes)
+	}
+}
+
+// closeShare closes a local session
+func (a *App) closeShare(code string) {
+	a.sharesMu.Lock()
+	defer a.sharesMu.Unlock()
+	delete(a.shares, code)
+}
+
+// newShareCode returns a random share code
+func newShareCode() (string, error) {
+	code := make([]byte, shareCodeLength)
+	for i := range code {
+		n, err := rand.Int(rand.Reader, big.NewInt(int64(len(shareCodeAlphabet))))
+		if err != nil {
+			return "", fmt.Errorf("could not generate share code: %v", err)
+		}
+		code[i] = shareCodeAlphabet[n.Int64()]
+	}
+	return string(code), nil
+}
+
+// relayJSON sends a JSON request to the share relay and decodes its reply
+func (a *App) relayJSON(method string, requestURL string, body interface{}, v interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("could not encode relay request: %v", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequest(method, requestURL, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	client := &http.Client{Transport: sharedTransport, Timeout: 10 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		a.metrics.countError("share_relay")
+		return fmt.Errorf("share relay unreachable: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		a.metrics.countError("share_relay")
+		var reply struct {
+			Error string `json:"error"`
+		}
+		if json.NewDecoder(resp.Body).Decode(&reply) == nil && reply.Error != "" {
+			return fmt.Errorf("share relay error: %s", reply.Error)
+		}
+		return fmt.Errorf("share relay error: status code %d", resp.StatusCode)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("failed to parse relay response: %v", err)
+	}
+	return nil
+}
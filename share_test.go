@@ -0,0 +1,132 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestVoteSessionTally(t *testing.T) {
+	session := VoteSession{
+		Movies: []SharedMovie{{Key: "alien"}, {Key: "heat"}, {Key: "ran"}},
+		Votes: map[string]map[string]int{
+			"alien": {"sam": VoteUp, "alex": VoteUp, "jo": VoteVeto},
+			"heat":  {"sam": VoteVeto},
+		},
+	}
+	session.tally()
+
+	want := map[string][2]int{"alien": {2, 1}, "heat": {0, 1}, "ran": {0, 0}}
+	for _, movie := range session.Movies {
+		if got := [2]int{movie.Up, movie.Vetoes}; got != want[movie.Key] {
+			t.Errorf("%s tallied %d up, %d vetoes; want %v", movie.Key, movie.Up, movie.Vetoes, want[movie.Key])
+		}
+	}
+}
+
+func TestRecordVote(t *testing.T) {
+	a := &App{}
+	share, err := a.createShare([]SharedMovie{{Key: "alien"}, {Key: "heat"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		voter   string
+		key     string
+		value   int
+		wantErr bool
+		wantUp  int
+	}{
+		{name: "upvote", voter: "sam", key: "alien", value: VoteUp, wantUp: 1},
+		{name: "second upvote", voter: " alex ", key: "alien", value: VoteUp, wantUp: 2},
+		{name: "change to veto", voter: "alex", key: "alien", value: VoteVeto, wantUp: 1},
+		{name: "withdraw", voter: "sam", key: "alien", value: 0, wantUp: 0},
+		{name: "no voter", voter: "  ", key: "alien", value: VoteUp, wantErr: true},
+		{name: "long voter name", voter: strings.Repeat("x", maxVoterName+1), key: "alien", value: VoteUp, wantErr: true},
+		{name: "invalid value", voter: "sam", key: "alien", value: 2, wantErr: true},
+		{name: "film not shared", voter: "sam", key: "ran", value: VoteUp, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			session, err := a.recordVote(share.Code, tt.voter, tt.key, tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("recordVote() error = %v, want error %v", err, tt.wantErr)
+			}
+			if err == nil && session.Movies[0].Up != tt.wantUp {
+				t.Errorf("alien has %d upvotes, want %d", session.Movies[0].Up, tt.wantUp)
+			}
+		})
+	}
+}
+
+func TestShareLimits(t *testing.T) {
+	a := &App{}
+	if _, err := a.createShare(nil); err == nil {
+		t.Error("createShare() accepted an empty shortlist")
+	}
+	if _, err := a.createShare(make([]SharedMovie, maxSharedMovies+1)); err == nil {
+		t.Error("createShare() accepted more than maxSharedMovies films")
+	}
+
+	share, err := a.createShare([]SharedMovie{{Key: "alien"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < maxShareVoters; i++ {
+		if _, err := a.recordVote(share.Code, fmt.Sprintf("voter%d", i), "alien", VoteUp); err != nil {
+			t.Fatalf("vote %d: %v", i, err)
+		}
+	}
+	if _, err := a.recordVote(share.Code, "one too many", "alien", VoteUp); err == nil {
+		t.Error("recordVote() accepted more than maxShareVoters voters")
+	}
+	if _, err := a.recordVote(share.Code, "voter0", "alien", VoteVeto); err != nil {
+		t.Errorf("recordVote() refused an existing voter: %v", err)
+	}
+}
+
+func TestOpenShareEvictsOldest(t *testing.T) {
+	a := &App{}
+	kept, err := a.openShare([]SharedMovie{{Key: "alien"}}, time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	first, err := a.createShare([]SharedMovie{{Key: "alien"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	a.shares[first.Code].CreatedAt = time.Now().Add(-time.Hour)
+	for i := 0; i < maxShares; i++ {
+		if _, err := a.createShare([]SharedMovie{{Key: "alien"}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if len(a.shares) > maxShares {
+		t.Errorf("%d shares open, want at most %d", len(a.shares), maxShares)
+	}
+	if _, err := a.share(first.Code); err == nil {
+		t.Error("the oldest share was not closed")
+	}
+	if _, err := a.share(kept.Code); err != nil {
+		t.Errorf("a comparison session's share was closed: %v", err)
+	}
+}
+
+func TestShareExpiry(t *testing.T) {
+	a := &App{}
+	share, err := a.createShare([]SharedMovie{{Key: "alien"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	a.shares[share.Code].ExpiresAt = time.Now().Add(-time.Minute)
+	if _, err := a.share(share.Code); err == nil {
+		t.Error("share() returned an expired share")
+	}
+	if _, err := a.recordVote(share.Code, "sam", "alien", VoteUp); err == nil {
+		t.Error("recordVote() accepted a vote on an expired share")
+	}
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// shortlistDiversity trades relevance for variety when picking the next film;
+// 0 returns the plain top picks, 1 ignores relevance entirely
+const shortlistDiversity = 0.6
+
+// GenerateShortlist picks a diverse shortlist of size films spanning
+// different genres, decades and runtimes, greedily selecting the film whose
+// relevance minus its similarity to already chosen films is highest. Bare
+// results are hydrated first, since genres and runtimes drive the variety
+func (a *App) GenerateShortlist(movies []Movie, size int) ([]Movie, error) {
+	if size <= 0 {
+		return nil, fmt.Errorf("shortlist size must be positive")
+	}
+	movies = a.hydrateBare(movies)
+	if len(movies) <= size {
+		return movies, nil
+	}
+
+	relevance := shortlistRelevance(movies)
+	chosen := make([]Movie, 0, size)
+	used := make([]bool, len(movies))
+
+	for len(chosen) < size {
+		best := -1
+		bestScore := 0.0
+		for i := range movies {
+			if used[i] {
+				continue
+			}
+			maxSimilarity := 0.0
+			for j := range chosen {
+				if sim := movieSimilarity(&movies[i], &chosen[j]); sim > maxSimilarity {
+					maxSimilarity = sim
+				}
+			}
+			score := (1-shortlistDiversity)*relevance[i] - shortlistDiversity*maxSimilarity
+			if best == -1 || score > bestScore {
+				best, bestScore = i, score
+			}
+		}
+		used[best] = true
+		chosen = append(chosen, movies[best])
+	}
+
+	return chosen, nil
+}
+
+// shortlistRelevance scores each movie in [0, 1] from its overlap score and rating
+func shortlistRelevance(movies []Movie) []float64 {
+	maxScore := 0.0
+	for _, movie := range movies {
+		if s := overlapScore(movie); s > maxScore {
+			maxScore = s
+		}
+	}
+
+	relevance := make([]float64, len(movies))
+	for i, movie := range movies {
+		overlap := 0.0
+		if maxScore > 0 {
+			overlap = overlapScore(movie) / maxScore
+		}
+		relevance[i] = 0.7*overlap + 0.3*movie.Rating/10
+	}
+	return relevance
+}
+
+// overlapScore returns the weighted overlap score, falling back to the raw count
+func overlapScore(movie Movie) float64 {
+	if movie.Score > 0 {
+		return movie.Score
+	}
+	return float64(movie.Count)
+}
+
+// movieSimilarity estimates in [0, 1] how alike two movies are by genre
+// overlap, decade and runtime bracket
+func movieSimilarity(a, b *Movie) float64 {
+	genres := 0.0
+	if len(a.Genres) > 0 || len(b.Genres) > 0 {
+		shared := 0
+		seen := make(map[string]bool, len(a.Genres))
+		for _, g := range a.Genres {
+			seen[g] = true
+		}
+		union := len(a.Genres)
+		for _, g := range b.Genres {
+			if seen[g] {
+				shared++
+			} else {
+				union++
+			}
+		}
+		genres = float64(shared) / float64(union)
+	}
+
+	decade := 0.0
+	yearA, errA := strconv.Atoi(a.ReleaseYear)
+	yearB, errB := strconv.Atoi(b.ReleaseYear)
+	if errA == nil && errB == nil && yearA/10 == yearB/10 {
+		decade = 1
+	}
+
+	runtime := 0.0
+	if a.Runtime > 0 && b.Runtime > 0 && runtimeBracket(a.Runtime) == runtimeBracket(b.Runtime) {
+		runtime = 1
+	}
+
+	return 0.6*genres + 0.25*decade + 0.15*runtime
+}
+
+// runtimeBracket groups runtimes into short, standard, long and epic
+func runtimeBracket(minutes int) int {
+	switch {
+	case minutes < 90:
+		return 0
+	case minutes < 120:
+		return 1
+	case minutes < 150:
+		return 2
+	default:
+		return 3
+	}
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+)
+
+// Suggestion is a TMDB similar/recommended film cross-referenced with the group's watchlists
+type Suggestion struct {
+	TMDBID       int      `json:"tmdb_id"`
+	Title        string   `json:"title"`
+	ReleaseYear  string   `json:"release_year"`
+	PosterURL    string   `json:"poster_url"`
+	Overview     string   `json:"overview"`
+	Rating       float64  `json:"rating"`
+	Source       string   `json:"source"`
+	OnWatchlists []string `json:"on_watchlists"`
+}
+
+// tmdbMovieList represents a paged TMDB list of movies such as /similar
+type tmdbMovieList struct {
+	Results []struct {
+		ID          int     `json:"id"`
+		Title       string  `json:"title"`
+		ReleaseDate string  `json:"release_date"`
+		PosterPath  string  `json:"poster_path"`
+		Overview    string  `json:"overview"`
+		VoteAverage float64 `json:"vote_average"`
+	} `json:"results"`
+}
+
+// GetSimilar returns films like the given TMDB movie, taken from TMDB's
+// recommendations and similar lists, with films already on participants'
+// watchlists from the last comparison ranked first
+func (a *App) GetSimilar(movieID int) ([]Suggestion, error) {
+	apiKey := a.getTMDBAPIKey()
+	if apiKey == "" || len(apiKey) < 10 {
+		return nil, fmt.Errorf("TMDB API key not configured")
+	}
+	if movieID <= 0 {
+		return nil, fmt.Errorf("invalid TMDB movie ID: %d", movieID)
+	}
+
+	// Index the group's watchlists by TMDB ID where an alias or an earlier
+	// hydration knows it, and by Letterboxd film slug otherwise, so
+	// same-titled remakes don't collide
+	aliases, err := a.GetAliases()
+	if err != nil {
+		log.Printf("Could not load aliases: %v", err)
+	}
+	a.mu.Lock()
+	hydrated := make(map[string]int)
+	for _, session := range a.sessions {
+		for _, movie := range session.movies {
+			if movie.TMDBID != 0 {
+				hydrated[movie.Key] = movie.TMDBID
+			}
+		}
+	}
+	byTMDB := make(map[int][]string)
+	bySlug := make(map[string][]string)
+	for user, watchlist := range a.watchlists {
+		for key := range watchlist {
+			id := aliases[key].TMDBID
+			if id == 0 {
+				id = hydrated[key]
+			}
+			if id != 0 {
+				byTMDB[id] = append(byTMDB[id], user)
+			} else {
+				bySlug[key] = append(bySlug[key], user)
+			}
+		}
+	}
+	a.mu.Unlock()
+
+	seen := make(map[int]bool)
+	var suggestions []Suggestion
+	var lastErr error
+
+	for _, source := range []string{"recommendations", "similar"} {
+		a.tmdbLimiter.wait()
+		list, err := a.fetchMovieList(fmt.Sprintf("https://api.themoviedb.org/3/movie/%d/%s?api_key=%s", movieID, source, apiKey))
+		if err != nil {
+			lastErr = err
+			continue
+		}
+
+		for _, result := range list.Results {
+			if seen[result.ID] {
+				continue
+			}
+			seen[result.ID] = true
+
+			suggestion := Suggestion{
+				TMDBID:      result.ID,
+				Title:       result.Title,
+				ReleaseYear: "----",
+				Overview:    result.Overview,
+				Rating:      result.VoteAverage,
+				Source:      source,
+			}
+			if len(result.ReleaseDate) >= 4 {
+				suggestion.ReleaseYear = result.ReleaseDate[:4]
+			}
+			if result.PosterPath != "" {
+				suggestion.PosterURL = fmt.Sprintf("https://image.tmdb.org/t/p/w500%s", result.PosterPath)
+			} else {
+				suggestion.PosterURL = a.placeholderPoster(result.Title)
+			}
+
+			// Letterboxd adds the year to the slug of all but the first film
+			// with a title, e.g. "the-thing-2011"
+			users := append([]string(nil), byTMDB[result.ID]...)
+			slug := letterboxdSlug(result.Title)
+			if suggestion.ReleaseYear != "----" {
+				users = appendMissing(users, bySlug[slug+"-"+suggestion.ReleaseYear])
+			}
+			users = appendMissing(users, bySlug[slug])
+			sort.Strings(users)
+			suggestion.OnWatchlists = users
+
+			suggestions = append(suggestions, suggestion)
+		}
+	}
+
+	if len(suggestions) == 0 && lastErr != nil {
+		return nil, lastErr
+	}
+
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		if len(suggestions[i].OnWatchlists) != len(suggestions[j].OnWatchlists) {
+			return len(suggestions[i].OnWatchlists) > len(suggestions[j].OnWatchlists)
+		}
+		return suggestions[i].Rating > suggestions[j].Rating
+	})
+
+	return suggestions, nil
+}
+
+// fetchMovieList requests a TMDB endpoint returning a list of movies
+func (a *App) fetchMovieList(requestURL string) (tmdbMovieList, error) {
+	var list tmdbMovieList
+
+	resp, err := a.tmdbGet(requestURL)
+	if err != nil {
+		return list, fmt.Errorf("network error: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		return list, fmt.Errorf("API error: %v", parseTMDBError(resp))
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&list); err != nil {
+		return list, fmt.Errorf("parse error: %v", err)
+	}
+
+	return list, nil
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Status is a diagnostics snapshot rendered by the frontend's status panel
+type Status struct {
+	APIKeyConfigured bool                 `json:"api_key_configured"`
+	APIKeyValid      bool                 `json:"api_key_valid"`
+	APIKeyError      string               `json:"api_key_error"`
+	TMDB             EndpointStatus       `json:"tmdb"`
+	Letterboxd       EndpointStatus       `json:"letterboxd"`
+	Caches           []CacheStatus        `json:"caches"`
+	LastComparison   time.Time            `json:"last_comparison"`
+	LastRefreshes    map[string]time.Time `json:"last_refreshes"`
+	CheckedAt        time.Time            `json:"checked_at"`
+}
+
+// EndpointStatus describes whether a remote service answered and how fast
+type EndpointStatus struct {
+	Reachable bool   `json:"reachable"`
+	LatencyMS int64  `json:"latency_ms"`
+	Error     string `json:"error"`
+}
+
+// CacheStatus describes the size and effectiveness of a local cache
+type CacheStatus struct {
+	Name      string  `json:"name"`
+	Entries   int     `json:"entries"`
+	SizeBytes int64   `json:"size_bytes"`
+	Hits      int     `json:"hits"`
+	Misses    int     `json:"misses"`
+	HitRate   float64 `json:"hit_rate"`
+}
+
+// refreshLog remembers when watchlists and comparisons were last refreshed
+type refreshLog struct {
+	mu             sync.Mutex
+	lastComparison time.Time
+	watchlists     map[string]time.Time
+}
+
+// watchlistRefreshed records a successful watchlist scrape for a user
+func (r *refreshLog) watchlistRefreshed(username string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.watchlists == nil {
+		r.watchlists = make(map[string]time.Time)
+	}
+	r.watchlists[username] = time.Now()
+}
+
+// comparisonFinished records the completion time of a comparison
+func (r *refreshLog) comparisonFinished() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.lastComparison = time.Now()
+}
+
+// snapshot returns a copy of the recorded timestamps
+func (r *refreshLog) snapshot() (time.Time, map[string]time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	watchlists := make(map[string]time.Time, len(r.watchlists))
+	for user, t := range r.watchlists {
+		watchlists[user] = t
+	}
+	return r.lastComparison, watchlists
+}
+
+// GetStatus checks API key validity, service reachability and cache health
+func (a *App) GetStatus() Status {
+	status := Status{CheckedAt: time.Now()}
+
+	apiKey := a.getTMDBAPIKey()
+	status.APIKeyConfigured = apiKey != "" && len(apiKey) >= 10
+
+	client := &http.Client{Transport: sharedTransport, Timeout: 10 * time.Second}
+	var wg sync.WaitGroup
+	wg.Add(2)
+
+	go func() {
+		defer wg.Done()
+		probeURL := "https://api.themoviedb.org/3/configuration"
+		if status.APIKeyConfigured {
+			probeURL += "?api_key=" + apiKey
+		}
+		code, endpoint := probe(client, probeURL)
+		status.TMDB = endpoint
+		if !status.APIKeyConfigured {
+			status.APIKeyError = "TMDB API key not configured"
+			return
+		}
+		switch {
+		case !endpoint.Reachable:
+			status.APIKeyError = "TMDB unreachable, key could not be verified"
+		case code == http.StatusOK:
+			status.APIKeyValid = true
+		case code == http.StatusUnauthorized:
+			status.APIKeyError = "Invalid TMDB API key"
+		default:
+			status.APIKeyError = fmt.Sprintf("TMDB API error: status code %d", code)
+		}
+	}()
+
+	go func() {
+		defer wg.Done()
+		_, status.Letterboxd = probe(client, "https://letterboxd.com/")
+	}()
+
+	wg.Wait()
+
+	status.Caches = []CacheStatus{a.scrapeCache.stats("letterboxd_pages"), a.tmdbCache.stats("tmdb_responses")}
+	status.LastComparison, status.LastRefreshes = a.refreshes.snapshot()
+
+	return status
+}
+
+// probe issues a GET request and reports reachability, latency and status code
+func probe(client *http.Client, target string) (int, EndpointStatus) {
+	start := time.Now()
+	resp, err := client.Get(target)
+	latency := time.Since(start).Milliseconds()
+	if err != nil {
+		return 0, EndpointStatus{LatencyMS: latency, Error: err.Error()}
+	}
+	resp.Body.Close()
+	return resp.StatusCode, EndpointStatus{Reachable: true, LatencyMS: latency}
+}
+
+// stats summarizes the on-disk size and hit rate of the cache
+func (t *cachingTransport) stats(name string) CacheStatus {
+	status := CacheStatus{Name: name}
+
+	entries, _ := os.ReadDir(t.folder())
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+		if info, err := entry.Info(); err == nil {
+			status.Entries++
+			status.SizeBytes += info.Size()
+		}
+	}
+
+	t.mu.Lock()
+	status.Hits, status.Misses = t.hits, t.misses
+	t.mu.Unlock()
+	if total := status.Hits + status.Misses; total > 0 {
+		status.HitRate = float64(status.Hits) / float64(total)
+	}
+
+	return status
+}
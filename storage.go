@@ -0,0 +1,94 @@
+package main
+
+import (
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"time"
+)
+
+// CacheStats is the disk usage of one cache kind
+type CacheStats struct {
+	Kind    string `json:"kind"`
+	Entries int    `json:"entries"`
+	Bytes   int64  `json:"bytes"`
+	// Oldest is when the least recently written entry was stored; zero when
+	// the cache is empty
+	Oldest time.Time `json:"oldest"`
+}
+
+// StorageStats summarizes the disk usage of the app's caches and data
+type StorageStats struct {
+	Caches []CacheStats `json:"caches"`
+	// TotalBytes covers the active profile's cache and data directories,
+	// including settings and other documents not broken down in Caches
+	TotalBytes int64 `json:"total_bytes"`
+}
+
+// GetStorageStats reports the size, entry count and oldest entry of each
+// cache kind, so users can see what takes up space before pruning it
+func (a *App) GetStorageStats() (StorageStats, error) {
+	var stats StorageStats
+	for _, kind := range []string{CachePages, CacheTMDB, CacheCheckpoints, CacheHistory} {
+		dirs, patterns, err := a.cacheLocations(kind)
+		if err != nil {
+			return stats, err
+		}
+		cache := CacheStats{Kind: kind}
+		for _, dir := range dirs {
+			cache.merge(dirUsage(dir))
+		}
+		for _, pattern := range patterns {
+			cache.merge(a.store.usage(pattern))
+		}
+		stats.Caches = append(stats.Caches, cache)
+	}
+
+	_, pageBytes, _ := dirUsage(a.scrapeCache.folder())
+	_, tmdbBytes, _ := dirUsage(a.tmdbCache.folder())
+	_, dataBytes, _ := a.store.usage("*")
+	stats.TotalBytes = pageBytes + tmdbBytes + dataBytes
+	return stats, nil
+}
+
+// PruneCache deletes the entries of a cache kind older than the given
+// number of days, e.g. scraped pages not refreshed in 90 days
+func (a *App) PruneCache(kind string, days int) (ClearResult, error) {
+	if days <= 0 {
+		return ClearResult{Kind: kind}, fmt.Errorf("days must be positive")
+	}
+	return a.clearCache(kind, time.Now().AddDate(0, 0, -days))
+}
+
+// merge adds the usage of some entries to the stats
+func (s *CacheStats) merge(entries int, bytes int64, oldest time.Time) {
+	s.Entries += entries
+	s.Bytes += bytes
+	if !oldest.IsZero() && (s.Oldest.IsZero() || oldest.Before(s.Oldest)) {
+		s.Oldest = oldest
+	}
+}
+
+// dirUsage returns the number, total size and oldest write time of the
+// files under dir
+func dirUsage(dir string) (int, int64, time.Time) {
+	var files int
+	var bytes int64
+	var oldest time.Time
+	filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return nil
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return nil
+		}
+		files++
+		bytes += info.Size()
+		if oldest.IsZero() || info.ModTime().Before(oldest) {
+			oldest = info.ModTime()
+		}
+		return nil
+	})
+	return files, bytes, oldest
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// dataStore persists small JSON documents in the app's data directory
+type dataStore struct {
+	mu  sync.Mutex
+	dir string
+	// synced is set when dir is in a folder shared between machines, whose
+	// lock must be held to write
+	synced bool
+	// renewed is when this machine last renewed the synced folder's lock
+	renewed time.Time
+}
+
+// newDataStore creates a store rooted at dir
+func newDataStore(dir string) *dataStore {
+	return &dataStore{dir: dir}
+}
+
+// load decodes the named document into v, leaving v untouched if it doesn't exist yet
+func (s *dataStore) load(name string, v interface{}) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := os.ReadFile(filepath.Join(s.dir, name+".json"))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("could not read %s: %v", name, err)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("could not parse %s: %v", name, err)
+	}
+	return nil
+}
+
+// save writes v as the named document, replacing it atomically
+func (s *dataStore) save(name string, v interface{}) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("could not encode %s: %v", name, err)
+	}
+	return s.writeLocked(name, data)
+}
+
+// write stores already encoded JSON as the named document
+func (s *dataStore) write(name string, data []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.writeLocked(name, data)
+}
+
+// writeLocked replaces the named document atomically; s.mu must be held
+func (s *dataStore) writeLocked(name string, data []byte) error {
+	if err := s.claimLocked(); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(s.dir, 0o755); err != nil {
+		return fmt.Errorf("could not create data directory: %v", err)
+	}
+
+	path := filepath.Join(s.dir, name+".json")
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("could not write %s: %v", name, err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("could not write %s: %v", name, err)
+	}
+	return nil
+}
+
+// remove deletes the named document; a missing document is not an error
+func (s *dataStore) remove(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.claimLocked(); err != nil {
+		return err
+	}
+	err := os.Remove(filepath.Join(s.dir, name+".json"))
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("could not remove %s: %v", name, err)
+	}
+	return nil
+}
+
+// removeMatching deletes the documents whose names match a filepath.Match
+// pattern and, unless before is zero, were last written before it; it
+// returns how many were removed and their total size
+func (s *dataStore) removeMatching(pattern string, before time.Time) (int, int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	paths, err := filepath.Glob(filepath.Join(s.dir, pattern+".json"))
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid document pattern '%s': %v", pattern, err)
+	}
+	if err := s.claimLocked(); err != nil {
+		return 0, 0, err
+	}
+	var files int
+	var size int64
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil || (!before.IsZero() && !info.ModTime().Before(before)) {
+			continue
+		}
+		if err := os.Remove(path); err != nil {
+			return files, size, fmt.Errorf("could not remove %s: %v", filepath.Base(path), err)
+		}
+		files++
+		size += info.Size()
+	}
+	return files, size, nil
+}
+
+// usage returns the number, total size and oldest write time of the
+// documents whose names match a filepath.Match pattern
+func (s *dataStore) usage(pattern string) (int, int64, time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	paths, _ := filepath.Glob(filepath.Join(s.dir, pattern+".json"))
+	var files int
+	var size int64
+	var oldest time.Time
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+		files++
+		size += info.Size()
+		if oldest.IsZero() || info.ModTime().Before(oldest) {
+			oldest = info.ModTime()
+		}
+	}
+	return files, size, oldest
+}
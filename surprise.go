@@ -0,0 +1,88 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	mathrand "math/rand"
+)
+
+// SurprisePick is a blind pick with everything that would spoil it redacted
+type SurprisePick struct {
+	Token            string   `json:"token"`
+	Runtime          int      `json:"runtime"`
+	FormattedRuntime string   `json:"formatted_runtime"`
+	Genres           []string `json:"genres"`
+	Certification    string   `json:"certification"`
+	Count            int      `json:"count"`
+}
+
+// PickSurprise chooses a movie at random, weighted by overlap score, and
+// returns only spoiler-free details; call Reveal with the token to see it
+func (a *App) PickSurprise(movies []Movie) (SurprisePick, error) {
+	if len(movies) == 0 {
+		return SurprisePick{}, fmt.Errorf("no movies to pick from")
+	}
+
+	var total float64
+	for _, movie := range movies {
+		total += overlapScore(movie)
+	}
+	target := mathrand.Float64() * total
+	chosen := movies[len(movies)-1]
+	for _, movie := range movies {
+		target -= overlapScore(movie)
+		if target < 0 {
+			chosen = movie
+			break
+		}
+	}
+
+	// Bare intersection results need details before they can be described
+	if chosen.TMDBID == 0 && chosen.Runtime == 0 {
+		a.enrichMovie(&chosen)
+	}
+
+	token, err := newToken()
+	if err != nil {
+		return SurprisePick{}, err
+	}
+
+	a.mu.Lock()
+	if a.surprises == nil {
+		a.surprises = make(map[string]Movie)
+	}
+	a.surprises[token] = chosen
+	a.mu.Unlock()
+
+	return SurprisePick{
+		Token:            token,
+		Runtime:          chosen.Runtime,
+		FormattedRuntime: chosen.FormattedRuntime,
+		Genres:           chosen.Genres,
+		Certification:    chosen.Certification,
+		Count:            chosen.Count,
+	}, nil
+}
+
+// Reveal returns the full movie behind a surprise pick
+func (a *App) Reveal(token string) (Movie, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	movie, ok := a.surprises[token]
+	if !ok {
+		return Movie{}, fmt.Errorf("unknown surprise pick")
+	}
+	delete(a.surprises, token)
+	return movie, nil
+}
+
+// newToken returns a random hex identifier
+func newToken() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("could not generate token: %v", err)
+	}
+	return hex.EncodeToString(b), nil
+}
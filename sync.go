@@ -0,0 +1,244 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// syncFolderFile, kept in the local data directory, holds the path of
+	// the synced folder used as the data directory instead
+	syncFolderFile = "sync-folder"
+	// syncLockFile, kept in the synced folder, records which machine last
+	// wrote to it
+	syncLockFile = "klisse.lock"
+	// lockLease is how long a machine keeps the synced folder after its
+	// last write; the other machines can't write until it runs out
+	lockLease = 5 * time.Minute
+	// lockRefresh is how often writes renew the lease
+	lockRefresh = time.Minute
+)
+
+// conflictPattern matches the copies Dropbox, Syncthing and Google Drive
+// make of a file changed on two machines at once
+var conflictPattern = regexp.MustCompile(`(?i)conflicted copy|\.sync-conflict-| \(\d+\)\.json$`)
+
+// folderLock is the contents of the synced folder's lock file
+type folderLock struct {
+	Host    string    `json:"host"`
+	Renewed time.Time `json:"renewed"`
+}
+
+// SyncStatus describes where local data is kept and whether the synced
+// folder needs attention
+type SyncStatus struct {
+	// Folder is the data directory, synced or not
+	Folder string `json:"folder"`
+	Synced bool   `json:"synced"`
+	// LockedBy is the other machine currently writing to the synced folder
+	LockedBy string `json:"locked_by"`
+	// Conflicts are conflicting copies the sync service made of data files,
+	// which Klisse ignores until they are merged or deleted by hand
+	Conflicts []string `json:"conflicts"`
+}
+
+// SetSyncFolder moves the data directory - settings, groups, history and
+// overrides - into a folder synced by Dropbox, Google Drive, Syncthing or
+// similar so machines in a household share it; an empty path returns to
+// the local directory. If the folder already holds Klisse data for the
+// active profile, e.g. set up from another machine, it is used as is;
+// otherwise the profile's current data is copied into it
+func (a *App) SetSyncFolder(path string) error {
+	base := GetDataDir()
+	if path = strings.TrimSpace(path); path != "" {
+		info, err := os.Stat(path)
+		if err != nil || !info.IsDir() {
+			return fmt.Errorf("'%s' is not a folder", path)
+		}
+		base = filepath.Join(path, "Klisse")
+	}
+	dir := profileDataDir(base, activeProfile())
+	if dir == a.store.folder() {
+		return nil
+	}
+
+	target := newDataStore(dir)
+	target.synced = path != ""
+	if target.synced {
+		if host := target.lockHolder(); host != "" {
+			return fmt.Errorf("the synced folder is in use on %s, try again in a few minutes", host)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "schema.json")); os.IsNotExist(err) {
+		docs, err := a.store.documents()
+		if err != nil {
+			return err
+		}
+		for name, data := range docs {
+			if err := target.write(name, data); err != nil {
+				return fmt.Errorf("could not copy data to the synced folder: %v", err)
+			}
+		}
+	}
+	if err := migrateStore(target); err != nil {
+		return err
+	}
+
+	pointer := filepath.Join(GetDataDir(), syncFolderFile)
+	if path == "" {
+		if err := os.Remove(pointer); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("could not forget the synced folder: %v", err)
+		}
+	} else {
+		if err := os.MkdirAll(GetDataDir(), 0o755); err != nil {
+			return fmt.Errorf("could not create data directory: %v", err)
+		}
+		if err := os.WriteFile(pointer, []byte(base), 0o644); err != nil {
+			return fmt.Errorf("could not remember the synced folder: %v", err)
+		}
+	}
+
+	a.store.release()
+	a.store.moveTo(dir, target.synced)
+	a.applySettings()
+	a.resetState()
+	return nil
+}
+
+// GetSyncStatus reports the data directory, which other machine holds the
+// synced folder, if any, and the conflicting copies found in it
+func (a *App) GetSyncStatus() SyncStatus {
+	dir := a.store.folder()
+	status := SyncStatus{Folder: dir, Synced: a.store.isSynced()}
+	if !status.Synced {
+		return status
+	}
+	status.LockedBy = a.store.lockHolder()
+	entries, _ := os.ReadDir(dir)
+	for _, entry := range entries {
+		if conflictPattern.MatchString(entry.Name()) {
+			status.Conflicts = append(status.Conflicts, entry.Name())
+		}
+	}
+	sort.Strings(status.Conflicts)
+	return status
+}
+
+// dataDir returns the data directory, the synced folder if one is set;
+// profiles other than the default one are kept in subdirectories of it
+func dataDir() string {
+	data, err := os.ReadFile(filepath.Join(GetDataDir(), syncFolderFile))
+	if err != nil {
+		return GetDataDir()
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// newAppStore opens the data store of the active profile, logging any
+// conflicting copies left by the sync service
+func newAppStore() *dataStore {
+	base := dataDir()
+	store := newDataStore(profileDataDir(base, activeProfile()))
+	store.synced = base != GetDataDir()
+	if store.synced {
+		entries, _ := os.ReadDir(store.dir)
+		for _, entry := range entries {
+			if conflictPattern.MatchString(entry.Name()) {
+				log.Printf("Synced folder has a conflicting copy: %s", entry.Name())
+			}
+		}
+	}
+	return store
+}
+
+// folder returns the store's directory
+func (s *dataStore) folder() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.dir
+}
+
+// isSynced reports whether the store is in a synced folder
+func (s *dataStore) isSynced() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.synced
+}
+
+// moveTo points the store at another directory
+func (s *dataStore) moveTo(dir string, synced bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.dir = dir
+	s.synced = synced
+	s.renewed = time.Time{}
+}
+
+// lockHolder returns the other machine holding the synced folder's lock,
+// or "" if it is free or held by this one
+func (s *dataStore) lockHolder() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	lock, ok := s.readLock()
+	if !ok || lock.Host == hostname() || time.Since(lock.Renewed) > lockLease {
+		return ""
+	}
+	return lock.Host
+}
+
+// claimLocked takes or renews the synced folder's lock before a write,
+// failing while another machine holds it; s.mu must be held
+func (s *dataStore) claimLocked() error {
+	if !s.synced || time.Since(s.renewed) < lockRefresh {
+		return nil
+	}
+	host := hostname()
+	if lock, ok := s.readLock(); ok && lock.Host != host && time.Since(lock.Renewed) <= lockLease {
+		return fmt.Errorf("the synced folder is in use on %s", lock.Host)
+	}
+	data, _ := json.Marshal(folderLock{Host: host, Renewed: time.Now()})
+	if err := os.MkdirAll(s.dir, 0o755); err != nil {
+		return fmt.Errorf("could not create data directory: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(s.dir, syncLockFile), data, 0o644); err != nil {
+		return fmt.Errorf("could not lock the synced folder: %v", err)
+	}
+	s.renewed = time.Now()
+	return nil
+}
+
+// release gives up the synced folder's lock if this machine holds it
+func (s *dataStore) release() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if lock, ok := s.readLock(); s.synced && ok && lock.Host == hostname() {
+		os.Remove(filepath.Join(s.dir, syncLockFile))
+	}
+	s.renewed = time.Time{}
+}
+
+// readLock reads the synced folder's lock file; s.mu must be held
+func (s *dataStore) readLock() (folderLock, bool) {
+	var lock folderLock
+	data, err := os.ReadFile(filepath.Join(s.dir, syncLockFile))
+	if err != nil || json.Unmarshal(data, &lock) != nil {
+		return lock, false
+	}
+	return lock, true
+}
+
+// hostname identifies this machine in the lock file
+func hostname() string {
+	host, err := os.Hostname()
+	if err != nil || host == "" {
+		return "unknown"
+	}
+	return host
+}
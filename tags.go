@@ -0,0 +1,133 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// TagCount is a tag in use and how many movies carry it
+type TagCount struct {
+	Tag   string `json:"tag"`
+	Count int    `json:"count"`
+}
+
+// AddTag applies a tag such as "this month" or "needs projector" to a movie
+func (a *App) AddTag(key string, tag string) error {
+	return a.TagMovies([]string{key}, tag)
+}
+
+// TagMovies applies a tag to several movies at once
+func (a *App) TagMovies(keys []string, tag string) error {
+	tag = strings.TrimSpace(tag)
+	if tag == "" {
+		return fmt.Errorf("no tag provided")
+	}
+
+	a.tagsMu.Lock()
+	defer a.tagsMu.Unlock()
+
+	tags, err := a.loadTags()
+	if err != nil {
+		return err
+	}
+	for _, key := range keys {
+		if key == "" {
+			return fmt.Errorf("no movie key provided")
+		}
+		if !hasTag(tags[key], tag) {
+			tags[key] = append(tags[key], tag)
+			sort.Strings(tags[key])
+		}
+	}
+	return a.store.save("tags", tags)
+}
+
+// RemoveTag removes a tag from a movie
+func (a *App) RemoveTag(key string, tag string) error {
+	a.tagsMu.Lock()
+	defer a.tagsMu.Unlock()
+
+	tags, err := a.loadTags()
+	if err != nil {
+		return err
+	}
+	var kept []string
+	for _, t := range tags[key] {
+		if !strings.EqualFold(t, strings.TrimSpace(tag)) {
+			kept = append(kept, t)
+		}
+	}
+	if len(kept) == 0 {
+		delete(tags, key)
+	} else {
+		tags[key] = kept
+	}
+	return a.store.save("tags", tags)
+}
+
+// GetTags returns every movie's tags keyed by movie key
+func (a *App) GetTags() (map[string][]string, error) {
+	a.tagsMu.Lock()
+	defer a.tagsMu.Unlock()
+	return a.loadTags()
+}
+
+// ListTags returns the tags in use with their movie counts, most used first
+func (a *App) ListTags() ([]TagCount, error) {
+	tags, err := a.GetTags()
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int)
+	for _, movieTags := range tags {
+		for _, tag := range movieTags {
+			counts[tag]++
+		}
+	}
+	list := make([]TagCount, 0, len(counts))
+	for tag, count := range counts {
+		list = append(list, TagCount{Tag: tag, Count: count})
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Count != list[j].Count {
+			return list[i].Count > list[j].Count
+		}
+		return list[i].Tag < list[j].Tag
+	})
+	return list, nil
+}
+
+// loadTags reads the tags keyed by movie key
+func (a *App) loadTags() (map[string][]string, error) {
+	tags := make(map[string][]string)
+	if err := a.store.load("tags", &tags); err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
+
+// hasTag reports whether tags contains tag, ignoring case
+func hasTag(tags []string, tag string) bool {
+	for _, t := range tags {
+		if strings.EqualFold(t, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// filterTags keeps only movies carrying at least one of the wanted tags
+func filterTags(movies []Movie, wanted []string) []Movie {
+	filtered := movies[:0]
+	for _, movie := range movies {
+		for _, tag := range wanted {
+			if hasTag(movie.Tags, tag) {
+				filtered = append(filtered, movie)
+				break
+			}
+		}
+	}
+	return filtered
+}
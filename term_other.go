@@ -0,0 +1,10 @@
+//go:build !linux && !darwin && !freebsd && !netbsd && !openbsd
+
+package main
+
+import "fmt"
+
+// enableRawMode is unsupported here, so the TUI reads keys line by line
+func enableRawMode(fd int) (func(), error) {
+	return nil, fmt.Errorf("raw terminal mode not supported on this platform")
+}
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Seasonal themes selectable per comparison
+const (
+	ThemeHalloween  = "halloween"
+	ThemeChristmas  = "christmas"
+	ThemeValentines = "valentines"
+	ThemePride      = "pride"
+	ThemeAnimation  = "animation"
+	ThemeMusic      = "music"
+)
+
+// Theme is a seasonal pick mode matching films by TMDB keywords and genres
+type Theme struct {
+	Name  string `json:"name"`
+	Label string `json:"label"`
+
+	// Keywords match TMDB keywords containing any of them
+	Keywords []string `json:"keywords"`
+
+	// Genres match films in any of these TMDB genres
+	Genres []string `json:"genres"`
+
+	// From and Until bound the season the theme is suggested in, as "MM-DD";
+	// themes without a season are never suggested
+	From  string `json:"from"`
+	Until string `json:"until"`
+}
+
+// themes are the built-in themes, the seasonal ones in calendar order
+var themes = []Theme{
+	{
+		Name:     ThemeValentines,
+		Label:    "Valentine's Day",
+		Keywords: []string{"valentine", "romantic comedy", "first love", "love at first sight"},
+		Genres:   []string{"Romance"},
+		From:     "02-01",
+		Until:    "02-14",
+	},
+	{
+		Name:     ThemePride,
+		Label:    "Pride",
+		Keywords: []string{"lgbt", "gay", "lesbian", "bisexual", "transgender", "queer", "drag queen", "coming out"},
+		From:     "06-01",
+		Until:    "06-30",
+	},
+	{
+		Name:     ThemeHalloween,
+		Label:    "Halloween",
+		Keywords: []string{"halloween", "haunted house", "slasher", "witch", "vampire", "werewolf", "zombie", "ghost"},
+		Genres:   []string{"Horror"},
+		From:     "10-01",
+		Until:    "10-31",
+	},
+	{
+		Name:     ThemeChristmas,
+		Label:    "Christmas",
+		Keywords: []string{"christmas", "santa claus", "holiday season"},
+		From:     "12-01",
+		Until:    "12-26",
+	},
+	{
+		Name:     ThemeAnimation,
+		Label:    "Animation night",
+		Keywords: []string{"anime"},
+		Genres:   []string{"Animation"},
+	},
+	{
+		Name:     ThemeMusic,
+		Label:    "Music night",
+		Keywords: []string{"concert", "live performance", "rockumentary"},
+		Genres:   []string{"Music"},
+	},
+}
+
+// GetThemes returns the seasonal themes followed by the other pick modes
+func (a *App) GetThemes() []Theme {
+	return themes
+}
+
+// SuggestTheme returns the name of the theme in season today, or "" if
+// none is
+func (a *App) SuggestTheme() string {
+	return themeInSeason(time.Now())
+}
+
+// themeInSeason returns the name of the theme whose season includes a date
+func themeInSeason(now time.Time) string {
+	today := now.Format("01-02")
+	for _, theme := range themes {
+		if today >= theme.From && today <= theme.Until {
+			return theme.Name
+		}
+	}
+	return ""
+}
+
+// themeFor resolves a theme by name
+func themeFor(name string) (Theme, error) {
+	for _, theme := range themes {
+		if theme.Name == name {
+			return theme, nil
+		}
+	}
+	return Theme{}, fmt.Errorf("unknown theme '%s'", name)
+}
+
+// matches reports whether TMDB details carry any of the theme's keywords
+// or genres
+func (t Theme) matches(details TMDBMovie) bool {
+	for _, genre := range details.Genres {
+		for _, name := range t.Genres {
+			if genre.Name == name {
+				return true
+			}
+		}
+	}
+	for _, keyword := range details.Keywords.Keywords {
+		name := strings.ToLower(keyword.Name)
+		for _, word := range t.Keywords {
+			if strings.Contains(name, word) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// themesFor returns the names of the themes a film fits
+func themesFor(details TMDBMovie) []string {
+	var names []string
+	for _, theme := range themes {
+		if theme.matches(details) {
+			names = append(names, theme.Name)
+		}
+	}
+	return names
+}
+
+// hasTheme reports whether a hydrated movie fits the named theme
+func hasTheme(movie Movie, name string) bool {
+	for _, theme := range movie.Themes {
+		if theme == name {
+			return true
+		}
+	}
+	return false
+}
+
+// applyTheme filters the movies to those fitting the theme or, in boost
+// mode, keeps every movie and adds a vote to the score of the ones that fit
+func applyTheme(movies []Movie, name string, boost bool) []Movie {
+	if boost {
+		for i := range movies {
+			if hasTheme(movies[i], name) {
+				movies[i].Score += voteWeight
+			}
+		}
+		return movies
+	}
+
+	filtered := movies[:0]
+	for _, movie := range movies {
+		if hasTheme(movie, name) {
+			filtered = append(filtered, movie)
+		}
+	}
+	return filtered
+}
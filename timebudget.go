@@ -0,0 +1,95 @@
+package main
+
+import "fmt"
+
+const (
+	// maxBudgetFilms is the most films combined to fill an evening
+	maxBudgetFilms = 3
+	// budgetProposals is how many combinations FillTimeBudget proposes
+	budgetProposals = 5
+	// maxBudgetMinutes bounds the time window, keeping the table small
+	maxBudgetMinutes = 24 * 60
+)
+
+// TimeBudgetPlan is a combination of films that fits a time window
+type TimeBudgetPlan struct {
+	Movies           []Movie `json:"movies"`
+	Runtime          int     `json:"runtime"`
+	FormattedRuntime string  `json:"formatted_runtime"`
+	// Spare is the time left over, in minutes
+	Spare int `json:"spare"`
+	// Score is the summed overlap score of the films
+	Score float64 `json:"score"`
+}
+
+// budgetPick is a knapsack table entry: the best films found so far with a
+// given count and total runtime
+type budgetPick struct {
+	films []int
+	score float64
+}
+
+// FillTimeBudget proposes combinations of up to maxBudgetFilms movies that
+// best fill the given minutes, fullest first and, for the same runtime, by
+// overlap score; movies without details are hydrated first
+func (a *App) FillTimeBudget(movies []Movie, minutes int) ([]TimeBudgetPlan, error) {
+	if minutes <= 0 || minutes > maxBudgetMinutes {
+		return nil, fmt.Errorf("time window must be between 1 and %d minutes", maxBudgetMinutes)
+	}
+
+	candidates := a.hydrateBare(movies)
+
+	// table[c][t] holds the best c films totalling exactly t minutes
+	table := make([][]*budgetPick, maxBudgetFilms+1)
+	for c := range table {
+		table[c] = make([]*budgetPick, minutes+1)
+	}
+	table[0][0] = &budgetPick{}
+	for i, movie := range candidates {
+		runtime := movie.Runtime
+		if runtime <= 0 || runtime > minutes {
+			continue
+		}
+		score := overlapScore(movie)
+		// Counts and runtimes are walked downwards so each film is used once
+		for c := maxBudgetFilms; c >= 1; c-- {
+			for t := minutes; t >= runtime; t-- {
+				prev := table[c-1][t-runtime]
+				if prev == nil {
+					continue
+				}
+				if best := table[c][t]; best == nil || prev.score+score > best.score {
+					films := append(append([]int(nil), prev.films...), i)
+					table[c][t] = &budgetPick{films: films, score: prev.score + score}
+				}
+			}
+		}
+	}
+
+	var plans []TimeBudgetPlan
+	for t := minutes; t > 0 && len(plans) < budgetProposals; t-- {
+		var best *budgetPick
+		for c := 1; c <= maxBudgetFilms; c++ {
+			if pick := table[c][t]; pick != nil && (best == nil || pick.score > best.score) {
+				best = pick
+			}
+		}
+		if best == nil {
+			continue
+		}
+		plan := TimeBudgetPlan{
+			Runtime:          t,
+			FormattedRuntime: formatRuntime(t, a.language()),
+			Spare:            minutes - t,
+			Score:            best.score,
+		}
+		for _, index := range best.films {
+			plan.Movies = append(plan.Movies, candidates[index])
+		}
+		plans = append(plans, plan)
+	}
+	if len(plans) == 0 {
+		return nil, fmt.Errorf("no films fit in %d minutes", minutes)
+	}
+	return plans, nil
+}
@@ -0,0 +1,233 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// certificationRegion is the default country whose age certification is reported
+const certificationRegion = "US"
+
+// detailAppends are the sections appended to a full details request
+const detailAppends = "credits,images,release_dates,keywords"
+
+var (
+	titleYearRegex = regexp.MustCompile(`\s*\((\d{4})\)$`)
+	slugYearRegex  = regexp.MustCompile(`-(\d{4})(?:-\d+)?$`)
+)
+
+// tmdbGet performs a TMDB API request through the response cache within
+// the rate-limit budget, recording its latency and failures. Concurrent
+// identical requests, such as two workers enriching the same title, share
+// one call
+func (a *App) tmdbGet(requestURL string) (*http.Response, error) {
+	return a.tmdbFlights.get(requestURL, func() (*http.Response, error) {
+		return a.tmdbFetch(requestURL)
+	})
+}
+
+// tmdbFetch performs one TMDB API request for tmdbGet
+func (a *App) tmdbFetch(requestURL string) (*http.Response, error) {
+	a.tmdbBudget.acquire()
+	start := time.Now()
+	client := &http.Client{Transport: a.tmdbCache, Timeout: 30 * time.Second}
+	resp, err := client.Get(requestURL)
+	a.metrics.since("tmdb_request", start)
+	if err != nil || resp.StatusCode >= 400 {
+		a.metrics.countError("tmdb")
+	}
+	// Stale copies served while offline carry outdated rate-limit headers
+	if err == nil && resp.Header.Get(cacheStatusHeader) != "stale" {
+		a.tmdbBudget.observe(resp)
+	}
+	return resp, err
+}
+
+// newTMDBCache creates the TMDB response cache, which revalidates cached
+// responses with their ETag so unchanged ones cost a 304
+func newTMDBCache(dir string) *cachingTransport {
+	cache := newCachingTransport(dir, nil)
+	cache.key = tmdbCacheKey
+	return cache
+}
+
+// tmdbCacheKey keys TMDB responses by URL without the API key, which must
+// not be written to disk
+func tmdbCacheKey(u *url.URL) string {
+	query := u.Query()
+	query.Del("api_key")
+	keyed := *u
+	keyed.RawQuery = query.Encode()
+	return keyed.String()
+}
+
+// TMDB status codes with a meaning of their own, as reported in error
+// bodies; see https://developer.themoviedb.org/docs/errors
+const (
+	tmdbAuthFailed       = 3
+	tmdbInvalidKey       = 7
+	tmdbSuspendedKey     = 10
+	tmdbRequestLimit     = 25
+	tmdbResourceNotFound = 34
+)
+
+// tmdbError is a failed TMDB response, with the status TMDB reports in its
+// body when there is one
+type tmdbError struct {
+	HTTPStatus    int    `json:"-"`
+	StatusCode    int    `json:"status_code"`
+	StatusMessage string `json:"status_message"`
+}
+
+// parseTMDBError reads the error body of a failed TMDB response
+func parseTMDBError(resp *http.Response) *tmdbError {
+	e := &tmdbError{HTTPStatus: resp.StatusCode}
+	json.NewDecoder(io.LimitReader(resp.Body, 64<<10)).Decode(e)
+	return e
+}
+
+// Error implements error
+func (e *tmdbError) Error() string {
+	if e.StatusMessage == "" {
+		return fmt.Sprintf("status code %d", e.HTTPStatus)
+	}
+	return fmt.Sprintf("%s (status code %d)", strings.TrimSuffix(e.StatusMessage, "."), e.HTTPStatus)
+}
+
+// invalidKey reports whether TMDB rejected the API key
+func (e *tmdbError) invalidKey() bool {
+	switch e.StatusCode {
+	case tmdbAuthFailed, tmdbInvalidKey, tmdbSuspendedKey:
+		return true
+	}
+	return e.StatusCode == 0 && e.HTTPStatus == http.StatusUnauthorized
+}
+
+// rateLimited reports whether the request was over TMDB's rate limit
+func (e *tmdbError) rateLimited() bool {
+	return e.StatusCode == tmdbRequestLimit || e.HTTPStatus == http.StatusTooManyRequests
+}
+
+// notFound reports whether the requested resource doesn't exist
+func (e *tmdbError) notFound() bool {
+	return e.StatusCode == tmdbResourceNotFound || (e.StatusCode == 0 && e.HTTPStatus == http.StatusNotFound)
+}
+
+// tmdbCertification returns the age certification (e.g. "PG-13") for a region
+func tmdbCertification(details TMDBMovie, region string) string {
+	for _, country := range details.ReleaseDates.Results {
+		if country.ISO31661 != region {
+			continue
+		}
+		for _, release := range country.ReleaseDates {
+			if release.Certification != "" {
+				return release.Certification
+			}
+		}
+	}
+	return ""
+}
+
+// TMDB release types, as used in release_dates
+const (
+	releaseTheatrical = 3
+	releaseDigital    = 4
+	releasePhysical   = 5
+)
+
+// tmdbReleaseDate returns the earliest release of the given type in a
+// region as "YYYY-MM-DD", or "" if there is none
+func tmdbReleaseDate(details TMDBMovie, region string, releaseType int) string {
+	earliest := ""
+	for _, country := range details.ReleaseDates.Results {
+		if country.ISO31661 != region {
+			continue
+		}
+		for _, release := range country.ReleaseDates {
+			if release.Type != releaseType || len(release.ReleaseDate) < 10 {
+				continue
+			}
+			if date := release.ReleaseDate[:10]; earliest == "" || date < earliest {
+				earliest = date
+			}
+		}
+	}
+	return earliest
+}
+
+// splitTitleYear separates a trailing "(YYYY)" from a title
+func splitTitleYear(title string) (string, string) {
+	matches := titleYearRegex.FindStringSubmatch(title)
+	if len(matches) < 2 {
+		return title, ""
+	}
+	return strings.TrimSpace(titleYearRegex.ReplaceAllString(title, "")), matches[1]
+}
+
+// filmYear returns a film's title without any year suffix and its release
+// year, read from the title or, for remakes, from the Letterboxd slug
+// (e.g. "nosferatu-2024")
+func filmYear(title string, filmURL string) (string, string) {
+	title, year := splitTitleYear(title)
+	if year != "" {
+		return title, year
+	}
+
+	matches := slugYearRegex.FindStringSubmatch(movieKey(filmURL))
+	if len(matches) < 2 {
+		return title, ""
+	}
+	// Years in the future belong to titles like "blade-runner-2049"
+	if y, err := strconv.Atoi(matches[1]); err != nil || y < 1870 || y > time.Now().Year()+5 {
+		return title, ""
+	}
+	// Titles ending in the same number are not disambiguated ("1917")
+	if strings.HasSuffix(title, matches[1]) {
+		return title, ""
+	}
+	return title, matches[1]
+}
+
+// bestCandidate picks the search result that best matches the title and
+// year: an exact year beats a neighbouring year, an exact title beats a
+// partial one, and TMDB's own relevance order breaks ties
+func bestCandidate(results TMDBSearchResult, title string, year string, includeAdult bool) int {
+	wantTitle := normalizeTitle(title)
+	wantYear, _ := strconv.Atoi(year)
+
+	bestID, bestScore := 0, -1
+	for rank, result := range results.Results {
+		// Obscure titles sometimes share a name with adult entries
+		if result.Adult && !includeAdult {
+			continue
+		}
+		score := 0
+		if normalizeTitle(result.Title) == wantTitle || normalizeTitle(result.OriginalTitle) == wantTitle {
+			score += 2
+		}
+		if wantYear > 0 && len(result.ReleaseDate) >= 4 {
+			gotYear, _ := strconv.Atoi(result.ReleaseDate[:4])
+			switch gotYear - wantYear {
+			case 0:
+				score += 4
+			case -1, 1:
+				score += 1
+			}
+		}
+		// Only the first few results are plausible; later ones rarely beat them
+		if rank >= 5 {
+			break
+		}
+		if score > bestScore {
+			bestID, bestScore = result.ID, score
+		}
+	}
+	return bestID
+}
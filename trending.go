@@ -0,0 +1,64 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/gocolly/colly/v2"
+)
+
+// GetTrending returns the keys of the films popular on Letterboxd this week,
+// most popular first
+func (a *App) GetTrending() ([]string, error) {
+	defer a.metrics.since("trending_scrape", time.Now())
+	c := a.newCollector()
+
+	var keys []string
+	var scrapeErr error
+
+	rules := a.ruleset()
+	selectors := rules.Selectors
+	c.OnHTML(selectors.Poster+" "+selectors.FilmPoster, func(e *colly.HTMLElement) {
+		if link := e.Attr(selectors.LinkAttr); link != "" {
+			keys = append(keys, movieKey(link))
+		}
+	})
+
+	c.OnError(func(r *colly.Response, e error) {
+		a.metrics.countError("scrape")
+		scrapeErr = e
+	})
+
+	if err := c.Visit(rules.URLs.PopularThisWeek); err != nil {
+		return nil, fmt.Errorf("could not visit popular films: %v", err)
+	}
+	if scrapeErr != nil {
+		return nil, scrapeErr
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("no popular films found")
+	}
+	return keys, nil
+}
+
+// applyTrending raises the overlap score of movies trending on Letterboxd,
+// by a vote for the most popular film this week, tapering off down the list
+func (a *App) applyTrending(movies []Movie) {
+	trending, err := a.GetTrending()
+	if err != nil {
+		log.Printf("Could not fetch trending films: %v", err)
+		return
+	}
+	rank := make(map[string]int, len(trending))
+	for i, key := range trending {
+		if _, ok := rank[key]; !ok {
+			rank[key] = i
+		}
+	}
+	for i := range movies {
+		if r, ok := rank[movies[i].Key]; ok {
+			movies[i].Score += voteWeight * (1 - float64(r)/float64(len(trending)))
+		}
+	}
+}
@@ -0,0 +1,346 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"image"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+)
+
+const (
+	// tuiListHeight is how many movies the TUI lists at once
+	tuiListHeight = 12
+	// tuiPosterWidth is the width of posters drawn in the terminal, in cells
+	tuiPosterWidth = 24
+	// asciiRamp shades posters from dark to light in terminals without colour
+	asciiRamp = " .:-=+*#%@"
+)
+
+// tui is the state of the interactive terminal comparison browser
+type tui struct {
+	app     *App
+	out     io.Writer
+	movies  []Movie
+	cursor  int
+	offset  int
+	picks   map[string]bool
+	details map[string]Movie
+	posters map[string][]string
+	message string
+}
+
+// runTUI compares the users' watchlists and lets them browse the results
+// with the keyboard, picking and vetoing films
+func runTUI(app *App, usernames []string, preset string) error {
+	fmt.Printf("Comparing watchlists of %s...\n", strings.Join(usernames, ", "))
+	movies, err := app.FindCommonMovies(usernames, preset)
+	if err != nil {
+		return err
+	}
+	if len(movies) == 0 {
+		fmt.Println("No films in common.")
+		return nil
+	}
+
+	t := &tui{
+		app:     app,
+		out:     os.Stdout,
+		movies:  movies,
+		picks:   make(map[string]bool),
+		details: make(map[string]Movie),
+		posters: make(map[string][]string),
+	}
+
+	keys := make(chan string)
+	if restore, err := enableRawMode(int(os.Stdin.Fd())); err == nil {
+		defer restore()
+		go readKeys(os.Stdin, keys)
+	} else {
+		t.message = "Type a key and press Enter"
+		go readLines(os.Stdin, keys)
+	}
+
+	fmt.Fprint(t.out, "\x1b[?25l")
+	defer fmt.Fprint(t.out, "\x1b[?25h")
+
+	t.render()
+	for key := range keys {
+		if key == "q" {
+			break
+		}
+		t.handle(key)
+		if len(t.movies) == 0 {
+			break
+		}
+		t.render()
+	}
+
+	fmt.Fprint(t.out, "\x1b[2J\x1b[H")
+	t.printPicks()
+	return nil
+}
+
+// handle applies a key press
+func (t *tui) handle(key string) {
+	t.message = ""
+	switch key {
+	case "up", "k":
+		t.move(-1)
+	case "down", "j":
+		t.move(1)
+	case "enter":
+		t.loadDetails()
+	case "p":
+		key := t.movies[t.cursor].Key
+		t.picks[key] = !t.picks[key]
+	case "v":
+		movie := t.movies[t.cursor]
+		if err := t.app.ExcludeMovie(movie.Key, movie.Title); err != nil {
+			t.message = fmt.Sprintf("Could not veto: %v", err)
+			return
+		}
+		delete(t.picks, movie.Key)
+		t.movies = append(t.movies[:t.cursor], t.movies[t.cursor+1:]...)
+		t.move(0)
+		t.message = fmt.Sprintf("Vetoed %s", movie.Title)
+	}
+}
+
+// move shifts the cursor, scrolling the list to keep it visible
+func (t *tui) move(delta int) {
+	t.cursor += delta
+	if t.cursor >= len(t.movies) {
+		t.cursor = len(t.movies) - 1
+	}
+	if t.cursor < 0 {
+		t.cursor = 0
+	}
+	if t.cursor < t.offset {
+		t.offset = t.cursor
+	}
+	if t.cursor >= t.offset+tuiListHeight {
+		t.offset = t.cursor - tuiListHeight + 1
+	}
+}
+
+// loadDetails fetches TMDB details and the poster of the selected movie
+func (t *tui) loadDetails() {
+	movie := t.movies[t.cursor]
+	if _, ok := t.details[movie.Key]; ok {
+		return
+	}
+	t.message = "Loading details..."
+	t.render()
+
+	detailed := movie
+	if err := t.app.enrichMovie(&detailed); err != nil {
+		t.message = fmt.Sprintf("Could not load details: %v", err)
+	}
+	t.details[movie.Key] = detailed
+	if poster, err := renderPoster(detailed.PosterURL, tuiPosterWidth); err == nil {
+		t.posters[movie.Key] = poster
+	}
+	t.message = ""
+}
+
+// render redraws the whole screen
+func (t *tui) render() {
+	var b strings.Builder
+	b.WriteString("\x1b[2J\x1b[H")
+	fmt.Fprintf(&b, "\x1b[1mKlisse\x1b[0m  %d films in common   ↑/↓ move  enter details  p pick  v veto  q quit\n\n", len(t.movies))
+
+	for i := t.offset; i < len(t.movies) && i < t.offset+tuiListHeight; i++ {
+		movie := t.movies[i]
+		cursor, pick := "  ", " "
+		if i == t.cursor {
+			cursor = "> "
+		}
+		if t.picks[movie.Key] {
+			pick = "★"
+		}
+		line := fmt.Sprintf("%s%s %-50s %d users", cursor, pick, truncate(movie.Title, 50), movie.Count)
+		if i == t.cursor {
+			line = "\x1b[7m" + line + "\x1b[0m"
+		}
+		b.WriteString(line + "\n")
+	}
+
+	b.WriteString("\n")
+	if movie, ok := t.details[t.movies[t.cursor].Key]; ok {
+		info := []string{
+			"\x1b[1m" + movie.Title + "\x1b[0m",
+			strings.TrimSpace(fmt.Sprintf("%s  %s  %s", movie.ReleaseYear, movie.FormattedRuntime, movie.Certification)),
+			"Rating: " + movie.FormattedRating,
+			"Director: " + movie.Director.Name,
+			strings.Join(movie.Genres, ", "),
+			"",
+		}
+		info = append(info, wrapText(movie.Overview, 60)...)
+
+		poster := t.posters[movie.Key]
+		for i := 0; i < len(poster) || i < len(info); i++ {
+			left := strings.Repeat(" ", tuiPosterWidth)
+			if i < len(poster) {
+				left = poster[i]
+			}
+			right := ""
+			if i < len(info) {
+				right = info[i]
+			}
+			b.WriteString(left + "  " + right + "\n")
+		}
+	}
+
+	if t.message != "" {
+		b.WriteString("\n" + t.message + "\n")
+	}
+	fmt.Fprint(t.out, b.String())
+}
+
+// printPicks lists the films picked during the session
+func (t *tui) printPicks() {
+	var picks []string
+	for _, movie := range t.movies {
+		if t.picks[movie.Key] {
+			picks = append(picks, movie.Title)
+		}
+	}
+	if len(picks) == 0 {
+		fmt.Fprintln(t.out, "No films picked.")
+		return
+	}
+	fmt.Fprintln(t.out, "Picked:")
+	for _, title := range picks {
+		fmt.Fprintf(t.out, "  ★ %s\n", title)
+	}
+}
+
+// readKeys decodes raw key presses, including arrow keys, from r
+func readKeys(r io.Reader, keys chan<- string) {
+	defer close(keys)
+	buf := make([]byte, 8)
+	for {
+		n, err := r.Read(buf)
+		if err != nil {
+			return
+		}
+		switch input := string(buf[:n]); {
+		case input == "\x1b[A" || input == "\x1bOA":
+			keys <- "up"
+		case input == "\x1b[B" || input == "\x1bOB":
+			keys <- "down"
+		case input == "\r" || input == "\n":
+			keys <- "enter"
+		case input == "\x03" || input == "\x1b":
+			keys <- "q"
+		default:
+			keys <- strings.ToLower(input[:1])
+		}
+	}
+}
+
+// readLines is the fallback for terminals without raw mode: every
+// character of a line is a key, and an empty line is Enter
+func readLines(r io.Reader, keys chan<- string) {
+	defer close(keys)
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			keys <- "enter"
+			continue
+		}
+		for _, c := range strings.ToLower(line) {
+			keys <- string(c)
+		}
+	}
+}
+
+// renderPoster draws a poster as terminal lines: half-block characters in
+// 24-bit colour where the terminal supports it, shaded ASCII otherwise
+func renderPoster(posterURL string, width int) ([]string, error) {
+	if !strings.HasPrefix(posterURL, "https://") {
+		return nil, fmt.Errorf("no poster to render")
+	}
+	// A small size is plenty for a couple of dozen terminal cells
+	posterURL = strings.Replace(posterURL, "/t/p/w500/", "/t/p/w154/", 1)
+	posterURL = strings.Replace(posterURL, "/t/p/original/", "/t/p/w154/", 1)
+
+	resp, err := sharedClient.Get(posterURL)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("poster request failed: status code %d", resp.StatusCode)
+	}
+	img, _, err := image.Decode(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	bounds := img.Bounds()
+	// Terminal cells are about twice as tall as they are wide
+	height := width * bounds.Dy() / bounds.Dx()
+	pixel := func(x, y int) (uint32, uint32, uint32) {
+		r, g, b, _ := img.At(bounds.Min.X+x*bounds.Dx()/width, bounds.Min.Y+y*bounds.Dy()/height).RGBA()
+		return r >> 8, g >> 8, b >> 8
+	}
+
+	colorTerm := os.Getenv("COLORTERM")
+	trueColor := strings.Contains(colorTerm, "truecolor") || strings.Contains(colorTerm, "24bit")
+
+	var lines []string
+	for y := 0; y+1 < height; y += 2 {
+		var line strings.Builder
+		for x := 0; x < width; x++ {
+			tr, tg, tb := pixel(x, y)
+			br, bg, bb := pixel(x, y+1)
+			if trueColor {
+				fmt.Fprintf(&line, "\x1b[38;2;%d;%d;%dm\x1b[48;2;%d;%d;%dm▀", tr, tg, tb, br, bg, bb)
+				continue
+			}
+			luminance := (299*(tr+br) + 587*(tg+bg) + 114*(tb+bb)) / 2000
+			line.WriteByte(asciiRamp[int(luminance)*(len(asciiRamp)-1)/255])
+		}
+		if trueColor {
+			line.WriteString("\x1b[0m")
+		}
+		lines = append(lines, line.String())
+	}
+	return lines, nil
+}
+
+// truncate shortens s to at most n runes
+func truncate(s string, n int) string {
+	if runes := []rune(s); len(runes) > n {
+		return string(runes[:n-1]) + "…"
+	}
+	return s
+}
+
+// wrapText breaks text into lines of at most width characters
+func wrapText(text string, width int) []string {
+	var lines []string
+	line := ""
+	for _, word := range strings.Fields(text) {
+		if line != "" && len(line)+1+len(word) > width {
+			lines = append(lines, line)
+			line = word
+			continue
+		}
+		if line != "" {
+			line += " "
+		}
+		line += word
+	}
+	if line != "" {
+		lines = append(lines, line)
+	}
+	return lines
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+)
+
+// maxUndo caps the edits each session can undo
+const maxUndo = 50
+
+// sessionEdit is an undoable change to a session and its persistent side
+// effects, such as an exclusion or a match override
+type sessionEdit struct {
+	label string
+	undo  func() error
+	redo  func() error
+}
+
+// OverrideSessionMatch pins one of the session's movies to a TMDB ID, like
+// OverrideMatch, and refreshes its details in the session. It can be undone
+// with Undo
+func (a *App) OverrideSessionMatch(sessionID string, key string, tmdbID int) (ComparisonSession, error) {
+	aliases, err := a.GetAliases()
+	if err != nil {
+		return ComparisonSession{}, err
+	}
+	previous, hadAlias := aliases[key]
+
+	session, err := a.applyOverride(sessionID, key, func() error { return a.OverrideMatch(key, tmdbID) })
+	if err != nil {
+		return session, err
+	}
+	return a.recordEdit(sessionID, sessionEdit{
+		label: "match override",
+		undo: func() error {
+			_, err := a.applyOverride(sessionID, key, func() error { return a.restoreAlias(key, previous, hadAlias) })
+			return err
+		},
+		redo: func() error {
+			_, err := a.applyOverride(sessionID, key, func() error { return a.OverrideMatch(key, tmdbID) })
+			return err
+		},
+	})
+}
+
+// Undo reverts the latest edit to a session: a vote, shortlisting, pin,
+// exclusion or match override
+func (a *App) Undo(sessionID string) (ComparisonSession, error) {
+	return a.replayEdit(sessionID, true)
+}
+
+// Redo reapplies the latest edit reverted with Undo
+func (a *App) Redo(sessionID string) (ComparisonSession, error) {
+	return a.replayEdit(sessionID, false)
+}
+
+// replayEdit moves the latest edit from the undo stack to the redo stack,
+// reverting it, or the other way round; an edit that fails stays put
+func (a *App) replayEdit(sessionID string, undo bool) (ComparisonSession, error) {
+	a.mu.Lock()
+	session, err := a.sessionLocked(sessionID)
+	if err != nil {
+		a.mu.Unlock()
+		return ComparisonSession{}, err
+	}
+	from, to := &session.undoStack, &session.redoStack
+	if !undo {
+		from, to = to, from
+	}
+	if len(*from) == 0 {
+		a.mu.Unlock()
+		if undo {
+			return a.sessionView(session), fmt.Errorf("nothing to undo")
+		}
+		return a.sessionView(session), fmt.Errorf("nothing to redo")
+	}
+	edit := (*from)[len(*from)-1]
+	*from = (*from)[:len(*from)-1]
+	a.mu.Unlock()
+
+	run, verb := edit.redo, "redo"
+	if undo {
+		run, verb = edit.undo, "undo"
+	}
+	err = run()
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if err != nil {
+		*from = append(*from, edit)
+		return a.sessionView(session), fmt.Errorf("could not %s %s: %v", verb, edit.label, err)
+	}
+	*to = append(*to, edit)
+	return a.sessionView(session), nil
+}
+
+// recordEdit pushes an edit onto a session's undo stack, clearing the redo
+// stack, and returns the session
+func (a *App) recordEdit(sessionID string, edit sessionEdit) (ComparisonSession, error) {
+	return a.updateSession(sessionID, func(s *ComparisonSession) error {
+		s.undoStack = append(s.undoStack, edit)
+		if len(s.undoStack) > maxUndo {
+			s.undoStack = s.undoStack[len(s.undoStack)-maxUndo:]
+		}
+		s.redoStack = nil
+		return nil
+	})
+}
+
+// restoreExcluded reverts an exclusion, putting the movie back where it was
+// in the results, shortlist and votes of the snapshot taken before it
+func (a *App) restoreExcluded(sessionID string, key string, snapshot ComparisonSession) error {
+	if err := a.IncludeMovie(key); err != nil {
+		return err
+	}
+	_, err := a.updateSession(sessionID, func(s *ComparisonSession) error {
+		if _, ok := s.movie(key); ok {
+			return nil
+		}
+		at := slices.IndexFunc(snapshot.movies, func(m Movie) bool { return m.Key == key })
+		movie := snapshot.movies[at]
+		s.movies = slices.Insert(slices.Clone(s.movies), min(at, len(s.movies)), movie)
+		s.Total = len(s.movies)
+		if at := slices.Index(snapshot.Shortlist, key); at >= 0 {
+			s.Shortlist = slices.Insert(slices.Clone(s.Shortlist), min(at, len(s.Shortlist)), key)
+		}
+		a.setShareMovies(s.ShareCode, sharedMovies(s.movies))
+		a.setShareVotes(s.ShareCode, key, snapshot.Votes[key])
+		return nil
+	})
+	return err
+}
+
+// applyOverride changes a session movie's alias and refreshes its details
+func (a *App) applyOverride(sessionID string, key string, change func() error) (ComparisonSession, error) {
+	movie, err := a.sessionMovie(sessionID, key)
+	if err != nil {
+		return ComparisonSession{}, err
+	}
+	if err := change(); err != nil {
+		return ComparisonSession{}, err
+	}
+	if err := a.enrichMovie(&movie); err != nil {
+		return ComparisonSession{}, err
+	}
+	return a.updateSession(sessionID, func(s *ComparisonSession) error {
+		movies := slices.Clone(s.movies)
+		for i := range movies {
+			if movies[i].Key == key {
+				movies[i] = movie
+			}
+		}
+		s.movies = movies
+		return nil
+	})
+}
+
+// restoreAlias puts back the alias a film slug had before an override, or
+// removes it if it had none
+func (a *App) restoreAlias(key string, alias Alias, existed bool) error {
+	a.aliasesMu.Lock()
+	defer a.aliasesMu.Unlock()
+
+	aliases, err := a.GetAliases()
+	if err != nil {
+		return err
+	}
+	if existed {
+		aliases[key] = alias
+	} else {
+		delete(aliases, key)
+	}
+	return a.store.save("aliases", aliases)
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/gocolly/colly/v2"
+)
+
+// WatchedFilm is a film from a user's Letterboxd diary/films page
+type WatchedFilm struct {
+	Title  string  `json:"title"`
+	URL    string  `json:"url"`
+	Rating float64 `json:"rating"` // Stars from 0.5 to 5, 0 when unrated
+}
+
+var ratedClassRegex = regexp.MustCompile(`rated-(\d+)`)
+
+// GetWatchedFilms scrapes every film a user has marked as watched
+func (a *App) GetWatchedFilms(username string) ([]WatchedFilm, error) {
+	return a.scrapeWatched(username, 0)
+}
+
+// scrapeWatched scrapes a user's watched films, stopping after maxPages pages
+// when maxPages is positive
+func (a *App) scrapeWatched(username string, maxPages int) ([]WatchedFilm, error) {
+	defer a.metrics.since("watched_scrape", time.Now())
+	c := a.newCollector()
+
+	var films []WatchedFilm
+	var scrapeErr error
+	pages := 0
+
+	c.OnResponse(func(r *colly.Response) {
+		pages++
+	})
+
+	rules := a.ruleset()
+	selectors := rules.Selectors
+	c.OnHTML(selectors.Poster, func(e *colly.HTMLElement) {
+		link := e.ChildAttr(selectors.FilmPoster, selectors.LinkAttr)
+		title := e.ChildAttr(selectors.PosterImage, "alt")
+		if link == "" || title == "" {
+			return
+		}
+
+		film := WatchedFilm{
+			Title: title,
+			URL:   rules.URLs.link(link),
+		}
+		film.Rating = parseRatingClass(e.ChildAttr(selectors.Rating, "class"))
+		films = append(films, film)
+	})
+
+	c.OnHTML(selectors.NextPage, func(e *colly.HTMLElement) {
+		nextHref := e.Attr("href")
+		if nextHref != "" && (maxPages <= 0 || pages < maxPages) {
+			a.letterboxdLimiter.wait() // Rate limiting
+			e.Request.Visit(rules.URLs.link(nextHref))
+		}
+	})
+
+	c.OnError(func(r *colly.Response, e error) {
+		a.metrics.countError("scrape")
+		scrapeErr = e
+	})
+
+	if err := c.Visit(forUser(rules.URLs.Watched, username)); err != nil {
+		return nil, fmt.Errorf("could not visit watched films for '%s': %v", username, err)
+	}
+
+	if scrapeErr != nil {
+		return nil, scrapeErr
+	}
+
+	return films, nil
+}
+
+// parseRatingClass converts a Letterboxd "rating rated-7" class into stars (3.5)
+func parseRatingClass(class string) float64 {
+	m := ratedClassRegex.FindStringSubmatch(class)
+	if len(m) < 2 {
+		return 0
+	}
+	halfStars, err := strconv.Atoi(m[1])
+	if err != nil {
+		return 0
+	}
+	return float64(halfStars) / 2
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+)
+
+// Watch-party platforms
+const (
+	PlatformTeleparty      = "Teleparty"
+	PlatformPrimeWatch     = "Prime Video Watch Party"
+	PlatformPlexWatchParty = "Plex Watch Together"
+)
+
+// WatchPartyLink opens a film on a service where a synced watch party can be
+// started for remote friends
+type WatchPartyLink struct {
+	Platform string `json:"platform"`
+	Provider string `json:"provider"`
+	URL      string `json:"url"`
+	// Note explains how the party is started from the linked page
+	Note string `json:"note"`
+}
+
+// watchPartyService is a streaming service with watch-party support, keyed
+// in watchPartyServices by TMDB provider ID
+type watchPartyService struct {
+	platform string
+	// search is the service's search URL, with %s replaced by the title
+	search string
+}
+
+// watchPartyServices are the providers watch parties can be started on
+var watchPartyServices = map[int]watchPartyService{
+	8:    {PlatformTeleparty, "https://www.netflix.com/search?q=%s"},
+	337:  {PlatformTeleparty, "https://www.disneyplus.com/search?q=%s"},
+	15:   {PlatformTeleparty, "https://www.hulu.com/search?q=%s"},
+	384:  {PlatformTeleparty, "https://play.max.com/search?q=%s"},
+	1899: {PlatformTeleparty, "https://play.max.com/search?q=%s"},
+	531:  {PlatformTeleparty, "https://www.paramountplus.com/search/?q=%s"},
+	386:  {PlatformTeleparty, "https://www.peacocktv.com/search?q=%s"},
+	9:    {PlatformPrimeWatch, "https://www.amazon.com/gp/video/search?phrase=%s"},
+	119:  {PlatformPrimeWatch, "https://www.amazon.com/gp/video/search?phrase=%s"},
+	538:  {PlatformPlexWatchParty, "https://app.plex.tv/desktop/#!/search?query=%s"},
+}
+
+// watchPartyNotes explains how each platform's party is started
+var watchPartyNotes = map[string]string{
+	PlatformTeleparty:      "Open the film, then start a party from the Teleparty browser extension",
+	PlatformPrimeWatch:     "Open the film and choose Watch Party on its page",
+	PlatformPlexWatchParty: "Open the film and choose Watch Together",
+}
+
+// GetWatchPartyLinks returns links for starting a watch party on the film
+// with the services that stream it in the configured region
+func (a *App) GetWatchPartyLinks(movie Movie) ([]WatchPartyLink, error) {
+	if movie.TMDBID == 0 {
+		return nil, fmt.Errorf("no TMDB match for '%s' to find watch providers with", movie.Title)
+	}
+	providers, err := a.GetWatchProviders(movie.TMDBID)
+	if err != nil {
+		return nil, err
+	}
+
+	title, _ := filmYear(movie.Title, movie.URL)
+	var links []WatchPartyLink
+	seen := make(map[string]bool)
+	for _, provider := range append(providers.Stream, providers.Free...) {
+		service, ok := watchPartyServices[provider.ID]
+		if !ok || seen[service.search] {
+			continue
+		}
+		seen[service.search] = true
+		links = append(links, WatchPartyLink{
+			Platform: service.platform,
+			Provider: provider.Name,
+			URL:      fmt.Sprintf(service.search, url.QueryEscape(title)),
+			Note:     watchPartyNotes[service.platform],
+		})
+	}
+	return links, nil
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// WatchProvider is a service offering a film in the configured region
+type WatchProvider struct {
+	ID      int    `json:"id"`
+	Name    string `json:"name"`
+	LogoURL string `json:"logo_url"`
+}
+
+// WatchProviders lists where a film can be streamed, rented or bought in a
+// region, as reported by TMDB's JustWatch data
+type WatchProviders struct {
+	Region string `json:"region"`
+	// Link is TMDB's page listing the offers with links to each service
+	Link   string          `json:"link"`
+	Stream []WatchProvider `json:"stream"`
+	Rent   []WatchProvider `json:"rent"`
+	Buy    []WatchProvider `json:"buy"`
+	// Free includes services that are free with ads
+	Free []WatchProvider `json:"free"`
+}
+
+// tmdbWatchProviders represents TMDB's watch providers response
+type tmdbWatchProviders struct {
+	Results map[string]struct {
+		Link     string             `json:"link"`
+		Flatrate []tmdbProviderInfo `json:"flatrate"`
+		Rent     []tmdbProviderInfo `json:"rent"`
+		Buy      []tmdbProviderInfo `json:"buy"`
+		Free     []tmdbProviderInfo `json:"free"`
+		Ads      []tmdbProviderInfo `json:"ads"`
+	} `json:"results"`
+}
+
+// tmdbProviderInfo is a provider entry in TMDB's watch providers response
+type tmdbProviderInfo struct {
+	ProviderID   int    `json:"provider_id"`
+	ProviderName string `json:"provider_name"`
+	LogoPath     string `json:"logo_path"`
+}
+
+// GetWatchProviders returns where a film can be watched in the configured region
+func (a *App) GetWatchProviders(movieID int) (WatchProviders, error) {
+	region := a.region()
+	providers := WatchProviders{Region: region}
+
+	apiKey := a.getTMDBAPIKey()
+	if apiKey == "" || len(apiKey) < 10 {
+		return providers, fmt.Errorf("TMDB API key not configured")
+	}
+	if movieID <= 0 {
+		return providers, fmt.Errorf("invalid TMDB movie ID: %d", movieID)
+	}
+
+	a.tmdbLimiter.wait()
+	resp, err := a.tmdbGet(fmt.Sprintf("https://api.themoviedb.org/3/movie/%d/watch/providers?api_key=%s", movieID, apiKey))
+	if err != nil {
+		return providers, fmt.Errorf("failed to get watch providers: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 {
+		return providers, fmt.Errorf("watch providers API error: %v", parseTMDBError(resp))
+	}
+
+	var data tmdbWatchProviders
+	if err := json.NewDecoder(resp.Body).Decode(&data); err != nil {
+		return providers, fmt.Errorf("failed to parse watch providers: %v", err)
+	}
+
+	offers := data.Results[region]
+	providers.Link = offers.Link
+	providers.Stream = toWatchProviders(offers.Flatrate)
+	providers.Rent = toWatchProviders(offers.Rent)
+	providers.Buy = toWatchProviders(offers.Buy)
+	providers.Free = toWatchProviders(append(offers.Free, offers.Ads...))
+	return providers, nil
+}
+
+// toWatchProviders converts TMDB provider entries
+func toWatchProviders(entries []tmdbProviderInfo) []WatchProvider {
+	providers := make([]WatchProvider, 0, len(entries))
+	for _, entry := range entries {
+		provider := WatchProvider{ID: entry.ProviderID, Name: entry.ProviderName}
+		if entry.LogoPath != "" {
+			provider.LogoURL = fmt.Sprintf("https://image.tmdb.org/t/p/w92%s", entry.LogoPath)
+		}
+		providers = append(providers, provider)
+	}
+	return providers
+}
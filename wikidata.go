@@ -0,0 +1,204 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// wikidataUserAgent identifies Klisse to Wikimedia, as its API policy asks
+const wikidataUserAgent = "Klisse/1.0 (https://github.com/jamaldinnnn/klisse-go)"
+
+// wikidataIDRegex matches a Wikidata item ID such as "Q13417189"
+var wikidataIDRegex = regexp.MustCompile(`^Q\d+$`)
+
+// wikidataDetailsQuery fetches a film's core metadata in a single SPARQL
+// query; %[1]s is the item ID
+const wikidataDetailsQuery = `SELECT
+  (SAMPLE(?directorLabel) AS ?director)
+  (MIN(?date) AS ?released)
+  (SAMPLE(?duration) AS ?runtime)
+  (SAMPLE(?poster) AS ?poster)
+  (SAMPLE(?image) AS ?image)
+  (SAMPLE(?imdb) AS ?imdb)
+  (SAMPLE(?tmdb) AS ?tmdb)
+  (SAMPLE(?description) AS ?overview)
+  (GROUP_CONCAT(DISTINCT ?genreLabel; separator="|") AS ?genres)
+WHERE {
+  BIND(wd:%[1]s AS ?film)
+  OPTIONAL { ?film wdt:P57 ?d . ?d rdfs:label ?directorLabel . FILTER(LANG(?directorLabel) = "en") }
+  OPTIONAL { ?film wdt:P577 ?date . }
+  OPTIONAL { ?film wdt:P2047 ?duration . }
+  OPTIONAL { ?film wdt:P3383 ?poster . }
+  OPTIONAL { ?film wdt:P18 ?image . }
+  OPTIONAL { ?film wdt:P345 ?imdb . }
+  OPTIONAL { ?film wdt:P4947 ?tmdb . }
+  OPTIONAL { ?film schema:description ?description . FILTER(LANG(?description) = "en") }
+  OPTIONAL { ?film wdt:P136 ?g . ?g rdfs:label ?genreLabel . FILTER(LANG(?genreLabel) = "en") }
+}`
+
+// wikidataProvider resolves films via Wikidata, which needs no API key,
+// enabling basic metadata when TMDB isn't configured
+type wikidataProvider struct {
+	app    *App
+	client *http.Client
+}
+
+// newWikidataProvider creates a Wikidata provider
+func newWikidataProvider(a *App) *wikidataProvider {
+	return &wikidataProvider{app: a, client: &http.Client{Transport: sharedTransport, Timeout: 15 * time.Second}}
+}
+
+// Name implements MetadataProvider
+func (p *wikidataProvider) Name() string {
+	return "wikidata"
+}
+
+// SearchMovie implements MetadataProvider, picking the first search result
+// described as a film, released in year if one is given
+func (p *wikidataProvider) SearchMovie(title string, year string) (string, error) {
+	params := url.Values{
+		"action":   {"wbsearchentities"},
+		"search":   {title},
+		"language": {"en"},
+		"type":     {"item"},
+		"limit":    {"10"},
+		"format":   {"json"},
+	}
+
+	var result struct {
+		Search []struct {
+			ID          string `json:"id"`
+			Description string `json:"description"`
+		} `json:"search"`
+	}
+	if err := p.getJSON("https://www.wikidata.org/w/api.php?"+params.Encode(), &result); err != nil {
+		return "", err
+	}
+
+	for _, candidate := range result.Search {
+		description := strings.ToLower(candidate.Description)
+		if !strings.Contains(description, "film") {
+			continue
+		}
+		if year != "" && !strings.Contains(description, year) {
+			continue
+		}
+		return candidate.ID, nil
+	}
+	return "", fmt.Errorf("no film found on Wikidata for '%s'", title)
+}
+
+// GetDetails implements MetadataProvider
+func (p *wikidataProvider) GetDetails(id string, title string) (Movie, error) {
+	if !wikidataIDRegex.MatchString(id) {
+		return Movie{}, fmt.Errorf("invalid Wikidata ID '%s'", id)
+	}
+
+	params := url.Values{
+		"query":  {fmt.Sprintf(wikidataDetailsQuery, id)},
+		"format": {"json"},
+	}
+	var result struct {
+		Results struct {
+			Bindings []map[string]struct {
+				Value string `json:"value"`
+			} `json:"bindings"`
+		} `json:"results"`
+	}
+	if err := p.getJSON("https://query.wikidata.org/sparql?"+params.Encode(), &result); err != nil {
+		return Movie{}, err
+	}
+	if len(result.Results.Bindings) == 0 {
+		return Movie{}, fmt.Errorf("no details found on Wikidata for '%s'", id)
+	}
+	row := result.Results.Bindings[0]
+
+	movie := Movie{Title: title, FormattedRating: "N/A", ReleaseYear: "----"}
+
+	if released := row["released"].Value; len(released) >= 10 {
+		movie.ReleaseDate = released[:10]
+		movie.ReleaseYear = released[:4]
+		movie.Status = releaseStatus("", movie.ReleaseDate, time.Now())
+	}
+
+	if minutes, err := strconv.ParseFloat(row["runtime"].Value, 64); err == nil && minutes > 0 {
+		movie.Runtime = int(minutes)
+		movie.FormattedRuntime = formatRuntime(movie.Runtime, p.app.language())
+	}
+
+	// Commons file URLs are Special:FilePath redirects, which can be scaled
+	image := row["poster"].Value
+	if image == "" {
+		image = row["image"].Value
+	}
+	if image != "" {
+		movie.PosterURL = image + "?width=500"
+	} else {
+		movie.PosterURL = p.app.placeholderPoster(title)
+	}
+	movie.BackdropURL = movie.PosterURL
+
+	movie.Genres = []string{}
+	for _, genre := range strings.Split(row["genres"].Value, "|") {
+		genre = strings.TrimSuffix(genre, " film")
+		if genre != "" {
+			movie.Genres = append(movie.Genres, strings.ToUpper(genre[:1])+genre[1:])
+		}
+	}
+
+	movie.IMDBID = row["imdb"].Value
+	movie.TMDBID, _ = strconv.Atoi(row["tmdb"].Value)
+
+	movie.Overview = row["overview"].Value
+	if movie.Overview == "" {
+		movie.Overview = "No overview available."
+	}
+
+	movie.Director = Person{Name: "N/A"}
+	if director := row["director"].Value; director != "" {
+		movie.Director = Person{Name: director}
+	}
+	movie.Cast = []Person{}
+
+	movie.EntryType = EntryFeature
+	if isShort(movie.Runtime) {
+		movie.EntryType = EntryShort
+	}
+	movie.Classification = classifyMovie(movie.EntryType, movie.Runtime, movie.Genres)
+
+	return movie, nil
+}
+
+// getJSON performs a Wikimedia API request and decodes the JSON response
+func (p *wikidataProvider) getJSON(requestURL string, v interface{}) error {
+	req, err := http.NewRequest(http.MethodGet, requestURL, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("User-Agent", wikidataUserAgent)
+	req.Header.Set("Accept", "application/json")
+
+	start := time.Now()
+	resp, err := p.client.Do(req)
+	p.app.metrics.since("wikidata_request", start)
+	if err != nil {
+		p.app.metrics.countError("wikidata")
+		return fmt.Errorf("Wikidata request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		p.app.metrics.countError("wikidata")
+		return fmt.Errorf("Wikidata error: status code %d", resp.StatusCode)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("failed to parse Wikidata response: %v", err)
+	}
+	return nil
+}
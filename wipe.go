@@ -0,0 +1,146 @@
+package main
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Cache kinds accepted by ClearCache
+const (
+	// CachePages is the scraped Letterboxd pages, including watchlists
+	CachePages = "pages"
+	// CacheTMDB is the TMDB API responses kept for revalidation
+	CacheTMDB = "tmdb"
+	// CacheCheckpoints is the progress saved by interrupted comparisons
+	CacheCheckpoints = "checkpoints"
+	// CacheHistory is the watchlist change history, the comparison history
+	// and the movie night log
+	CacheHistory = "history"
+	// CacheAll is the active profile's pages and TMDB responses plus
+	// checkpoints
+	CacheAll = "all"
+)
+
+// ClearResult reports what clearing a cache or wiping data removed
+type ClearResult struct {
+	Kind  string `json:"kind"`
+	Files int    `json:"files"`
+	// Bytes is the disk space reclaimed
+	Bytes int64 `json:"bytes"`
+}
+
+// add counts removed files towards the result
+func (r *ClearResult) add(files int, bytes int64) {
+	r.Files += files
+	r.Bytes += bytes
+}
+
+// ClearCache deletes one kind of cached or historical data, reporting the
+// disk space reclaimed; settings and credentials are kept
+func (a *App) ClearCache(kind string) (ClearResult, error) {
+	return a.clearCache(kind, time.Time{})
+}
+
+// clearCache deletes the files of a cache kind last written before the
+// given time, or all of them when it is zero
+func (a *App) clearCache(kind string, before time.Time) (ClearResult, error) {
+	result := ClearResult{Kind: kind}
+	dirs, patterns, err := a.cacheLocations(kind)
+	if err != nil {
+		return result, err
+	}
+	for _, dir := range dirs {
+		result.add(removeContents(dir, before))
+	}
+	for _, pattern := range patterns {
+		files, bytes, err := a.store.removeMatching(pattern, before)
+		result.add(files, bytes)
+		if err != nil {
+			return result, err
+		}
+	}
+	if kind == CachePages || kind == CacheAll {
+		a.mu.Lock()
+		a.watchlists = nil
+		a.intersections = nil
+		a.mu.Unlock()
+	}
+	return result, nil
+}
+
+// cacheLocations returns the directories and store document patterns
+// holding a cache kind
+func (a *App) cacheLocations(kind string) ([]string, []string, error) {
+	switch kind {
+	case CachePages:
+		return []string{a.scrapeCache.folder()}, nil, nil
+	case CacheTMDB:
+		return []string{a.tmdbCache.folder()}, nil, nil
+	case CacheCheckpoints:
+		return nil, []string{"checkpoint-*"}, nil
+	case CacheHistory:
+		return nil, []string{"watchlist-*", "comparisons", "nights"}, nil
+	case CacheAll:
+		return []string{a.scrapeCache.folder(), a.tmdbCache.folder()}, []string{"checkpoint-*"}, nil
+	default:
+		return nil, nil, fmt.Errorf("unknown cache kind '%s'", kind)
+	}
+}
+
+// WipeAllData deletes every cache, all history and the stored settings and
+// credentials of the active profile, returning it to its first-run state
+func (a *App) WipeAllData() (ClearResult, error) {
+	result := ClearResult{Kind: CacheAll}
+	result.add(removeContents(a.scrapeCache.folder(), time.Time{}))
+	result.add(removeContents(a.tmdbCache.folder(), time.Time{}))
+	files, bytes, err := a.store.removeMatching("*", time.Time{})
+	result.add(files, bytes)
+	if err != nil {
+		return result, err
+	}
+
+	a.runtimeAPIKey = ""
+	a.resetState()
+	return result, nil
+}
+
+// resetState drops the in-memory state derived from stored data, after it
+// was wiped or replaced
+func (a *App) resetState() {
+	a.mu.Lock()
+	a.watchlists = nil
+	a.affinities = nil
+	a.surprises = nil
+	a.intersections = nil
+	a.sessions = nil
+	a.mu.Unlock()
+	a.sharesMu.Lock()
+	a.shares = nil
+	a.sharesMu.Unlock()
+}
+
+// removeContents deletes the files under dir last written before the given
+// time, or all of them when it is zero, keeping the directories; it returns
+// how many were removed and their total size, skipping files that can't be
+func removeContents(dir string, before time.Time) (int, int64) {
+	var files int
+	var bytes int64
+	filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return nil
+		}
+		info, err := entry.Info()
+		if err != nil || (!before.IsZero() && !info.ModTime().Before(before)) {
+			return nil
+		}
+		if os.Remove(path) == nil {
+			files++
+			bytes += info.Size()
+		}
+		return nil
+	})
+	return files, bytes
+}